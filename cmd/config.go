@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/tomledit"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set values in story.toml and config.toml",
+	Long: `Get and set any value in story.toml or config.toml by its dotted path,
+e.g. "p2p.max_num_inbound_peers" or "log.level". Known keys are validated
+against a schema; other keys that already exist in a file are typed from
+their current value.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <section.key>",
+	Short:             "Show a configuration value",
+	Args:              cobra.ExactArgs(1),
+	RunE:              runConfigGet,
+	ValidArgsFunction: completeConfigArgs,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <section.key> <value>",
+	Short:             "Set a configuration value",
+	Args:              cobra.ExactArgs(2),
+	RunE:              runConfigSet,
+	ValidArgsFunction: completeConfigArgs,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the known configuration keys",
+	RunE:  runConfigList,
+}
+
+// configFileFlag restricts key lookup to story.toml or config.toml
+var configFileFlag string
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configCmd.PersistentFlags().StringVar(&configFileFlag, "file", "", "Config file to use when a key exists in both (story or config)")
+	configCmd.RegisterFlagCompletionFunc("file", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"story", "config"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key, err := resolveConfigKey(configFileFlag, args[0])
+	if err != nil {
+		return err
+	}
+	return showConfigValue(key, key.Key)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, err := resolveConfigKey(configFileFlag, args[0])
+	if err != nil {
+		return err
+	}
+	return setConfigValue(key, key.Key, args[1])
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	tableData := pterm.TableData{{"File", "Key", "Type", "Allowed", "Description"}}
+	for _, k := range config.NodeConfigSchema {
+		tableData = append(tableData, []string{
+			k.File.FileName(),
+			k.Key,
+			string(k.Type),
+			strings.Join(k.Allowed, ", "),
+			k.Description,
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader(true).WithData(tableData).Render()
+}
+
+// nodeConfigPath returns the path of story.toml or config.toml of the
+// active profile
+func nodeConfigPath(file config.NodeConfigFile) (string, error) {
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(node.ConfigDir(), file.FileName()), nil
+}
+
+// resolveConfigKey finds the schema entry for key. Keys that are not in the
+// schema but already exist in one of the files get their type inferred from
+// the current value.
+func resolveConfigKey(fileName, key string) (config.ConfigKey, error) {
+	var file config.NodeConfigFile
+	if fileName != "" {
+		var err error
+		if file, err = config.ParseNodeConfigFile(fileName); err != nil {
+			return config.ConfigKey{}, err
+		}
+	}
+
+	matches := config.LookupConfigKey(file, key)
+	if len(matches) == 0 {
+		matches = inferConfigKeys(file, key)
+	}
+
+	switch len(matches) {
+	case 0:
+		return config.ConfigKey{}, fmt.Errorf("unknown configuration key: %s (run 'scli config list' to see known keys)", key)
+	case 1:
+		return matches[0], nil
+	default:
+		return config.ConfigKey{}, fmt.Errorf("key %s exists in both story.toml and config.toml; use --file story or --file config", key)
+	}
+}
+
+// inferConfigKeys looks for key in the config files on disk
+func inferConfigKeys(file config.NodeConfigFile, key string) []config.ConfigKey {
+	var matches []config.ConfigKey
+	for _, f := range []config.NodeConfigFile{config.StoryToml, config.CometToml} {
+		if file != "" && f != file {
+			continue
+		}
+		path, err := nodeConfigPath(f)
+		if err != nil {
+			continue
+		}
+		doc, err := tomledit.Load(path)
+		if err != nil {
+			continue
+		}
+		if raw, ok := doc.Get(key); ok {
+			matches = append(matches, config.ConfigKey{
+				File: f,
+				Key:  key,
+				Type: config.InferValueType(raw),
+			})
+		}
+	}
+	return matches
+}
+
+// showConfigValue prints the current value of key, labelled with name
+func showConfigValue(key config.ConfigKey, name string) error {
+	path, err := nodeConfigPath(key.File)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to load %s: %v", key.File.FileName(), err))
+		return err
+	}
+
+	var tree map[string]interface{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		printError(fmt.Sprintf("Failed to parse %s: %v", key.File.FileName(), err))
+		return err
+	}
+
+	value, ok := lookupTomlValue(tree, key.Key)
+	if !ok {
+		return fmt.Errorf("%s is not set in %s", key.Key, key.File.FileName())
+	}
+
+	fmt.Printf("%s: %v\n", name, value)
+	return nil
+}
+
+// setConfigValue validates input and writes it to key, keeping the rest of
+// the file untouched
+func setConfigValue(key config.ConfigKey, name, input string) error {
+	value, err := key.Parse(input)
+	if err != nil {
+		return err
+	}
+
+	path, err := nodeConfigPath(key.File)
+	if err != nil {
+		return err
+	}
+
+	doc, err := tomledit.Load(path)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to load %s: %v", key.File.FileName(), err))
+		return err
+	}
+
+	if err := doc.Set(key.Key, value); err != nil {
+		return err
+	}
+
+	// Backup the original file
+	if err := backupFile(path); err != nil {
+		return fmt.Errorf("failed to backup %s: %v", key.File.FileName(), err)
+	}
+
+	if err := doc.Save(path); err != nil {
+		printError(fmt.Sprintf("Failed to write to %s: %v", key.File.FileName(), err))
+		return err
+	}
+
+	pterm.Info.Printf("%s set to: %v\n", name, input)
+	return nil
+}
+
+// lookupTomlValue walks a decoded TOML tree along a dotted key, which may
+// have quoted parts
+func lookupTomlValue(tree map[string]interface{}, key string) (interface{}, bool) {
+	parts := tomledit.SplitKey(key)
+	var current interface{} = tree
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// completeConfigArgs completes keys for the first argument and allowed values
+// for the second
+func completeConfigArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return configKeyCompletions(), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		if cmd.Name() != "set" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		key, err := resolveConfigKey(configFileFlag, args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(key.Allowed) > 0 {
+			return key.Allowed, cobra.ShellCompDirectiveNoFileComp
+		}
+		if key.Type == config.TypeBool {
+			return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// configKeyCompletions returns schema keys plus any other keys found in the
+// config files on disk
+func configKeyCompletions() []string {
+	descriptions := map[string]string{}
+	for _, k := range config.NodeConfigSchema {
+		if _, ok := descriptions[k.Key]; !ok {
+			descriptions[k.Key] = k.Description
+		}
+	}
+	for _, f := range []config.NodeConfigFile{config.StoryToml, config.CometToml} {
+		path, err := nodeConfigPath(f)
+		if err != nil {
+			continue
+		}
+		doc, err := tomledit.Load(path)
+		if err != nil {
+			continue
+		}
+		for _, key := range doc.Keys() {
+			if _, ok := descriptions[key]; !ok {
+				descriptions[key] = f.FileName()
+			}
+		}
+	}
+
+	completions := make([]string, 0, len(descriptions))
+	for key, desc := range descriptions {
+		completions = append(completions, key+"\t"+desc)
+	}
+	sort.Strings(completions)
+	return completions
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "testing"
+
+func TestLookupTomlValue(t *testing.T) {
+	tree := map[string]interface{}{
+		"p2p": map[string]interface{}{"seeds": "a,b"},
+		"a.b": map[string]interface{}{"c": int64(1)},
+	}
+	tests := []struct {
+		key  string
+		want interface{}
+		ok   bool
+	}{
+		{"p2p.seeds", "a,b", true},
+		{`"a.b".c`, int64(1), true},
+		{"a.b.c", nil, false},
+		{"p2p.seeds.x", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := lookupTomlValue(tree, tt.key)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("lookupTomlValue(%q) = %v, %v; want %v, %v", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+// cmd/exporter.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/install"
+	"github.com/sSelmann/storycli/utils/metrics"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+)
+
+// exporterSnapshotTimeout bounds how long one provider may take to report
+// its snapshots for both modes
+const exporterSnapshotTimeout = 20 * time.Second
+
+// exporterSigningInterval is the time between checks of the blocks the
+// validator signed
+const exporterSigningInterval = 15 * time.Second
+
+var (
+	exporterListen           string
+	exporterReferenceRPC     string
+	exporterNoReference      bool
+	exporterSnapshotInterval time.Duration
+	exporterVersionInterval  time.Duration
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve Prometheus metrics on the health of the node",
+	Long: `Serves metrics on the health of the node in the Prometheus text format on
+/metrics: whether the services run, the heights of the consensus and execution
+clients and how far apart they are, the block lag against a reference RPC,
+peers, blocks the validator missed, free disk space, the latest snapshot height
+of every provider against the local height, and the installed story and geth
+versions against the latest ones.
+
+The node is queried on every scrape. Signed blocks are checked in the
+background every 15s, and snapshot heights and latest versions, which come
+from remote APIs, are refreshed in the background as well. Run it as a service, e.g.
+with contrib/systemd/scli-exporter@.service.`,
+	Example: `  scli exporter --listen :9657
+  scli exporter --profile mainnet --listen 127.0.0.1:9658 --no-reference`,
+	RunE: runExporter,
+}
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9657", "Address to serve the metrics on")
+	exporterCmd.Flags().StringVar(&exporterReferenceRPC, "reference-rpc", "", "CometBFT RPC to measure the block lag against (default: the public RPC of the network)")
+	exporterCmd.Flags().BoolVar(&exporterNoReference, "no-reference", false, "Don't compare the height with a reference RPC")
+	exporterCmd.Flags().DurationVar(&exporterSnapshotInterval, "snapshot-interval", 10*time.Minute, "Time between refreshes of the snapshot heights of the providers")
+	exporterCmd.Flags().DurationVar(&exporterVersionInterval, "version-interval", time.Hour, "Time between checks for the latest story and geth versions")
+	exporterCmd.MarkFlagsMutuallyExclusive("reference-rpc", "no-reference")
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	if exporterSnapshotInterval < time.Minute || exporterVersionInterval < time.Minute {
+		return fmt.Errorf("--snapshot-interval and --version-interval must be at least 1m")
+	}
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	network, err := config.LookupNetwork(node.Network)
+	if err != nil {
+		return err
+	}
+	endpoints := nodestatus.Discover(node)
+	e := &exporter{
+		node:      node,
+		network:   network,
+		endpoints: endpoints,
+		opts:      referenceOptions(node, exporterReferenceRPC, exporterNoReference),
+		tracker:   nodestatus.SigningTracker{Endpoint: endpoints.CometRPC},
+		latest:    map[string]string{},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go every(ctx, exporterSigningInterval, e.refreshSigning)
+	go every(ctx, exporterSnapshotInterval, e.refreshSnapshots)
+	go every(ctx, exporterVersionInterval, e.refreshVersions)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "storycli exporter: metrics are served on /metrics")
+	})
+	server := &http.Server{Addr: exporterListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	pterm.Info.Printf("Serving metrics of profile %s on %s/metrics (CometBFT RPC %s, geth RPC %s)\n", node.Name, exporterListen, endpoints.CometRPC, endpoints.GethRPC)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	pterm.Info.Println("Exporter stopped.")
+	return nil
+}
+
+// every calls fn now and then every interval until ctx is done
+func every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// exporter serves the metrics of a node. The node is queried on every
+// scrape; signed blocks, snapshot heights and latest versions are refreshed
+// in the background.
+type exporter struct {
+	node      config.Profile
+	network   config.Network
+	endpoints nodestatus.Endpoints
+	opts      nodestatus.Options
+
+	// tracker and signingErr are only used by refreshSigning
+	tracker    nodestatus.SigningTracker
+	signingErr string
+
+	// remote guards the data refreshed in the background
+	remote    sync.RWMutex
+	signing   signingCounts
+	snapshots []provider.SnapshotInfo
+	latest    map[string]string
+}
+
+// signingCounts are the counts of the signing tracker after its last update
+type signingCounts struct {
+	signed, missed       uint64
+	window, windowMissed int
+	consecutiveMissed    int
+	lastHeight           int64
+}
+
+// refreshSigning checks the blocks committed since the last check while
+// the node is an active validator that is in sync
+func (e *exporter) refreshSigning() {
+	c := nodestatus.CollectConsensus(e.endpoints, e.opts)
+	if !c.Up || c.CatchingUp || c.VotingPower == 0 {
+		return
+	}
+	err := e.tracker.Update(c.ValidatorAddress, c.LatestHeight)
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	if msg != "" && msg != e.signingErr {
+		pterm.Warning.Printf("Failed to check signed blocks: %s\n", msg)
+	}
+	e.signingErr = msg
+
+	counts := signingCounts{
+		signed:            e.tracker.Signed,
+		missed:            e.tracker.Missed,
+		consecutiveMissed: e.tracker.ConsecutiveMissed(),
+		lastHeight:        e.tracker.LastHeight(),
+	}
+	counts.window, counts.windowMissed = e.tracker.Window()
+	e.remote.Lock()
+	e.signing = counts
+	e.remote.Unlock()
+}
+
+// refreshSnapshots fetches the latest snapshot of every provider for both
+// pruning modes. Providers that fail keep their previous snapshots.
+func (e *exporter) refreshSnapshots() {
+	endpoints, _ := config.ResolveEndpoints(e.network)
+	providers := provider.All(endpoints, e.network)
+	fetched := make([][]provider.SnapshotInfo, len(providers))
+	failed := make([]bool, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p provider.SnapshotProvider) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), exporterSnapshotTimeout)
+			defer cancel()
+			for _, mode := range []string{"pruned", "archive"} {
+				info, err := p.FetchSnapshotInfo(ctx, mode)
+				if errors.Is(err, provider.ErrNetworkUnsupported) {
+					continue
+				}
+				if err != nil {
+					pterm.Warning.Printf("Failed to fetch %s snapshot data (mode=%s): %v\n", p.Name(), mode, err)
+					failed[i] = true
+					continue
+				}
+				fetched[i] = append(fetched[i], info)
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	e.remote.Lock()
+	defer e.remote.Unlock()
+	var snapshots []provider.SnapshotInfo
+	for i, p := range providers {
+		if !failed[i] {
+			snapshots = append(snapshots, fetched[i]...)
+			continue
+		}
+		for _, info := range e.snapshots {
+			if info.ProviderName == p.Name() {
+				snapshots = append(snapshots, info)
+			}
+		}
+	}
+	e.snapshots = snapshots
+}
+
+// refreshVersions looks up the latest story and geth versions. A failed
+// lookup keeps the previous version.
+func (e *exporter) refreshVersions() {
+	for _, binary := range []string{install.Story, install.Geth} {
+		version, err := latestVersion(binary, e.network)
+		if err != nil {
+			pterm.Warning.Printf("Failed to get the latest %s version: %v\n", binary, err)
+			continue
+		}
+		e.remote.Lock()
+		e.latest[binary] = version
+		e.remote.Unlock()
+	}
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := nodestatus.Collect(e.node, e.endpoints, e.opts)
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	m := metrics.NewWriter(w)
+	e.writeNode(m, status)
+	e.writeSigning(m)
+	e.writeDisk(m)
+	e.writeRemote(m, status.Consensus)
+	m.Gauge("storycli_scrape_duration_seconds", "Time taken to collect the metrics.", time.Since(start).Seconds())
+	if err := m.Flush(); err != nil {
+		pterm.Warning.Printf("Failed to write metrics: %v\n", err)
+	}
+}
+
+func (e *exporter) writeNode(m *metrics.Writer, s nodestatus.Status) {
+	for _, svc := range s.Services {
+		if svc.Error == "" {
+			m.Bool("storycli_service_up", "Whether the service runs.", svc.Active, "service", svc.Name)
+		}
+	}
+
+	c := s.Consensus
+	m.Bool("storycli_consensus_up", "Whether the CometBFT RPC answers.", c.Up)
+	if c.Up {
+		m.Gauge("storycli_consensus_height", "Latest block height of the consensus client.", float64(c.LatestHeight))
+		if !c.LatestBlockTime.IsZero() {
+			m.Gauge("storycli_consensus_block_timestamp_seconds", "Time of the latest consensus block.", float64(c.LatestBlockTime.Unix()))
+		}
+		m.Bool("storycli_consensus_catching_up", "Whether the consensus client is catching up.", c.CatchingUp)
+		m.Gauge("storycli_consensus_peers", "Peers of the consensus client.", float64(c.InboundPeers), "direction", "inbound")
+		m.Gauge("storycli_consensus_peers", "Peers of the consensus client.", float64(c.OutboundPeers), "direction", "outbound")
+		m.Gauge("storycli_validator_voting_power", "Voting power of the validator of the node, 0 outside the active set.", float64(c.VotingPower))
+	}
+
+	x := s.Execution
+	m.Bool("storycli_execution_up", "Whether the geth JSON-RPC answers.", x.Up)
+	if x.Up {
+		m.Gauge("storycli_execution_height", "Latest block number of the execution client.", float64(x.BlockNumber))
+		if !x.BlockTime.IsZero() {
+			m.Gauge("storycli_execution_block_timestamp_seconds", "Time of the latest execution block.", float64(x.BlockTime.Unix()))
+		}
+		m.Bool("storycli_execution_syncing", "Whether the execution client is syncing.", x.Syncing)
+		m.Gauge("storycli_execution_peers", "Peers of the execution client.", float64(x.Peers))
+	}
+
+	if s.HeadDrift != nil {
+		m.Gauge("storycli_head_drift_seconds", "Time of the latest consensus block minus time of the latest execution block.", *s.HeadDrift)
+		m.Bool("storycli_heads_agree", "Whether the consensus and execution heads are within the drift tolerance.", *s.HeadsAgree)
+	}
+	if s.Reference != nil {
+		m.Bool("storycli_reference_up", "Whether the reference RPC answers.", s.Reference.Up, "endpoint", s.Reference.Endpoint)
+		if s.Reference.Up {
+			m.Gauge("storycli_reference_height", "Latest block height of the reference RPC.", float64(s.Reference.LatestHeight), "endpoint", s.Reference.Endpoint)
+		}
+	}
+	if s.BlockLag != nil {
+		m.Gauge("storycli_block_lag", "Blocks the node is behind the reference RPC.", float64(*s.BlockLag))
+	}
+}
+
+func (e *exporter) writeSigning(m *metrics.Writer) {
+	e.remote.RLock()
+	s := e.signing
+	e.remote.RUnlock()
+	if s.lastHeight == 0 {
+		return
+	}
+	m.Counter("storycli_validator_signed_blocks_total", "Blocks the validator signed since the exporter started.", float64(s.signed))
+	m.Counter("storycli_validator_missed_blocks_total", "Blocks the validator missed since the exporter started.", float64(s.missed))
+	m.Gauge("storycli_validator_window_blocks", fmt.Sprintf("Recent blocks checked for signatures, up to %d.", nodestatus.SigningWindow), float64(s.window))
+	m.Gauge("storycli_validator_window_missed_blocks", "Recent checked blocks the validator missed.", float64(s.windowMissed))
+	m.Gauge("storycli_validator_consecutive_missed_blocks", "Latest checked blocks in a row the validator missed.", float64(s.consecutiveMissed))
+	m.Gauge("storycli_validator_last_checked_height", "Last height checked for a signature of the validator.", float64(s.lastHeight))
+}
+
+func (e *exporter) writeDisk(m *metrics.Writer) {
+	usage, err := disk.Usage(e.node.HomeDir)
+	if err != nil {
+		return
+	}
+	m.Gauge("storycli_disk_free_bytes", "Free space on the file system of the node home.", float64(usage.Free), "path", e.node.HomeDir)
+	m.Gauge("storycli_disk_size_bytes", "Size of the file system of the node home.", float64(usage.Total), "path", e.node.HomeDir)
+}
+
+func (e *exporter) writeRemote(m *metrics.Writer, c nodestatus.Consensus) {
+	e.remote.RLock()
+	defer e.remote.RUnlock()
+
+	for _, info := range e.snapshots {
+		if height, ok := info.Height(); ok {
+			m.Gauge("storycli_snapshot_height", "Block height of the latest snapshot of the provider.", float64(height), "provider", info.ProviderName, "mode", info.Mode)
+		}
+	}
+	if c.Up {
+		for _, info := range e.snapshots {
+			if height, ok := info.Height(); ok {
+				m.Gauge("storycli_snapshot_blocks_ahead", "Snapshot height minus the local height; positive if the snapshot is ahead of the node.", float64(height-c.LatestHeight), "provider", info.ProviderName, "mode", info.Mode)
+			}
+		}
+	}
+
+	record, err := install.NewStore(e.node).LoadRecord()
+	if err != nil {
+		return
+	}
+	installed := map[string]string{}
+	for _, binary := range []string{install.Story, install.Geth} {
+		installed[binary] = "unknown"
+		if b := record.Binaries[binary]; b != nil && b.Current != "" {
+			installed[binary] = b.Current
+		}
+		latest := e.latest[binary]
+		if latest == "" {
+			latest = "unknown"
+		}
+		m.Gauge("storycli_binary_info", "Installed and latest version of the binary; always 1.", 1, "binary", binary, "installed", installed[binary], "latest", latest)
+	}
+	for _, binary := range []string{install.Story, install.Geth} {
+		latest := e.latest[binary]
+		if latest != "" && installed[binary] != "unknown" && installed[binary] != "unmanaged" {
+			m.Bool("storycli_binary_outdated", "Whether the installed version differs from the latest one.", installed[binary] != latest, "binary", binary)
+		}
+	}
+}
@@ -1,22 +1,25 @@
 package cmd
 
 import (
-	"bytes"
-	"os/exec"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/service"
 )
 
-// checkServiceExists checks if a given systemd service exists
-func checkServiceExists(serviceName string) (bool, error) {
-	cmd := exec.Command("systemctl", "list-unit-files", serviceName+".service")
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+// serviceManager returns the service manager of the active profile
+func serviceManager() (service.Manager, error) {
+	node, err := config.ActiveProfile()
 	if err != nil {
-		return false, nil
+		return nil, err
 	}
+	return service.ForProfile(node)
+}
 
-	return bytes.Contains(out.Bytes(), []byte(serviceName+".service")), nil
+// checkServiceExists checks if a given service is installed with the
+// service manager of the active profile
+func checkServiceExists(serviceName string) (bool, error) {
+	manager, err := serviceManager()
+	if err != nil {
+		return false, err
+	}
+	return manager.Exists(serviceName)
 }
@@ -4,11 +4,11 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"strconv"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
 )
 
 var logsCmd = &cobra.Command{
@@ -20,13 +20,13 @@ var logsCmd = &cobra.Command{
 var storyLogsCmd = &cobra.Command{
 	Use:   "story",
 	Short: "View logs for the Story service",
-	RunE:  runServiceLogs("story"),
+	RunE:  runServiceLogs(func(p config.Profile) string { return p.StoryService }),
 }
 
 var gethLogsCmd = &cobra.Command{
 	Use:   "geth",
 	Short: "View logs for the Story-Geth service",
-	RunE:  runServiceLogs("story-geth"),
+	RunE:  runServiceLogs(func(p config.Profile) string { return p.GethService }),
 }
 
 var logsLines int
@@ -41,8 +41,14 @@ func init() {
 	gethLogsCmd.Flags().IntVarP(&logsLines, "lines", "n", 20, "Number of log lines to display")
 }
 
-func runServiceLogs(serviceName string) func(cmd *cobra.Command, args []string) error {
+// runServiceLogs shows the logs of the service picked from the active profile
+func runServiceLogs(service func(config.Profile) string) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		node, err := config.ActiveProfile()
+		if err != nil {
+			return err
+		}
+		serviceName := service(node)
 		pterm.Info.Printf(fmt.Sprintf("Checking if '%s' service exists...", serviceName))
 
 		exists, err := checkServiceExists(serviceName)
@@ -64,14 +70,14 @@ func runServiceLogs(serviceName string) func(cmd *cobra.Command, args []string)
 	}
 }
 
-// displayServiceLogs displays the logs of a given systemd service
+// displayServiceLogs follows the logs of a given service
 func displayServiceLogs(serviceName string, lines int) error {
-	cmd := exec.Command("journalctl", "-fu", serviceName, "-o", "cat", "-n", strconv.Itoa(lines))
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	manager, err := serviceManager()
+	if err != nil {
+		return err
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := manager.Logs(serviceName, lines, true, os.Stdout); err != nil {
 		pterm.Warning.Printf(fmt.Sprintf("Failed to fetch logs for '%s' service.", serviceName))
 		return err
 	}
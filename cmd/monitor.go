@@ -0,0 +1,171 @@
+// cmd/monitor.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/monitor"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+	"github.com/sSelmann/storycli/utils/notify"
+)
+
+var (
+	monitorInterval     time.Duration
+	monitorReferenceRPC string
+	monitorNoReference  bool
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Check the node on a schedule and send alerts when something is wrong",
+	Long: `Checks the node every interval and sends an alert through the notifiers set in
+the [monitor] section of ~/.config/storycli/config.toml when a service is down or
+restarted, an RPC is unreachable, the height stopped moving, the node is behind
+the reference RPC, the validator lost its voting power or missed blocks, the
+node has too few peers or the disk is nearly full.
+
+Every alert is sent once when it starts firing, again every "repeat" while it
+keeps firing if that is set, and once more when it resolves. With --dry-run the
+alerts are printed instead of sent. Run it as a service, e.g. with
+contrib/systemd/scli-monitor@.service.`,
+	Example: `  scli monitor
+  scli monitor --profile mainnet --interval 30s
+  scli monitor --dry-run
+  scli monitor test`,
+	RunE: runMonitor,
+}
+
+var monitorTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test message through every notifier",
+	RunE:  runMonitorTest,
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+	monitorCmd.AddCommand(monitorTestCmd)
+	monitorCmd.Flags().DurationVarP(&monitorInterval, "interval", "i", 0, "Time between checks (default: interval from the [monitor] section, 1m if unset)")
+	monitorCmd.Flags().StringVar(&monitorReferenceRPC, "reference-rpc", "", "CometBFT RPC to measure the block lag against (default: the public RPC of the network)")
+	monitorCmd.Flags().BoolVar(&monitorNoReference, "no-reference", false, "Don't compare the height with a reference RPC")
+	monitorCmd.MarkFlagsMutuallyExclusive("reference-rpc", "no-reference")
+}
+
+// monitorNotifiers loads the [monitor] section and builds its notifiers
+func monitorNotifiers() (config.MonitorConfig, []notify.Notifier, error) {
+	cfg, err := config.LoadMonitorConfig()
+	if err != nil {
+		return cfg, nil, err
+	}
+	notifiers, err := notify.FromConfig(cfg)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, notifiers, nil
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	cfg, notifiers, err := monitorNotifiers()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("interval") {
+		cfg.Interval = config.Duration(monitorInterval)
+	}
+	interval := time.Duration(cfg.Interval)
+	if interval < 5*time.Second {
+		return fmt.Errorf("the monitor interval must be at least 5s, got %s", interval)
+	}
+	if len(notifiers) == 0 {
+		pterm.Warning.Println("No notifiers are set in the [monitor] section of the config file; alerts are only logged.")
+	}
+
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	endpoints := nodestatus.Discover(node)
+	mon := monitor.New(node, endpoints, referenceOptions(node, monitorReferenceRPC, monitorNoReference), cfg)
+	dispatcher := &notify.Dispatcher{Notifiers: notifiers}
+	pterm.Info.Printf("Monitoring profile %s every %s (CometBFT RPC %s, geth RPC %s)\n", node.Name, interval, endpoints.CometRPC, endpoints.GethRPC)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		msgs := mon.Check()
+		for _, m := range msgs {
+			if m.State == notify.Firing {
+				pterm.Warning.Println(m.Title())
+			} else {
+				pterm.Info.Println(m.Title())
+			}
+		}
+		dispatcher.Send(ctx, msgs)
+
+		select {
+		case <-ctx.Done():
+			pterm.Info.Println("Monitor stopped.")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func runMonitorTest(cmd *cobra.Command, args []string) error {
+	_, notifiers, err := monitorNotifiers()
+	if err != nil {
+		return err
+	}
+	if len(notifiers) == 0 {
+		path, _ := config.ConfigFilePath()
+		return fmt.Errorf("no notifiers are set in the [monitor] section of %s", path)
+	}
+
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	now := time.Now().UTC()
+	m := notify.Message{
+		Profile: node.Name,
+		Host:    host,
+		Alert:   "test",
+		Rule:    "test",
+		State:   notify.Event,
+		Summary: "test message from scli monitor",
+		Since:   now,
+		Time:    now,
+	}
+	failed := 0
+	for _, n := range notifiers {
+		err := executor.Perform(fmt.Sprintf("send %q via %s", m.Title(), n.Name()), func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			return n.Notify(ctx, m)
+		})
+		if err != nil {
+			pterm.Error.Printf("%s: %v\n", n.Name(), err)
+			failed++
+			continue
+		}
+		if !dryRun {
+			pterm.Success.Printf("%s: sent\n", n.Name())
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notifiers failed", failed, len(notifiers))
+	}
+	return nil
+}
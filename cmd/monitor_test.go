@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+func TestRunMonitorTest(t *testing.T) {
+	offlineNode(t)
+	// Deliver for real, to the servers below
+	executor.SetActive(executor.System{})
+
+	var alerts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Alert string `json:"alert"`
+			State string `json:"state"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path == "/broken" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		alerts = append(alerts, body.Alert+" "+body.State)
+	}))
+	defer srv.Close()
+
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(urls ...string) {
+		var b strings.Builder
+		for _, url := range urls {
+			fmt.Fprintf(&b, "[[monitor.notifiers]]\ntype = \"webhook\"\nurl = %q\n\n", srv.URL+url)
+		}
+		if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("/ok")
+	if err := runMonitorTest(monitorTestCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(alerts, ", "); got != "test event" {
+		t.Errorf("received %q, want one test event", got)
+	}
+
+	write("/ok", "/broken")
+	if err := runMonitorTest(monitorTestCmd, nil); err == nil || !strings.Contains(err.Error(), "1 of 2 notifiers failed") {
+		t.Errorf("got %v, want 1 of 2 notifiers failed", err)
+	}
+	if len(alerts) != 2 {
+		t.Errorf("%d test events received, the working notifier must still be sent to", len(alerts))
+	}
+
+	write()
+	if err := runMonitorTest(monitorTestCmd, nil); err == nil || !strings.Contains(err.Error(), "no notifiers") {
+		t.Errorf("got %v, want no notifiers", err)
+	}
+}
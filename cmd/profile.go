@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Show the node profiles from the storycli config file",
+	Long: `Profiles describe the nodes on this machine: home and binary directories,
+service names, network, chain-id and RPC ports. They are read from
+~/.config/storycli/config.toml and selected with --profile.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured profiles",
+	RunE:  runProfileList,
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the settings of the active profile",
+	RunE:  runProfileShow,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileShowCmd)
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	names, err := config.ProfileNames()
+	if err != nil {
+		return err
+	}
+	active, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		marker := " "
+		if name == active.Name {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
+
+func runProfileShow(cmd *cobra.Command, args []string) error {
+	p, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	tableData := pterm.TableData{
+		{"Setting", "Value"},
+		{"profile", p.Name},
+		{"home_dir", p.HomeDir},
+		{"bin_dir", p.BinDir},
+		{"story_service", p.StoryService},
+		{"geth_service", p.GethService},
+		{"service_manager", p.ServiceManager},
+		{"container_image", p.ContainerImage},
+		{"network", p.Network},
+		{"chain_id", p.ChainID},
+		{"rpc_port", strconv.Itoa(p.RPCPort)},
+		{"geth_rpc_port", strconv.Itoa(p.GethRPCPort)},
+	}
+	if path, err := config.ConfigFilePath(); err == nil {
+		pterm.Info.Printf("Config file: %s\n", path)
+	}
+	return pterm.DefaultTable.WithHasHeader(true).WithData(tableData).Render()
+}
@@ -3,10 +3,11 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
 )
 
 var restartCmd = &cobra.Command{
@@ -22,7 +23,11 @@ func init() {
 func runRestart(cmd *cobra.Command, args []string) error {
 	pterm.Info.Printf("Restarting services...")
 
-	services := []string{"story", "story-geth"}
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	services := node.Services()
 	for _, service := range services {
 		if err := performServiceAction(service, restartService); err != nil {
 			return err
@@ -34,9 +39,12 @@ func runRestart(cmd *cobra.Command, args []string) error {
 }
 
 func restartService(serviceName string) error {
-	cmd := exec.Command("systemctl", "restart", serviceName)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		printError(fmt.Sprintf("Failed to restart '%s' service: %v\nOutput: %s", serviceName, err, string(output)))
+	manager, err := serviceManager()
+	if err != nil {
+		return err
+	}
+	if err := manager.Restart(serviceName); err != nil {
+		printError(fmt.Sprintf("Failed to restart '%s' service: %v", serviceName, err))
 		return err
 	}
 	return nil
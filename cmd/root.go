@@ -4,22 +4,42 @@ package cmd
 import (
 	"os"
 
+	"github.com/pterm/pterm"
 	"github.com/sSelmann/storycli/cmd/snapshot"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
 	"github.com/spf13/cobra"
 )
 
+var (
+	// profileFlag selects the node profile from the storycli config file
+	profileFlag string
+	// dryRun prints what would be run and written instead of doing it
+	dryRun bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "storycli",
 	Short: "Story CLI is built for setting up and managing your Story node.",
 	Long: `Story CLI is built for setting up and managing your Story node.
 It has commands to make your work easier and save your time.`,
+	PersistentPreRunE: loadProfile,
 }
 
-func completionCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "completion",
-		Short: "Generate the autocompletion script for the specified shell",
+// loadProfile resolves --profile and --dry-run before any command runs, so
+// every command works on the same node and executor
+func loadProfile(cmd *cobra.Command, args []string) error {
+	profile, err := config.LoadProfile(profileFlag)
+	if err != nil {
+		return err
 	}
+	config.SetActiveProfile(profile)
+
+	if dryRun {
+		pterm.Warning.Println("Dry run: commands and file changes are printed, not carried out.")
+		executor.SetActive(executor.NewDryRun(os.Stdout))
+	}
+	return nil
 }
 
 // Execute executes the root command.
@@ -31,13 +51,17 @@ func Execute() {
 }
 
 func init() {
-	completion := completionCommand()
-
-	// mark completion hidden
-	completion.Hidden = true
-	rootCmd.AddCommand(completion)
+	// keep the generated completion command, but hide it from help
+	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 
 	rootCmd.AddCommand(snapshot.GetSnapshotCmd())
 
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Node profile from ~/.config/storycli/config.toml (default: $"+config.ProfileEnv+", then default_profile)")
+	rootCmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names, _ := config.ProfileNames()
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the commands and file changes instead of carrying them out")
+
 	rootCmd.Flags().BoolP("help", "h", false, "help for storycli")
 }
@@ -1,16 +1,16 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/manifoldco/promptui"
 	"github.com/pterm/pterm"
@@ -19,13 +19,26 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/sSelmann/storycli/cmd/snapshot"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
 	"github.com/sSelmann/storycli/utils/bash"
 	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/cosmovisor"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/install"
+	"github.com/sSelmann/storycli/utils/service"
 )
 
 var (
 	moniker    string
 	customPort string
+	// setupNetwork overrides the network of the active profile
+	setupNetwork string
+	// useCosmovisor runs story under Cosmovisor
+	useCosmovisor bool
+	// skipVerify allows binaries without a published checksum
+	skipVerify bool
+	// installMethod is "release" or "source", see install.Method
+	installMethod string
 )
 
 var (
@@ -49,11 +62,57 @@ func init() {
 	setupNodeCmd.Flags().StringVar(&moniker, "moniker", "", "Your node's moniker")
 	setupNodeCmd.Flags().StringVar(&customPort, "customport", "", "First two digits of the custom port (default: 26)")
 	setupNodeCmd.Flags().StringVar(&pruningMode, "pruning-mode", "", "Pruning mode to use (pruned or archive)")
+	setupNodeCmd.Flags().StringVar(&setupNetwork, "network", "", "Network to join (default: network of the profile)")
+	setupNodeCmd.RegisterFlagCompletionFunc("network", completeNetworks)
+	setupNodeCmd.Flags().BoolVar(&useCosmovisor, "cosmovisor", false, "Run story under Cosmovisor so upgrades can be scheduled with 'scli upgrade schedule'")
+	setupNodeCmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Install release binaries even if the release publishes no checksum")
+	setupNodeCmd.Flags().StringVar(&installMethod, "install-method", string(install.MethodRelease), "Download release binaries (release) or build story and geth from source (source)")
+	setupNodeCmd.RegisterFlagCompletionFunc("install-method", completeInstallMethods)
+}
+
+// completeInstallMethods completes --install-method
+func completeInstallMethods(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var methods []string
+	for _, m := range install.Methods {
+		methods = append(methods, string(m))
+	}
+	return methods, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNetworks completes --network with the known networks
+func completeNetworks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return config.NetworkNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// selectNodeNetwork returns the active profile switched to --network, if
+// given, along with the catalogue entry of its network
+func selectNodeNetwork(networkFlag string) (config.Profile, config.Network, error) {
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return node, config.Network{}, err
+	}
+	name := networkFlag
+	if name == "" {
+		name = node.Network
+	}
+	network, err := config.LookupNetwork(name)
+	if err != nil {
+		return node, network, err
+	}
+	return node.WithNetwork(network), network, nil
 }
 
 func runSetupNode(cmd *cobra.Command, args []string) error {
+	node, network, err := selectNodeNetwork(setupNetwork)
+	if err != nil {
+		return err
+	}
+	if _, err := install.ParseMethod(installMethod); err != nil {
+		return err
+	}
+
 	// Step 0: System Resource Check
-	err := checkSystemResources()
+	err = checkSystemResources()
 	if err != nil {
 		return err
 	}
@@ -64,7 +123,7 @@ func runSetupNode(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	storyDir := fmt.Sprintf("%s/.story", homeDir)
+	storyDir := node.HomeDir
 	storyRepoDir := fmt.Sprintf("%s/story", homeDir)
 	if _, err := os.Stat(storyDir); err == nil {
 		// Directory exists
@@ -80,31 +139,35 @@ func runSetupNode(cmd *cobra.Command, args []string) error {
 
 		if strings.ToLower(result) == "yes" {
 			// Check if the services exist and are running
-			pterm.Info.Println("Checking if Story and Story-Geth services are active...")
-			storyActive := checkServiceExistsAndActive("story")
-			storyGethActive := checkServiceExistsAndActive("story-geth")
+			manager, err := service.ForProfile(node)
+			if err != nil {
+				return err
+			}
+			pterm.Info.Printf("Checking if %s and %s services are active...\n", node.StoryService, node.GethService)
+			storyActive, _ := manager.Active(node.StoryService)
+			storyGethActive, _ := manager.Active(node.GethService)
 
 			pterm.Info.Println("Stopping Story services...")
 			if storyActive {
-				err = bash.RunCommand("sudo", "systemctl", "stop", "story")
+				err = manager.Stop(node.StoryService)
 				if err != nil {
 					return fmt.Errorf("failed to stop Story service: %v", err)
 				}
 			}
 
 			if storyGethActive {
-				err = bash.RunCommand("sudo", "systemctl", "stop", "story-geth")
+				err = manager.Stop(node.GethService)
 				if err != nil {
 					return fmt.Errorf("failed to stop Story-Geth service: %v", err)
 				}
 			}
 
 			// Remove directories
-			err := os.RemoveAll(storyDir)
+			err = executor.Active().RemoveAll(storyDir)
 			if err != nil {
 				return fmt.Errorf("failed to remove Story directory: %v", err)
 			}
-			err = os.RemoveAll(storyRepoDir)
+			err = executor.Active().RemoveAll(storyRepoDir)
 			if err != nil {
 				return fmt.Errorf("failed to remove Story repository directory: %v", err)
 			}
@@ -167,8 +230,8 @@ func runSetupNode(cmd *cobra.Command, args []string) error {
 		pterm.Warning.Println(fmt.Sprintf("Failed to select pruning mode: %v", err))
 	}
 
-	// Proceed with setup without Cosmovisor
-	err = setupWithoutCosmovisor(moniker, customPort, pruningMode)
+	// Proceed with setup, with or without Cosmovisor
+	err = setupStoryNode(node, network, moniker, customPort, pruningMode, useCosmovisor)
 	if err != nil {
 		return err
 	}
@@ -176,12 +239,6 @@ func runSetupNode(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func checkServiceExistsAndActive(serviceName string) bool {
-	cmd := exec.Command("systemctl", "is-active", "--quiet", serviceName)
-	err := cmd.Run()
-	return err == nil // Eğer `systemctl is-active` başarılı dönerse, servis aktif demektir
-}
-
 func checkSystemResources() error {
 	pterm.Info.Println("Checking system resources...")
 
@@ -221,25 +278,22 @@ func checkSystemResources() error {
 	return nil
 }
 
-func getLatestReleaseTag(repo string) (string, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+// githubAPI is the GitHub REST API the latest releases are looked up in
+var githubAPI = "https://api.github.com"
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch latest release: %s", resp.Status)
-	}
+// apiTimeout bounds each request to the release and versions APIs
+const apiTimeout = 30 * time.Second
+
+func getLatestReleaseTag(repo string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
 
 	var release struct {
 		TagName string `json:"tag_name"`
 	}
-
-	err = json.NewDecoder(resp.Body).Decode(&release)
-	if err != nil {
-		return "", err
+	apiURL := fmt.Sprintf("%s/repos/%s/releases/latest", githubAPI, repo)
+	if err := provider.GetJSON(ctx, apiURL, &release); err != nil {
+		return "", fmt.Errorf("failed to fetch latest release: %w", err)
 	}
 
 	if release.TagName == "" {
@@ -249,7 +303,46 @@ func getLatestReleaseTag(repo string) (string, error) {
 	return release.TagName, nil
 }
 
-func setupWithoutCosmovisor(moniker, customPort, pruningMode string) error {
+// addBinary puts version of the binary from source into store, with the
+// configured install method. It reports whether the release binary was
+// downloaded or the binary was built from source in workDir.
+func addBinary(store install.Store, source install.Source, version, workDir string) error {
+	if store.Has(source.Binary, version) {
+		pterm.Info.Printf("%s %s is already installed in %s\n", source.Binary, version, store.Root)
+		// Nothing is fetched; Add only checks the stored binary
+		return store.Add(source.Binary, version, nil)
+	}
+
+	method, err := install.ParseMethod(installMethod)
+	if err != nil {
+		return err
+	}
+	installer := install.NewInstaller(source, method, skipVerify)
+	plan, err := installer.Resolve(version)
+	if errors.Is(err, install.ErrNoChecksum) {
+		return fmt.Errorf("%w (use --skip-verify to install it anyway)", err)
+	}
+	if err != nil {
+		return err
+	}
+	pterm.Info.Printf("Installing %s: %s\n", source.Binary, plan)
+	err = store.Add(source.Binary, version, func(path string) error {
+		return installer.Install(plan, workDir, path)
+	})
+	if err != nil {
+		return err
+	}
+	if plan.Method == install.MethodSource {
+		pterm.Success.Printf("%s %s built from source for %s\n", source.Binary, version, plan.Platform)
+	} else {
+		pterm.Success.Printf("%s %s installed from %s\n", source.Binary, version, plan.Asset.Name)
+	}
+	return nil
+}
+
+// setupStoryNode installs and configures story and geth for node. With
+// withCosmovisor, story is started through Cosmovisor.
+func setupStoryNode(node config.Profile, network config.Network, moniker, customPort, pruningMode string, withCosmovisor bool) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return err
@@ -265,114 +358,132 @@ func setupWithoutCosmovisor(moniker, customPort, pruningMode string) error {
 		return err
 	}
 
-	// Download geth binary
-	pterm.Info.Println("Downloading geth binary...")
-	downloadGethBinary()
-
-	// Make geth executable
-	pterm.Info.Println("Setting execute permissions for geth...")
-	err = bash.RunCommand("chmod", "+x", "geth")
-	if err != nil {
-		return err
-	}
+	store := install.NewStore(node)
 
-	// Move geth to ~/go/bin/
-	pterm.Info.Println("Moving geth to " + homeDir + "/go/bin/")
-	err = bash.RunCommand("rm", "-rf", homeDir+"/go/bin/geth")
+	// Install geth into the version store and link it into the bin dir
+	gethVersion, err := getLatestGethVersion(network)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to fetch Geth version: %v", err)
 	}
-	err = bash.RunCommand("mkdir", "-p", homeDir+"/go/bin")
+	err = addBinary(store, install.GethSource, gethVersion, homeDir)
 	if err != nil {
 		return err
 	}
-	err = bash.RunCommand("mv", homeDir+"/geth", homeDir+"/go/bin/")
+	pterm.Info.Println("Linking geth into " + node.BinDir)
+	err = store.Activate(install.Geth, gethVersion)
 	if err != nil {
 		return err
 	}
 
 	// Create necessary directories
 	pterm.Info.Println("Creating necessary directories...")
-	err = bash.RunCommand("bash", "-c", "[ ! -d \"$HOME/.story/story\" ] && mkdir -p \"$HOME/.story/story\"")
+	err = executor.Active().MkdirAll(node.StoryHome(), 0755)
 	if err != nil {
 		return err
 	}
 
 	// Install Story
-	pterm.Info.Println("Cloning Story repository...")
-	err = os.Chdir(homeDir)
+	tag, err := getLatestReleaseTag(install.StoryRepo)
 	if err != nil {
 		return err
 	}
-
-	err = bash.RunCommand("rm", "-rf", "story")
+	err = addBinary(store, install.StorySource, tag, homeDir)
 	if err != nil {
 		return err
 	}
-
-	err = bash.RunCommand("git", "clone", "https://github.com/piplabs/story")
+	pterm.Info.Println("Linking story into " + node.BinDir)
+	err = store.Activate(install.Story, tag)
 	if err != nil {
 		return err
 	}
 
-	// Get the latest release tag
-	tag, err := getLatestReleaseTag("piplabs/story")
+	// Initialize Story
+	pterm.Info.Println("Initializing Story node...")
+	err = bash.RunCommand(node.Binary("story"), "init", "--moniker", moniker, "--network", network.StoryNetwork, "--home", node.StoryHome())
 	if err != nil {
 		return err
 	}
 
-	pterm.Info.Println(fmt.Sprintf("Checking out version %s...", tag))
-	err = os.Chdir("story")
-	if err != nil {
-		return err
+	if withCosmovisor {
+		pterm.Info.Println("Installing Cosmovisor...")
+		err = cosmovisor.Install(node.BinDir)
+		if err != nil {
+			return err
+		}
+
+		layout := cosmovisor.ForProfile(node)
+		pterm.Info.Println("Setting up Cosmovisor in " + layout.Root())
+		err = layout.Init(node.Binary("story"))
+		if err != nil {
+			return err
+		}
 	}
 
-	err = bash.RunCommand("git", "checkout", tag)
+	// Download genesis and addrbook
+	pterm.Info.Println("Downloading genesis and addrbook...")
+	err = downloadGenesisAndAddrbookWithoutCosmovisor(network, node.ConfigDir())
 	if err != nil {
 		return err
 	}
 
-	pterm.Info.Println("Building Story binary...")
-	err = bash.RunCommand("env", "PATH=$PATH:/usr/local/go/bin:$HOME/go/bin", "go", "build", "-o", "story", "./client")
+	// The config files are created by story init, so editing them can only
+	// be previewed as a whole
+	err = executor.Perform("configure seeds, peers, ports and pruning in "+node.ConfigDir(), func() error {
+		return configureNode(node, network, customPort, pruningMode)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Move story binary to ~/go/bin/
-	pterm.Info.Println("Moving story binary to " + homeDir + "/go/bin/")
-	err = bash.RunCommand("rm", "-rf", homeDir+"/go/bin/story")
+	// Install the services so the snapshot apply can stop and start them
+	manager, err := service.ForProfile(node)
 	if err != nil {
 		return err
 	}
-	err = bash.RunCommand("mv", homeDir+"/story/story", homeDir+"/go/bin/")
+	pterm.Info.Printf("Creating %s services...\n", manager.Kind())
+	err = manager.Install(serviceUnits(node, network, customPort, withCosmovisor)...)
 	if err != nil {
 		return err
 	}
 
-	// Initialize Story
-	pterm.Info.Println("Initializing Story node...")
-	err = bash.RunCommand(homeDir+"/go/bin/story", "init", "--moniker", moniker, "--network", "odyssey")
+	// Download snapshot based on provider
+	pterm.Info.Println("Downloading snapshot...")
+	if err := snapshot.CallRunDownloadSnapshotManually(pruningMode, node); err != nil {
+		return fmt.Errorf("failed to apply the snapshot: %w", err)
+	}
+
+	// Enable and start services
+	pterm.Info.Println("Enabling and starting services...")
+	err = manager.Enable(node.StoryService, node.GethService)
 	if err != nil {
 		return err
 	}
-
-	// Configure seeds and peers
-	pterm.Info.Println("Configuring seeds and peers...")
-	err = configureSeedsAndPeersWithoutCosmovisor(homeDir)
+	err = manager.Restart(node.StoryService, node.GethService)
 	if err != nil {
 		return err
 	}
+	if withCosmovisor {
+		pterm.Success.Println("Node setup with Cosmovisor completed successfully.")
+	} else {
+		pterm.Success.Println("Node setup without Cosmovisor completed successfully.")
+	}
 
-	// Download genesis and addrbook
-	pterm.Info.Println("Downloading genesis and addrbook...")
-	err = downloadGenesisAndAddrbookWithoutCosmovisor(homeDir)
+	return nil
+}
+
+// configureNode sets the seeds, peers, ports and pruning of a node that
+// was just initialized
+func configureNode(node config.Profile, network config.Network, customPort, pruningMode string) error {
+	// Configure seeds and peers
+	pterm.Info.Println("Configuring seeds and peers...")
+	err := configureSeedsAndPeersWithoutCosmovisor(network, node.ConfigDir())
 	if err != nil {
 		return err
 	}
 
 	// Set custom ports in story.toml
 	pterm.Info.Println("Setting custom ports in story.toml...")
-	storyToml := fmt.Sprintf("%s/.story/story/config/story.toml", homeDir)
+	storyToml := filepath.Join(node.ConfigDir(), "story.toml")
 	err = replaceInFile(storyToml, `:1317`, fmt.Sprintf(":%s317", customPort))
 	if err != nil {
 		return err
@@ -384,7 +495,7 @@ func setupWithoutCosmovisor(moniker, customPort, pruningMode string) error {
 
 	// Set custom ports in config.toml
 	pterm.Info.Println("Setting custom ports in config.toml...")
-	configToml := fmt.Sprintf("%s/.story/story/config/config.toml", homeDir)
+	configToml := filepath.Join(node.ConfigDir(), "config.toml")
 	publicIP, err := getPublicIP()
 	if err != nil {
 		return err
@@ -426,48 +537,21 @@ func setupWithoutCosmovisor(moniker, customPort, pruningMode string) error {
 		}
 	}
 
-	// Create systemd service files
-	pterm.Info.Println("Creating systemd service files...")
-	err = createServiceFilesWithoutCosmovisor(homeDir, customPort)
-	if err != nil {
-		return err
-	}
-
-	// Download snapshot based on provider
-	pterm.Info.Println("Downloading snapshot...")
-	snapshot.CallRunDownloadSnapshotManually(pruningMode, homeDir)
-
-	// Enable and start services
-	pterm.Info.Println("Enabling and starting services...")
-	err = bash.RunCommand("sudo", "systemctl", "daemon-reload")
-	if err != nil {
-		return err
-	}
-	err = bash.RunCommand("sudo", "systemctl", "enable", "story", "story-geth")
-	if err != nil {
-		return err
-	}
-	err = bash.RunCommand("sudo", "systemctl", "restart", "story", "story-geth")
-	if err != nil {
-		return err
-	}
-	pterm.Success.Println("Node setup without Cosmovisor completed successfully.")
-
 	return nil
 }
 
-func configureSeedsAndPeersWithoutCosmovisor(homeDir string) error {
-	seeds := "51ff395354c13fab493a03268249a74860b5f9cc@story-testnet-seed.itrocket.net:26656"
-
+func configureSeedsAndPeersWithoutCosmovisor(network config.Network, configDir string) error {
 	// Fetch peers
-	cmd := exec.Command("bash", "-c", `curl -sS https://story-testnet-rpc.itrocket.net/net_info | jq -r '.result.peers[] | "\(.node_info.id)@\(.remote_ip):\(.node_info.listen_addr)"' | awk -F ':' '{print $1":"$(NF)}' | paste -sd, -`)
-	out, err := cmd.Output()
-	if err != nil {
-		return err
+	var peers string
+	if network.PeersRPC != "" {
+		out, err := executor.Query(executor.Cmd("bash", "-c", `curl -sS `+network.PeersRPC+`/net_info | jq -r '.result.peers[] | "\(.node_info.id)@\(.remote_ip):\(.node_info.listen_addr)"' | awk -F ':' '{print $1":"$(NF)}' | paste -sd, -`))
+		if err != nil {
+			return err
+		}
+		peers = strings.TrimSpace(string(out))
 	}
-	peers := strings.TrimSpace(string(out))
 
-	configFile := fmt.Sprintf("%s/.story/story/config/config.toml", homeDir)
+	configFile := filepath.Join(configDir, "config.toml")
 	// Read the config file
 	data, err := os.ReadFile(configFile)
 	if err != nil {
@@ -475,80 +559,100 @@ func configureSeedsAndPeersWithoutCosmovisor(homeDir string) error {
 	}
 	configContent := string(data)
 	// Update seeds and peers
-	configContent = regexp.MustCompile(`(?m)^seeds *=.*`).ReplaceAllString(configContent, fmt.Sprintf(`seeds = "%s"`, seeds))
-	configContent = regexp.MustCompile(`(?m)^persistent_peers *=.*`).ReplaceAllString(configContent, fmt.Sprintf(`persistent_peers = "%s"`, peers))
+	if network.Seeds != "" {
+		configContent = regexp.MustCompile(`(?m)^seeds *=.*`).ReplaceAllString(configContent, fmt.Sprintf(`seeds = "%s"`, network.Seeds))
+	}
+	if peers != "" {
+		configContent = regexp.MustCompile(`(?m)^persistent_peers *=.*`).ReplaceAllString(configContent, fmt.Sprintf(`persistent_peers = "%s"`, peers))
+	}
 
-	err = os.WriteFile(configFile, []byte(configContent), 0644)
+	err = executor.Active().WriteFile(configFile, []byte(configContent), 0644)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func downloadGenesisAndAddrbookWithoutCosmovisor(homeDir string) error {
-	endpoint, err := config.FetchItrocketRootEndpointFromAPI()
-	if err != nil {
-		return err
+func downloadGenesisAndAddrbookWithoutCosmovisor(network config.Network, configDir string) error {
+	if network.ItrocketChain == "" {
+		pterm.Info.Printf("No genesis and addrbook published for %s, keeping the ones written by story init.\n", network.Name)
+		return nil
+	}
+
+	endpoint, failures := config.ResolveItrocketRootEndpoint()
+	for _, err := range failures {
+		pterm.Warning.Println(err.Error())
 	}
 
-	err = bash.RunCommand("wget", "-q", "-O", homeDir+"/.story/story/config/genesis.json", "https://"+endpoint+"/testnet/story/genesis.json")
+	err := bash.RunCommand("wget", "-q", "-O", filepath.Join(configDir, "genesis.json"), network.GenesisURL(endpoint))
 	if err != nil {
 		return err
 	}
 
-	err = bash.RunCommand("wget", "-q", "-O", homeDir+"/.story/story/config/addrbook.json", "https://"+endpoint+"/testnet/story/addrbook.json")
+	err = bash.RunCommand("wget", "-q", "-O", filepath.Join(configDir, "addrbook.json"), network.AddrbookURL(endpoint))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func createServiceFilesWithoutCosmovisor(homeDir, customPort string) error {
-	// Create geth service file
-	gethServiceContent := fmt.Sprintf(`[Unit]
-Description=Story Geth daemon
-After=network-online.target
-
-[Service]
-User=%s
-ExecStart=%s/go/bin/geth --odyssey --syncmode full --http --http.api eth,net,web3,engine --http.vhosts '*' --http.addr 0.0.0.0 --http.port %s545 --authrpc.port %s551 --ws --ws.api eth,web3,net,txpool --ws.addr 0.0.0.0 --ws.port %s546
-Restart=on-failure
-RestartSec=3
-LimitNOFILE=65535
-
-[Install]
-WantedBy=multi-user.target
-`, os.Getenv("USER"), homeDir, customPort, customPort, customPort)
-
-	err := os.WriteFile("/etc/systemd/system/story-geth.service", []byte(gethServiceContent), 0644)
-	if err != nil {
-		return err
+// serviceUnits returns the geth and story services of node. With Cosmovisor,
+// story is run through it.
+func serviceUnits(node config.Profile, network config.Network, customPort string, withCosmovisor bool) []service.Unit {
+	story := storyServiceUnit(node)
+	if withCosmovisor {
+		story = cosmovisorServiceUnit(node)
 	}
+	return []service.Unit{gethServiceUnit(node, network, customPort), story}
+}
 
-	// Create story service file
-	storyServiceContent := fmt.Sprintf(`[Unit]
-Description=Story Service
-After=network.target
-
-[Service]
-User=%s
-WorkingDirectory=%s/.story/story
-ExecStart=%s/go/bin/story run
-
-Restart=on-failure
-RestartSec=5
-LimitNOFILE=65535
-
-[Install]
-WantedBy=multi-user.target
-`, os.Getenv("USER"), homeDir, homeDir)
+// gethServiceUnit returns the service running geth with its RPC ports
+// starting with customPort
+func gethServiceUnit(node config.Profile, network config.Network, customPort string) service.Unit {
+	return service.Unit{
+		Name:        node.GethService,
+		Description: "Story Geth daemon",
+		Command: []string{
+			node.Binary("geth"), network.GethFlag,
+			"--datadir", node.GethDataDir(),
+			"--syncmode", "full",
+			"--http", "--http.api", "eth,net,web3,engine", "--http.vhosts", "*",
+			"--http.addr", "0.0.0.0", "--http.port", customPort + "545",
+			"--authrpc.port", customPort + "551",
+			"--ws", "--ws.api", "eth,web3,net,txpool", "--ws.addr", "0.0.0.0", "--ws.port", customPort + "546",
+		},
+		RestartSec: 3,
+	}
+}
 
-	err = os.WriteFile("/etc/systemd/system/story.service", []byte(storyServiceContent), 0644)
-	if err != nil {
-		return err
+// storyServiceUnit returns the service that runs story directly
+func storyServiceUnit(node config.Profile) service.Unit {
+	return service.Unit{
+		Name:        node.StoryService,
+		Description: "Story Service",
+		Command:     []string{node.Binary("story"), "run", "--home", node.StoryHome()},
+		WorkDir:     node.StoryHome(),
+		RestartSec:  5,
 	}
+}
 
-	return nil
+// cosmovisorServiceUnit returns the service that runs story through
+// Cosmovisor. Upgrade binaries are never downloaded by Cosmovisor itself;
+// they are put in place with 'scli upgrade schedule'.
+func cosmovisorServiceUnit(node config.Profile) service.Unit {
+	layout := cosmovisor.ForProfile(node)
+	return service.Unit{
+		Name:        node.StoryService,
+		Description: "Story Service (Cosmovisor)",
+		Command:     []string{layout.Cosmovisor, "run", "run", "--home", layout.Home},
+		WorkDir:     layout.Home,
+		Env: append(layout.Env(),
+			"DAEMON_ALLOW_DOWNLOAD_BINARIES=false",
+			"DAEMON_RESTART_AFTER_UPGRADE=true",
+			"UNSAFE_SKIP_BACKUP=true",
+		),
+		RestartSec: 5,
+	}
 }
 
 func replaceInFile(filePath, old, new string) error {
@@ -559,55 +663,44 @@ func replaceInFile(filePath, old, new string) error {
 	content := string(data)
 	re := regexp.MustCompile(old)
 	content = re.ReplaceAllString(content, new)
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return executor.Active().WriteFile(filePath, []byte(content), 0644)
 }
 
 func getPublicIP() (string, error) {
-	cmd := exec.Command("wget", "-qO-", "eth0.me")
-	out, err := cmd.Output()
+	out, err := executor.Query(executor.Cmd("wget", "-qO-", "eth0.me"))
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(out)), nil
 }
 
-func getLatestGethVersion() (string, error) {
-	apiURL := "https://snapshot-external-providers-api.krews.xyz/story/latest_versions"
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest versions: %v", err)
-	}
-	defer resp.Body.Close()
+// getRecommendedVersions returns the versions published by the VersionsAPI
+// of network
+func getRecommendedVersions(network config.Network) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received non-OK status code %d from API", resp.StatusCode)
+	var versions map[string]string
+	if err := provider.GetJSON(ctx, network.VersionsAPI, &versions); err != nil {
+		return nil, fmt.Errorf("failed to fetch latest versions: %w", err)
 	}
+	return versions, nil
+}
 
-	var versions map[string]string
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		return "", fmt.Errorf("failed to decode API response: %v", err)
+// getLatestGethVersion returns the geth version recommended for network, or
+// the latest release if the network has no versions API
+func getLatestGethVersion(network config.Network) (string, error) {
+	if network.VersionsAPI == "" {
+		return getLatestReleaseTag("piplabs/story-geth")
 	}
 
+	versions, err := getRecommendedVersions(network)
+	if err != nil {
+		return "", err
+	}
 	gethVersion, exists := versions["geth-version"]
 	if !exists {
 		return "", fmt.Errorf("geth-version key not found in API response")
 	}
-
 	return gethVersion, nil
 }
-
-func downloadGethBinary() error {
-	gethVersion, err := getLatestGethVersion()
-	if err != nil {
-		return fmt.Errorf("failed to fetch Geth version: %v", err)
-	}
-
-	downloadURL := fmt.Sprintf("https://github.com/piplabs/story-geth/releases/download/%s/geth-linux-amd64", gethVersion)
-
-	err = bash.RunCommand("wget", "-O", "geth", downloadURL)
-	if err != nil {
-		return fmt.Errorf("failed to download Geth binary: %v", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/cmd/snapshot"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+var (
+	fakeAPIOnce sync.Once
+	fakeAPIURL  string
+)
+
+// fakeAPI answers the GitHub, versions and snapshot provider APIs. Every
+// other path is not found, so providers it doesn't serve are skipped. The
+// server is shared by all tests, as the snapshot package keeps the
+// providers it built for the first one.
+func fakeAPI() string {
+	fakeAPIOnce.Do(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/piplabs/story/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"tag_name": "v1.2.0"}`)
+		})
+		mux.HandleFunc("/repos/piplabs/story-geth/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"tag_name": "v1.0.2"}`)
+		})
+		mux.HandleFunc("/versions", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"geth-version": "v1.0.2", "story-version": "v1.2.0"}`)
+		})
+		mux.HandleFunc("/jnode", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"pruned": {"files": {
+				"story": {"size_gb": 1.5, "url": "https://jnode.test/story_pruned.tar.lz4"},
+				"geth": {"size_gb": 20, "url": "https://jnode.test/geth_pruned.tar.lz4"}},
+				"snapshot_height": "1234567", "time_ago": "1h"}}`)
+		})
+		fakeAPIURL = httptest.NewServer(mux).URL
+	})
+	return fakeAPIURL
+}
+
+// offlineNode runs a test against the default profile in a temporary home:
+// the APIs scli queries are answered by fakeAPI and the executor only
+// records what it is asked to do. It returns the profile, the recorder and
+// a function returning the recorded calls with the home replaced by ~.
+func offlineNode(t *testing.T) (config.Profile, *executor.Recorder, func() []string) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("TMPDIR", home)
+	// Keep GoPathEnv from adding the PATH of the machine to the commands
+	t.Setenv("PATH", os.Getenv("PATH")+string(filepath.ListSeparator)+"/usr/local/go/bin")
+
+	url := fakeAPI()
+	api := githubAPI
+	githubAPI = url
+	t.Cleanup(func() { githubAPI = api })
+
+	endpoints := fmt.Sprintf(`itrocket_root = "itrocket.test"
+itrocket_api = "%[1]s/itrocket-api"
+krews = "%[1]s/krews"
+jnode = "%[1]s/jnode"
+
+[itrocket]
+pruned = ["%[1]s/itrocket"]
+archive = ["%[1]s/itrocket"]
+`, url)
+	path, err := config.EndpointsFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(endpoints), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := config.DefaultProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.SetActiveProfile(node)
+
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	rec := executor.NewRecorder()
+	active := executor.Active()
+	executor.SetActive(rec)
+	t.Cleanup(func() { executor.SetActive(active) })
+
+	// Unit files, paths and temporary directories differ between runs
+	sizes := regexp.MustCompile(`\(\d+ bytes`)
+	temps := regexp.MustCompile(`(storycli-\w+-)\d+`)
+	commands := func() []string {
+		var lines []string
+		for _, c := range rec.Commands() {
+			c = strings.ReplaceAll(c, home, "~")
+			c = strings.ReplaceAll(c, url, "http://api.test")
+			c = temps.ReplaceAllString(c, "${1}*")
+			lines = append(lines, sizes.ReplaceAllString(c, "(N bytes"))
+		}
+		return lines
+	}
+	return node, rec, commands
+}
+
+// checkCommands compares recorded calls with the expected ones
+func checkCommands(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") == strings.Join(want, "\n") {
+		return
+	}
+	t.Errorf("recorded calls:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+}
+
+// Calls of a source install of geth v1.0.2 and story v1.2.0
+var sourceInstall = []string{
+	"mkdir -p ~/.story/versions/geth/v1.0.2",
+	"rm -rf ~/.story/versions/geth/v1.0.2/geth.tmp",
+	"rm -rf ~/story-geth",
+	"cd ~ && git clone --quiet --depth 1 --branch v1.0.2 https://github.com/piplabs/story-geth ~/story-geth",
+	"cd ~/story-geth && go build -o ~/.story/versions/geth/v1.0.2/geth.tmp ./cmd/geth",
+	"store ~/.story/versions/geth/v1.0.2/geth.tmp as ~/.story/versions/geth/v1.0.2/geth and record its sha256",
+	"link ~/go/bin/geth to ~/.story/versions/geth/v1.0.2/geth",
+	"mkdir -p ~/.story/story",
+	"mkdir -p ~/.story/versions/story/v1.2.0",
+	"rm -rf ~/.story/versions/story/v1.2.0/story.tmp",
+	"rm -rf ~/story",
+	"cd ~ && git clone --quiet --depth 1 --branch v1.2.0 https://github.com/piplabs/story ~/story",
+	"cd ~/story && go build -o ~/.story/versions/story/v1.2.0/story.tmp ./client",
+	"store ~/.story/versions/story/v1.2.0/story.tmp as ~/.story/versions/story/v1.2.0/story and record its sha256",
+	"link ~/go/bin/story to ~/.story/versions/story/v1.2.0/story",
+	"~/go/bin/story init --moniker test --network odyssey --home ~/.story/story",
+}
+
+// Calls of applying the pruned Jnode snapshot to a systemd node
+var jnodeApply = []string{
+	"sudo apt-get install wget lz4 aria2 pv -y",
+	"rm -rf ~/.story/.snapshot-staging",
+	"mkdir -p ~/.story/.snapshot-staging",
+	"download https://jnode.test/story_pruned.tar.lz4 and extract it into ~/.story/.snapshot-staging/story",
+	"download https://jnode.test/geth_pruned.tar.lz4 and extract it into ~/.story/.snapshot-staging/geth/odyssey/geth",
+	"check the snapshot staged in ~/.story/.snapshot-staging",
+	"sudo systemctl stop story story-geth",
+	"back up the validator signing state and carry it over into the new data",
+	"rm -rf ~/.story/story/data.old",
+	"mkdir -p ~/.story/story",
+	"mv ~/.story/story/data ~/.story/story/data.old",
+	"mv ~/.story/.snapshot-staging/story/data ~/.story/story/data",
+	"rm -rf ~/.story/geth/odyssey/geth/chaindata.old",
+	"mkdir -p ~/.story/geth/odyssey/geth",
+	"mv ~/.story/geth/odyssey/geth/chaindata ~/.story/geth/odyssey/geth/chaindata.old",
+	"mv ~/.story/.snapshot-staging/geth/odyssey/geth/chaindata ~/.story/geth/odyssey/geth/chaindata",
+	"rm -rf ~/.story/story/data.old",
+	"rm -rf ~/.story/geth/odyssey/geth/chaindata.old",
+	"check the validator signing state",
+	"sudo systemctl restart story story-geth",
+	"rm -rf ~/.story/.snapshot-staging",
+}
+
+// concat joins lists of calls
+func concat(lists ...[]string) []string {
+	var all []string
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	return all
+}
+
+func TestSetupStoryNode(t *testing.T) {
+	configure := []string{
+		"wget -q -O ~/.story/story/config/genesis.json https://itrocket.test/testnet/story/genesis.json",
+		"wget -q -O ~/.story/story/config/addrbook.json https://itrocket.test/testnet/story/addrbook.json",
+		"configure seeds, peers, ports and pruning in ~/.story/story/config",
+		"write /etc/systemd/system/story-geth.service (N bytes, mode 0644)",
+		"write /etc/systemd/system/story.service (N bytes, mode 0644)",
+		"sudo systemctl daemon-reload",
+	}
+	start := []string{
+		"sudo systemctl enable story story-geth",
+		"sudo systemctl restart story story-geth",
+	}
+
+	tests := []struct {
+		name       string
+		cosmovisor bool
+		// fail is a call that fails
+		fail    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "without cosmovisor",
+			want: concat(sourceInstall, configure, jnodeApply, start),
+		},
+		{
+			name:       "with cosmovisor",
+			cosmovisor: true,
+			want: concat(sourceInstall, []string{
+				"GOBIN=~/go/bin go install cosmossdk.io/tools/cosmovisor/cmd/cosmovisor@v1.7.0",
+				"DAEMON_NAME=story DAEMON_HOME=~/.story/story ~/go/bin/cosmovisor init ~/go/bin/story",
+			}, configure, jnodeApply, start),
+		},
+		{
+			name:    "snapshot fails",
+			fail:    "sudo systemctl stop story story-geth",
+			want:    concat(sourceInstall, configure, jnodeApply[:7], []string{"rm -rf ~/.story/.snapshot-staging"}),
+			wantErr: "failed to apply the snapshot",
+		},
+		{
+			name: "validator state fails",
+			fail: "back up the validator signing state and carry it over into the new data",
+			want: concat(sourceInstall, configure, jnodeApply[:8], []string{
+				"sudo systemctl restart story story-geth",
+				"rm -rf ~/.story/.snapshot-staging",
+			}),
+			wantErr: "failed to apply the snapshot",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, rec, commands := offlineNode(t)
+			if tt.fail != "" {
+				rec.Fail(tt.fail, errors.New("failed"))
+			}
+			network, err := config.LookupNetwork(node.Network)
+			if err != nil {
+				t.Fatal(err)
+			}
+			network.VersionsAPI = githubAPI + "/versions"
+
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Chdir(wd) })
+			for _, env := range []string{"MONIKER", "STORY_PORT", "PRUNING_MODE"} {
+				t.Setenv(env, "")
+			}
+			setFlag(t, &installMethod, "source")
+
+			dl, _, err := snapshot.GetSnapshotCmd().Find([]string{"download"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := dl.Flags().Set("provider", "Jnode"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { dl.Flags().Set("provider", "") })
+
+			err = setupStoryNode(node, network, "test", "26", "pruned", tt.cosmovisor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			checkCommands(t, commands(), tt.want)
+		})
+	}
+}
+
+// setFlag sets a flag variable for the test
+func setFlag(t *testing.T, flag *string, value string) {
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
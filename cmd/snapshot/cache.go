@@ -0,0 +1,109 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sSelmann/storycli/utils/config"
+)
+
+// providerCacheTTL is how long fetched provider data is reused
+const providerCacheTTL = 5 * time.Minute
+
+// providerCache keeps recently fetched provider data on disk so that e.g.
+// `snapshot providers` followed by `snapshot download` only queries the
+// provider APIs once.
+type providerCache struct {
+	Entries map[string]cachedProviderInfo `json:"entries"`
+}
+
+type cachedProviderInfo struct {
+	FetchedAt time.Time            `json:"fetched_at"`
+	Info      providerSnapshotInfo `json:"info"`
+}
+
+// providerCacheScope identifies the network and the endpoints the providers
+// were built with, so editing endpoints.toml doesn't serve data fetched
+// from the old endpoints
+func providerCacheScope(network string, endpoints config.Endpoints) string {
+	data, _ := json.Marshal(endpoints)
+	sum := sha256.Sum256(data)
+	return network + "@" + hex.EncodeToString(sum[:8])
+}
+
+func providerCacheKey(scope, name, mode string) string {
+	return scope + "/" + name + "/" + mode
+}
+
+// providerCachePath returns ~/.cache/storycli/snapshot_providers.json
+func providerCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "storycli", "snapshot_providers.json"), nil
+}
+
+// loadProviderCache reads the cache; a missing or broken cache is empty
+func loadProviderCache() *providerCache {
+	cache := &providerCache{Entries: map[string]cachedProviderInfo{}}
+	path, err := providerCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Entries == nil {
+		return &providerCache{Entries: map[string]cachedProviderInfo{}}
+	}
+	return cache
+}
+
+// get returns the cached data for a provider and mode in scope if it is
+// recent enough
+func (c *providerCache) get(scope, name, mode string) (providerSnapshotInfo, bool) {
+	entry, ok := c.Entries[providerCacheKey(scope, name, mode)]
+	if !ok || time.Since(entry.FetchedAt) > providerCacheTTL {
+		return providerSnapshotInfo{}, false
+	}
+	return entry.Info, true
+}
+
+func (c *providerCache) put(scope string, info providerSnapshotInfo) {
+	c.Entries[providerCacheKey(scope, info.ProviderName, info.Mode)] = cachedProviderInfo{
+		FetchedAt: time.Now(),
+		Info:      info,
+	}
+}
+
+// saveProviderCache writes the cache. Failing to do so only costs another
+// round of API requests next time, so errors are ignored.
+func saveProviderCache(c *providerCache) {
+	path, err := providerCachePath()
+	if err != nil {
+		return
+	}
+	for key, entry := range c.Entries {
+		if time.Since(entry.FetchedAt) > providerCacheTTL {
+			delete(c.Entries, key)
+		}
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
@@ -1,209 +1,125 @@
 package snapshot
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/pterm/pterm"
-	"github.com/sSelmann/storycli/snapshot_providers/itrocket"
-	"github.com/sSelmann/storycli/snapshot_providers/jnode"
-	"github.com/sSelmann/storycli/snapshot_providers/krews"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
+
+	// Register the built-in snapshot providers
+	_ "github.com/sSelmann/storycli/snapshot_providers/itrocket"
+	_ "github.com/sSelmann/storycli/snapshot_providers/jnode"
+	_ "github.com/sSelmann/storycli/snapshot_providers/krews"
 )
 
+// providerFetchTimeout bounds how long a single provider may take to report
+// its snapshots for all modes
+const providerFetchTimeout = 20 * time.Second
+
 // providerSnapshotInfo holds data displayed for each provider
-type providerSnapshotInfo struct {
-	ProviderName string
-	Mode         string
-	TotalSize    string // sum of snapshot_size + geth_snapshot_size (for Itrocket)
-	BlockHeight  string
-	TimeAgo      string
+type providerSnapshotInfo = provider.SnapshotInfo
+
+// loadedProviders caches the built providers so API responses fetched for
+// one mode are reused for the other.
+var loadedProviders []provider.SnapshotProvider
+
+// snapshotProviders returns every registered provider built for the selected
+// network with the current endpoints
+func snapshotProviders() []provider.SnapshotProvider {
+	if loadedProviders == nil {
+		loadedProviders = provider.All(resolveEndpoints(), network)
+	}
+	return loadedProviders
 }
 
+// providerResult is the outcome of fetching one provider for one mode
+type providerResult struct {
+	info    providerSnapshotInfo
+	fetched bool // freshly fetched, as opposed to cached or failed
+	skipped bool // the provider has nothing for the network
+}
+
+// fetchAllProvidersDataForModes queries all providers in parallel and
+// returns their data grouped by mode. Recent results are served from the
+// on-disk cache unless --refresh is given.
 func fetchAllProvidersDataForModes(modes []string) ([]providerSnapshotInfo, error) {
-	var results []providerSnapshotInfo
+	providers := snapshotProviders()
+	cache := loadProviderCache()
+	scope := providerCacheScope(network.Name, resolveEndpoints())
 
-	for _, mode := range modes {
-		// ITROCKET
-		totalSizeIt, blockHeightIt, timeAgoIt, err := itrocket.FetchItrocketForMode(mode, endpoints.Itrocket)
-		if err != nil {
-			pterm.Warning.Println("Failed to fetch Itrocket data (mode=%s): %v\n", mode, err)
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Itrocket",
-				Mode:         mode,
-				TotalSize:    "unknown",
-				BlockHeight:  "N/A",
-				TimeAgo:      "N/A",
-			})
-		} else {
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Itrocket",
-				Mode:         mode,
-				TotalSize:    totalSizeIt,
-				BlockHeight:  blockHeightIt,
-				TimeAgo:      timeAgoIt,
-			})
-		}
+	results := make([][]providerResult, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p provider.SnapshotProvider) {
+			defer wg.Done()
+			results[i] = fetchProviderData(p, modes, cache, scope)
+		}(i, p)
+	}
+	wg.Wait()
 
-		// KREWS
-		kPrunedSize, kArchiveSize,
-			kPrunedBlock, kArchiveBlock,
-			kPrunedTimeAgo, kArchiveTimeAgo,
-			err := krews.FetchSnapshotSizesKrews(endpoints.Krews)
-		if err != nil {
-			pterm.Warning.Printf("Failed to fetch Krews data (mode=%s): %v\n", mode, err)
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Krews",
-				Mode:         mode,
-				TotalSize:    "unknown",
-				BlockHeight:  "N/A",
-				TimeAgo:      "N/A",
-			})
-		} else {
-			var size, blockH, timeAgo string
-			if mode == "pruned" {
-				size = kPrunedSize
-				blockH = kPrunedBlock
-				timeAgo = kPrunedTimeAgo
-			} else {
-				size = kArchiveSize
-				blockH = kArchiveBlock
-				timeAgo = kArchiveTimeAgo
+	var data []providerSnapshotInfo
+	updated := false
+	for m := range modes {
+		for i := range providers {
+			r := results[i][m]
+			if r.skipped {
+				continue
 			}
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Krews",
-				Mode:         mode,
-				TotalSize:    size,
-				BlockHeight:  blockH,
-				TimeAgo:      timeAgo,
-			})
-		}
-
-		// JNODE
-		jPrunedSize, jArchiveSize,
-			jPrunedBlock, jArchiveBlock,
-			jPrunedTimeAgo, jArchiveTimeAgo,
-			err := jnode.FetchSnapshotSizesJnode()
-		if err != nil {
-			pterm.Warning.Printf("Failed to fetch Jnode data (mode=%s): %v\n", mode, err)
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Jnode",
-				Mode:         mode,
-				TotalSize:    "unknown",
-				BlockHeight:  "N/A",
-				TimeAgo:      "N/A",
-			})
-		} else {
-			var size, blockH, timeAgo string
-			if mode == "pruned" {
-				size = jPrunedSize
-				blockH = jPrunedBlock
-				timeAgo = jPrunedTimeAgo
-			} else {
-				size = jArchiveSize
-				blockH = jArchiveBlock
-				timeAgo = jArchiveTimeAgo
+			if r.fetched {
+				cache.put(scope, r.info)
+				updated = true
 			}
-			results = append(results, providerSnapshotInfo{
-				ProviderName: "Jnode",
-				Mode:         mode,
-				TotalSize:    size,
-				BlockHeight:  blockH,
-				TimeAgo:      timeAgo,
-			})
+			data = append(data, r.info)
 		}
 	}
 
-	return results, nil
+	if updated {
+		saveProviderCache(cache)
+	}
+	return data, nil
 }
 
 func fetchAllProvidersDataForMode(mode string) ([]providerSnapshotInfo, error) {
-	var results []providerSnapshotInfo
-
-	// ITROCKET
-	totalSizeIt, blockHeightIt, timeAgoIt, err := itrocket.FetchItrocketForMode(mode, endpoints.Itrocket)
-	if err != nil {
-		pterm.Warning.Println("Failed to fetch Itrocket data (mode=%s): %v\n", mode, err)
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Itrocket",
-			Mode:         mode,
-			TotalSize:    "unknown",
-			BlockHeight:  "N/A",
-			TimeAgo:      "N/A",
-		})
-	} else {
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Itrocket",
-			Mode:         mode,
-			TotalSize:    totalSizeIt,
-			BlockHeight:  blockHeightIt,
-			TimeAgo:      timeAgoIt,
-		})
-	}
+	return fetchAllProvidersDataForModes([]string{mode})
+}
 
-	// KREWS
-	kPrunedSize, kArchiveSize,
-		kPrunedBlock, kArchiveBlock,
-		kPrunedTimeAgo, kArchiveTimeAgo,
-		err := krews.FetchSnapshotSizesKrews(endpoints.Krews)
-	if err != nil {
-		pterm.Warning.Printf("Failed to fetch Krews data (mode=%s): %v\n", mode, err)
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Krews",
-			Mode:         mode,
-			TotalSize:    "unknown",
-			BlockHeight:  "N/A",
-			TimeAgo:      "N/A",
-		})
-	} else {
-		var size, blockH, timeAgo string
-		if mode == "pruned" {
-			size = kPrunedSize
-			blockH = kPrunedBlock
-			timeAgo = kPrunedTimeAgo
-		} else {
-			size = kArchiveSize
-			blockH = kArchiveBlock
-			timeAgo = kArchiveTimeAgo
+// fetchProviderData fetches every mode from one provider under a shared
+// deadline, unless the cache has it for scope. Modes are fetched one after
+// another so a provider can reuse a response that covers several modes.
+func fetchProviderData(p provider.SnapshotProvider, modes []string, cache *providerCache, scope string) []providerResult {
+	ctx, cancel := context.WithTimeout(context.Background(), providerFetchTimeout)
+	defer cancel()
+
+	results := make([]providerResult, len(modes))
+	for m, mode := range modes {
+		if !refreshFlag {
+			if info, ok := cache.get(scope, p.Name(), mode); ok {
+				results[m] = providerResult{info: info}
+				continue
+			}
 		}
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Krews",
-			Mode:         mode,
-			TotalSize:    size,
-			BlockHeight:  blockH,
-			TimeAgo:      timeAgo,
-		})
-	}
 
-	// JNODE
-	jPrunedSize, jArchiveSize,
-		jPrunedBlock, jArchiveBlock,
-		jPrunedTimeAgo, jArchiveTimeAgo,
-		err := jnode.FetchSnapshotSizesJnode()
-	if err != nil {
-		pterm.Warning.Printf("Failed to fetch Jnode data (mode=%s): %v\n", mode, err)
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Jnode",
-			Mode:         mode,
-			TotalSize:    "unknown",
-			BlockHeight:  "N/A",
-			TimeAgo:      "N/A",
-		})
-	} else {
-		var size, blockH, timeAgo string
-		if mode == "pruned" {
-			size = jPrunedSize
-			blockH = jPrunedBlock
-			timeAgo = jPrunedTimeAgo
-		} else {
-			size = jArchiveSize
-			blockH = jArchiveBlock
-			timeAgo = jArchiveTimeAgo
+		info, err := p.FetchSnapshotInfo(ctx, mode)
+		if errors.Is(err, provider.ErrNetworkUnsupported) {
+			results[m] = providerResult{skipped: true}
+			continue
+		}
+		if err != nil {
+			pterm.Warning.Printf("Failed to fetch %s data (mode=%s): %v\n", p.Name(), mode, err)
+			results[m] = providerResult{info: providerSnapshotInfo{
+				ProviderName: p.Name(),
+				Mode:         mode,
+				TotalSize:    "unknown",
+				BlockHeight:  "N/A",
+				TimeAgo:      "N/A",
+			}}
+			continue
 		}
-		results = append(results, providerSnapshotInfo{
-			ProviderName: "Jnode",
-			Mode:         mode,
-			TotalSize:    size,
-			BlockHeight:  blockH,
-			TimeAgo:      timeAgo,
-		})
+		results[m] = providerResult{info: info, fetched: true}
 	}
-
-	return results, nil
+	return results
 }
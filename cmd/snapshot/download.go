@@ -4,13 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/manifoldco/promptui"
 	"github.com/pterm/pterm"
-	"github.com/sSelmann/storycli/snapshot_providers/itrocket"
-	"github.com/sSelmann/storycli/snapshot_providers/jnode"
-	"github.com/sSelmann/storycli/snapshot_providers/krews"
-	"github.com/sSelmann/storycli/utils/bash"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
+	"github.com/sSelmann/storycli/utils/config"
 	"github.com/spf13/cobra"
 )
 
@@ -27,14 +28,37 @@ func runDownloadSnapshot(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse output-path: %w", err)
 	}
-	return RunDownloadSnapshotCore(pruningMode, outputPath, false, homeDirFlag)
+	restoreOutput, err := setupOutput(cmd, outputFlag)
+	if err != nil {
+		return err
+	}
+	defer restoreOutput()
+	if err := selectNetwork(); err != nil {
+		return err
+	}
+
+	err = RunDownloadSnapshotCore(pruningMode, outputPath, false)
+	result := snapshotEvent{Event: "result", Network: network.Name, Mode: pruningMode, Provider: selectedProvider, OutputPath: outputPath, Status: "success"}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	emit(result)
+	return err
 }
 
-func CallRunDownloadSnapshotManually(pruningMode string, homedir string) error {
-	return RunDownloadSnapshotCore(pruningMode, "", true, homedir+".story")
+// CallRunDownloadSnapshotManually applies a snapshot to the node of profile
+// as part of another command, e.g. setup node.
+func CallRunDownloadSnapshotManually(pruningMode string, node config.Profile) error {
+	homeDirFlag = node.HomeDir
+	networkFlag = node.Network
+	if err := selectNetwork(); err != nil {
+		return err
+	}
+	return RunDownloadSnapshotCore(pruningMode, "", true)
 }
 
-func RunDownloadSnapshotCore(pruningMode, outputPath string, isManual bool, storyDir string) error {
+func RunDownloadSnapshotCore(pruningMode, outputPath string, isManual bool) error {
 	if !isManual {
 		PruningModeInformation()
 	}
@@ -46,74 +70,177 @@ func RunDownloadSnapshotCore(pruningMode, outputPath string, isManual bool, stor
 			return fmt.Errorf("failed to select pruning mode: %w", err)
 		}
 	}
+	if pruningMode != "pruned" && pruningMode != "archive" {
+		return fmt.Errorf("invalid pruning mode: %s (use pruned or archive)", pruningMode)
+	}
 
-	pterm.Info.Println(fmt.Sprintf("Fetching snapshot data for providers (mode=%s)...", pruningMode))
+	pterm.Info.Println(fmt.Sprintf("Fetching snapshot data for providers (network=%s, mode=%s)...", network.Name, pruningMode))
+	emit(snapshotEvent{Event: "fetching", Network: network.Name, Mode: pruningMode})
 	providersData, err := fetchAllProvidersDataForMode(pruningMode)
-	if err != nil || len(providersData) == 0 {
+	if err != nil {
 		return fmt.Errorf("no snapshot data found: %w", err)
 	}
+	if len(providersData) == 0 {
+		return errors.New("no snapshot data found")
+	}
+	emit(snapshotEvent{Event: "providers", Mode: pruningMode, Providers: providersData})
 
-	selectedProvider, err := selectSnapshotProvider(providersData)
+	chosen, err := chooseSnapshotProvider(providersData)
 	if err != nil {
 		return err
 	}
+	providerName := chosen.ProviderName
+	selectedProvider = providerName
+	emit(snapshotEvent{Event: "selected", Mode: pruningMode, Provider: providerName})
 
 	if outputPath != "" {
-		pterm.Info.Println(fmt.Sprintf("Downloading snapshot from %s to %s...", selectedProvider, outputPath))
-		return downloadToPath(selectedProvider, pruningMode, outputPath)
+		pterm.Info.Println(fmt.Sprintf("Downloading snapshot from %s to %s...", providerName, outputPath))
+		emit(snapshotEvent{Event: "downloading", Mode: pruningMode, Provider: providerName, OutputPath: outputPath})
+		return downloadToPath(chosen, outputPath)
 	} else if !isManual {
-		storyDir, err := os.UserHomeDir()
+		node, err := nodeProfile()
 		if err != nil {
 			return err
 		}
-
-		storyDir = storyDir + ".story"
-		sDir := storyDir + "/story"
-		gDir := storyDir + "/geth"
-		if _, err := os.Stat(sDir); err == nil {
+		sDir := node.StoryHome()
+		gDir := filepath.Join(node.HomeDir, "geth")
+		if _, err := os.Stat(sDir); err != nil {
 			pterm.Error.Println("Story path not found: " + sDir)
 			return err
 		}
-		if _, err := os.Stat(gDir); err == nil {
-			pterm.Error.Println("Geth path not found:" + gDir)
+		if _, err := os.Stat(gDir); err != nil {
+			pterm.Error.Println("Geth path not found: " + gDir)
 			return err
 		}
 	}
 
-	if !isManual {
-		pterm.Info.Println("Stopping services...")
-		if err := bash.RunCommand("sudo", "systemctl", "stop", "story", "story-geth"); err != nil {
-			return err
+	// The services keep running while the snapshot is downloaded, they are
+	// only stopped to swap the new data in
+	emit(snapshotEvent{Event: "applying", Mode: pruningMode, Provider: providerName})
+	return downloadAndApplySnapshot(chosen)
+}
+
+// nonInteractive reports whether prompts are disabled
+func nonInteractive() bool {
+	return yesFlag || jsonOutput()
+}
+
+// chooseSnapshotProvider picks the snapshot of the provider from --provider
+// or --auto, and asks the user otherwise
+func chooseSnapshotProvider(providersData []providerSnapshotInfo) (providerSnapshotInfo, error) {
+	if selectedProvider != "" {
+		p, err := provider.Find(snapshotProviders(), selectedProvider)
+		if err != nil {
+			return providerSnapshotInfo{}, err
 		}
+		for _, pd := range providersData {
+			if pd.ProviderName == p.Name() {
+				return pd, nil
+			}
+		}
+		return providerSnapshotInfo{}, fmt.Errorf("%s has no snapshots for network %s", p.Name(), network.Name)
 	}
 
-	return downloadAndApplySnapshot(selectedProvider, pruningMode)
+	if autoFlag != "" {
+		info, err := autoSelectProvider(providersData, autoFlag)
+		if err != nil {
+			return providerSnapshotInfo{}, err
+		}
+		pterm.Info.Printf("Selected %s (%s, size: %s, height: %s)\n", info.ProviderName, autoFlag, info.TotalSize, info.BlockHeight)
+		return info, nil
+	}
+
+	if nonInteractive() {
+		return providerSnapshotInfo{}, errors.New("no provider selected: use --provider or --auto")
+	}
+	return selectSnapshotProvider(providersData)
 }
 
-func downloadToPath(provider, mode, path string) error {
-	switch provider {
-	case "Itrocket":
-		return itrocket.DownloadSnapshotToPathItrocket(mode, path, endpoints.Itrocket)
-	case "Krews":
-		return krews.DownloadSnapshotToPathKrews(mode, path)
-	case "Jnode":
-		return jnode.DownloadSnapshotToPathJnode(mode, path, endpoints.Jnode)
-	default:
-		return errors.New("unsupported provider")
+// Strategies for --auto
+const (
+	autoFreshest = "freshest"
+	autoSmallest = "smallest"
+)
+
+// autoSelectProvider returns the provider with the highest block height
+// (freshest) or the smallest total size (smallest). Providers whose data
+// could not be fetched are skipped.
+func autoSelectProvider(providersData []providerSnapshotInfo, strategy string) (providerSnapshotInfo, error) {
+	var best providerSnapshotInfo
+	var bestValue float64
+	found := false
+
+	for _, pd := range providersData {
+		var value float64
+		var ok bool
+		switch strategy {
+		case autoFreshest:
+			var height int64
+			height, ok = pd.Height()
+			// Negate so that the smallest value wins for both strategies
+			value = -float64(height)
+		case autoSmallest:
+			value, ok = parseSizeGB(pd.TotalSize)
+		default:
+			return best, fmt.Errorf("unsupported --auto strategy: %s (use %s or %s)", strategy, autoFreshest, autoSmallest)
+		}
+		if !ok {
+			continue
+		}
+		if !found || value < bestValue {
+			best, bestValue, found = pd, value, true
+		}
 	}
+
+	if !found {
+		return best, fmt.Errorf("no provider has usable snapshot data for --auto %s", strategy)
+	}
+	return best, nil
 }
 
-func downloadAndApplySnapshot(provider, mode string) error {
-	switch provider {
-	case "Itrocket":
-		return itrocket.DownloadSnapshotItrocket(homeDirFlag, mode)
-	case "Krews":
-		return krews.DownloadSnapshotKrews(homeDirFlag, mode)
-	case "Jnode":
-		return jnode.DownloadSnapshotJnode(homeDirFlag, mode, endpoints.Jnode)
+// parseSizeGB parses sizes like "52.20G", "121 GB" or "1.2TiB" into GB
+func parseSizeGB(s string) (float64, bool) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "T"):
+		multiplier = 1024
+	case strings.HasSuffix(s, "G"):
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1.0 / 1024
 	default:
-		return errors.New("unsupported provider")
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// downloadToPath downloads the chosen snapshot to path
+func downloadToPath(info providerSnapshotInfo, path string) error {
+	p, err := provider.Find(snapshotProviders(), info.ProviderName)
+	if err != nil {
+		return err
+	}
+	return p.DownloadToPath(info, path, snapshotOptions())
+}
+
+// downloadAndApplySnapshot applies the chosen snapshot to the node
+func downloadAndApplySnapshot(info providerSnapshotInfo) error {
+	p, err := provider.Find(snapshotProviders(), info.ProviderName)
+	if err != nil {
+		return err
+	}
+	node, err := nodeProfile()
+	if err != nil {
+		return err
 	}
+	return p.Apply(node, info, snapshotOptions())
 }
 
 func PruningModeInformation() {
@@ -124,6 +251,9 @@ func PruningModeInformation() {
 
 func SelectPruningMode() (string, error) {
 	if pruningMode == "" {
+		if nonInteractive() {
+			return "", errors.New("no pruning mode selected: use --mode pruned or --mode archive")
+		}
 		pmPrompt := promptui.Select{
 			Label: "Select the pruning mode",
 			Items: []string{"pruned", "archive"},
@@ -137,9 +267,9 @@ func SelectPruningMode() (string, error) {
 	return pruningMode, nil
 }
 
-func selectSnapshotProvider(providersData []providerSnapshotInfo) (string, error) {
+func selectSnapshotProvider(providersData []providerSnapshotInfo) (providerSnapshotInfo, error) {
 	if len(providersData) == 0 {
-		return "", errors.New("no providers data found")
+		return providerSnapshotInfo{}, errors.New("no providers data found")
 	}
 
 	type providerDisplay struct {
@@ -179,7 +309,12 @@ func selectSnapshotProvider(providersData []providerSnapshotInfo) (string, error
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return "", err
+		return providerSnapshotInfo{}, err
 	}
-	return items[i].Name, nil
+	return items[i].original, nil
+}
+
+// snapshotOptions builds the provider options from the download flags
+func snapshotOptions() provider.Options {
+	return provider.Options{Verify: verifyFlag, Resumable: resumableFlag, PreserveOwnership: preserveOwnerFlag, KeepOld: keepOldFlag, Progress: progressOut}
 }
@@ -0,0 +1,280 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// jnodeRequests counts the requests to the Jnode API of offlineSnapshots
+var jnodeRequests atomic.Int32
+
+// offlineSnapshots runs a test against the default profile in a temporary
+// home with a node set up. Only the Jnode API answers, with a pruned
+// snapshot, and the executor records what it is asked to do. It returns the
+// recorded calls with the home replaced by ~.
+func offlineSnapshots(t *testing.T) func() []string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+
+	mux := http.NewServeMux()
+	jnodeRequests.Store(0)
+	mux.HandleFunc("/jnode", func(w http.ResponseWriter, r *http.Request) {
+		jnodeRequests.Add(1)
+		fmt.Fprint(w, `{"pruned": {"files": {
+			"story": {"size_gb": 1.5, "url": "https://jnode.test/story_pruned.tar.lz4"},
+			"geth": {"size_gb": 20, "url": "https://jnode.test/geth_pruned.tar.lz4"}},
+			"snapshot_height": "1234567", "time_ago": "1h"}}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	endpoints := fmt.Sprintf(`itrocket_api = "%[1]s/itrocket-api"
+krews = "%[1]s/krews"
+jnode = "%[1]s/jnode"
+
+[itrocket]
+pruned = ["%[1]s/itrocket"]
+archive = ["%[1]s/itrocket"]
+`, srv.URL)
+	path, err := config.EndpointsFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(endpoints), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := config.DefaultProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.SetActiveProfile(node)
+	for _, dir := range []string{node.StoryHome(), filepath.Join(node.HomeDir, "geth")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Providers and endpoints are built once per run of scli
+	loadedProviders = nil
+	endpointsOnce = sync.Once{}
+	t.Cleanup(func() {
+		loadedProviders = nil
+		endpointsOnce = sync.Once{}
+	})
+
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	rec := executor.NewRecorder()
+	active := executor.Active()
+	executor.SetActive(rec)
+	t.Cleanup(func() { executor.SetActive(active) })
+
+	return func() []string {
+		var lines []string
+		for _, c := range rec.Commands() {
+			lines = append(lines, strings.ReplaceAll(c, home, "~"))
+		}
+		return lines
+	}
+}
+
+// setFlag sets a flag of the download command for the test
+func setFlag(t *testing.T, name, value string) {
+	if err := downloadCmd.Flags().Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		f := downloadCmd.Flags().Lookup(name)
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
+
+func TestRunDownloadSnapshot(t *testing.T) {
+	apply := []string{
+		"sudo apt-get install wget lz4 aria2 pv -y",
+		"rm -rf ~/.story/.snapshot-staging",
+		"mkdir -p ~/.story/.snapshot-staging",
+		"download https://jnode.test/story_pruned.tar.lz4 and extract it into ~/.story/.snapshot-staging/story",
+		"download https://jnode.test/geth_pruned.tar.lz4 and extract it into ~/.story/.snapshot-staging/geth/odyssey/geth",
+		"check the snapshot staged in ~/.story/.snapshot-staging",
+		"sudo systemctl stop story story-geth",
+		"back up the validator signing state and carry it over into the new data",
+		"rm -rf ~/.story/story/data.old",
+		"mkdir -p ~/.story/story",
+		"mv ~/.story/story/data ~/.story/story/data.old",
+		"mv ~/.story/.snapshot-staging/story/data ~/.story/story/data",
+		"rm -rf ~/.story/geth/odyssey/geth/chaindata.old",
+		"mkdir -p ~/.story/geth/odyssey/geth",
+		"mv ~/.story/geth/odyssey/geth/chaindata ~/.story/geth/odyssey/geth/chaindata.old",
+		"mv ~/.story/.snapshot-staging/geth/odyssey/geth/chaindata ~/.story/geth/odyssey/geth/chaindata",
+		"rm -rf ~/.story/story/data.old",
+		"rm -rf ~/.story/geth/odyssey/geth/chaindata.old",
+		"check the validator signing state",
+		"sudo systemctl restart story story-geth",
+		"rm -rf ~/.story/.snapshot-staging",
+	}
+
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "apply from a provider",
+			flags: map[string]string{"provider": "Jnode", "mode": "pruned"},
+			want:  apply,
+		},
+		{
+			name:  "apply the freshest snapshot",
+			flags: map[string]string{"auto": "freshest", "mode": "pruned"},
+			want:  apply,
+		},
+		{
+			name:  "download to a path",
+			flags: map[string]string{"provider": "Jnode", "mode": "pruned", "output-path": "/snapshots"},
+			want: []string{
+				"download https://jnode.test/story_pruned.tar.lz4 to /snapshots/story_pruned.tar.lz4",
+				"download https://jnode.test/geth_pruned.tar.lz4 to /snapshots/geth_pruned.tar.lz4",
+			},
+		},
+		{
+			name:    "no provider without prompts",
+			flags:   map[string]string{"mode": "pruned", "yes": "true"},
+			wantErr: "no provider selected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := offlineSnapshots(t)
+			for name, value := range tt.flags {
+				setFlag(t, name, value)
+			}
+
+			err := runDownloadSnapshot(downloadCmd, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := commands(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("recorded calls:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+			}
+		})
+	}
+}
+
+func TestRunDownloadSnapshotJSON(t *testing.T) {
+	offlineSnapshots(t)
+	setFlag(t, "provider", "Jnode")
+	setFlag(t, "mode", "pruned")
+	setFlag(t, "output", "json")
+	var stdout, stderr bytes.Buffer
+	downloadCmd.SetOut(&stdout)
+	downloadCmd.SetErr(&stderr)
+	t.Cleanup(func() {
+		downloadCmd.SetOut(nil)
+		downloadCmd.SetErr(nil)
+	})
+	osStdout := os.Stdout
+
+	if err := runDownloadSnapshot(downloadCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stdout != osStdout {
+		t.Error("os.Stdout was replaced")
+	}
+	if jsonOutput() {
+		t.Error("JSON output is still on after the command")
+	}
+
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var e snapshotEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("stdout line %q is not an event: %v", line, err)
+		}
+		events = append(events, e.Event)
+	}
+	want := "fetching providers selected applying result"
+	if got := strings.Join(events, " "); got != want {
+		t.Errorf("events %q, want %q", got, want)
+	}
+}
+
+func TestRunDownloadSnapshotUsesCache(t *testing.T) {
+	commands := offlineSnapshots(t)
+	// A new run of scli, as the providers are built once per run
+	newRun := func() {
+		loadedProviders = nil
+		endpointsOnce = sync.Once{}
+	}
+
+	// scli snapshot providers
+	if _, err := fetchAllProvidersDataForModes([]string{"pruned"}); err != nil {
+		t.Fatal(err)
+	}
+	newRun()
+	// scli snapshot download
+	setFlag(t, "provider", "Jnode")
+	setFlag(t, "mode", "pruned")
+	setFlag(t, "output-path", "/snapshots")
+	if err := runDownloadSnapshot(downloadCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := jnodeRequests.Load(); n != 1 {
+		t.Errorf("%d requests to the Jnode API, want 1", n)
+	}
+	want := []string{
+		"download https://jnode.test/story_pruned.tar.lz4 to /snapshots/story_pruned.tar.lz4",
+		"download https://jnode.test/geth_pruned.tar.lz4 to /snapshots/geth_pruned.tar.lz4",
+	}
+	if got := commands(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("recorded calls:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+
+	// Changed endpoints are queried again
+	path, err := config.EndpointsFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "/jnode\"", "/jnode?v=2\"", 1))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	newRun()
+	if _, err := fetchAllProvidersDataForModes([]string{"pruned"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := jnodeRequests.Load(); n != 2 {
+		t.Errorf("%d requests to the Jnode API after changing its endpoint, want 2", n)
+	}
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// Output formats supported by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// snapshotEvent is one line of --output json
+type snapshotEvent struct {
+	Event      string                 `json:"event"`
+	Time       time.Time              `json:"time"`
+	Network    string                 `json:"network,omitempty"`
+	Mode       string                 `json:"mode,omitempty"`
+	Provider   string                 `json:"provider,omitempty"`
+	Providers  []providerSnapshotInfo `json:"providers,omitempty"`
+	OutputPath string                 `json:"output_path,omitempty"`
+	Status     string                 `json:"status,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// jsonOut receives the JSON events. It is nil in text mode.
+var jsonOut io.Writer
+
+// progressOut receives progress bars and the output of download tools. It is
+// nil, meaning stdout, in text mode.
+var progressOut io.Writer
+
+// setupOutput validates --output. In JSON mode the stdout of cmd only
+// carries one JSON event per line; messages and progress bars go to its
+// stderr. The returned function switches back to text output.
+func setupOutput(cmd *cobra.Command, format string) (func(), error) {
+	switch format {
+	case "", outputText:
+		return func() {}, nil
+	case outputJSON:
+		jsonOut, progressOut = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		pterm.SetDefaultOutput(cmd.ErrOrStderr())
+		return func() {
+			jsonOut, progressOut = nil, nil
+			pterm.SetDefaultOutput(os.Stdout)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s (use text or json)", format)
+	}
+}
+
+// jsonOutput reports whether --output json is active
+func jsonOutput() bool {
+	return jsonOut != nil
+}
+
+// emit writes an event in JSON mode and does nothing otherwise
+func emit(e snapshotEvent) {
+	if jsonOut == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		pterm.Warning.Printf("Failed to encode %s event: %v\n", e.Event, err)
+		return
+	}
+	fmt.Fprintln(jsonOut, string(data))
+}
@@ -17,6 +17,10 @@ var providersCmd = &cobra.Command{
 }
 
 func runListProviders(cmd *cobra.Command, args []string) error {
+	if err := selectNetwork(); err != nil {
+		return err
+	}
+
 	// We'll fetch data for both pruned and archive
 	modes := []string{"pruned", "archive"}
 
@@ -1,17 +1,16 @@
 package snapshot
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"sync"
 
 	"github.com/pterm/pterm"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
 	"github.com/sSelmann/storycli/utils/config"
 	"github.com/spf13/cobra"
 )
 
 var (
-	// homeDirFlag is used to store the home directory path (passed via --home).
+	// homeDirFlag overrides the node home of the active profile (passed via --home).
 	homeDirFlag string
 
 	// pruningMode represents the selected pruning mode ("pruned" or "archive").
@@ -20,7 +19,34 @@ var (
 	// selectedProvider stores the chosen provider (Itrocket, Krews, Jnode).
 	selectedProvider string
 
-	endpoints = config.DefaultEndpoints()
+	// verifyFlag refuses to extract snapshots that don't match the published size or checksum.
+	verifyFlag bool
+	// resumableFlag downloads archives to disk before extracting them.
+	resumableFlag bool
+	// preserveOwnerFlag keeps the file owners stored in the archives.
+	preserveOwnerFlag bool
+	// keepOldFlag keeps the replaced data as data.old and chaindata.old.
+	keepOldFlag bool
+
+	// yesFlag disables all prompts; missing choices are an error.
+	yesFlag bool
+	// autoFlag picks the provider automatically ("freshest" or "smallest").
+	autoFlag string
+	// outputFlag selects text or json output.
+	outputFlag string
+
+	// refreshFlag ignores cached provider data.
+	refreshFlag bool
+
+	// networkFlag overrides the network of the active profile.
+	networkFlag string
+	// network is the network snapshots are listed and applied for, set by
+	// selectNetwork.
+	network config.Network
+
+	// endpoints are resolved on first use by resolveEndpoints.
+	endpoints     config.Endpoints
+	endpointsOnce sync.Once
 )
 
 // snapshotCmd represents the main snapshot command
@@ -40,21 +66,101 @@ func init() {
 	snapshotCmd.AddCommand(downloadCmd)
 	snapshotCmd.AddCommand(providersCmd)
 
+	// Flag to bypass the provider data cache
+	snapshotCmd.PersistentFlags().BoolVar(&refreshFlag, "refresh", false, "Ignore cached provider data and query every provider again")
+
+	// Flag to pick the network instead of the one in the profile
+	snapshotCmd.PersistentFlags().StringVar(&networkFlag, "network", "", "Network of the snapshots (default: network of the profile)")
+	snapshotCmd.RegisterFlagCompletionFunc("network", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return config.NetworkNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+
 	// Add flags to the download subcommand (e.g., home directory)
-	downloadCmd.Flags().StringVar(&homeDirFlag, "home", defaultHomeDir(), "Home directory for Story node")
+	downloadCmd.Flags().StringVar(&homeDirFlag, "home", "", "Node home directory holding story/ and geth/ (default: home_dir of the profile, ~/.story)")
 
 	// Flag to download snapshot directly to a specified path
 	downloadCmd.Flags().String("output-path", "", "Download snapshot directly to the specified path without setup")
+
+	// Flag to refuse snapshots that don't match the published size or checksum
+	downloadCmd.Flags().BoolVar(&verifyFlag, "verify", false, "Refuse to extract snapshots that don't match the provider's published size or checksum")
+
+	// Flag to keep the download-then-extract mode for resumable downloads
+	downloadCmd.Flags().BoolVar(&resumableFlag, "resumable", false, "Download archives to disk before extracting so interrupted downloads can be resumed (needs extra disk space)")
+
+	// Flag to keep the uid/gid stored in the snapshot archives
+	downloadCmd.Flags().BoolVar(&preserveOwnerFlag, "preserve-ownership", false, "Keep the file owners stored in the snapshot archives (requires root)")
+
+	// Flag to keep the previous data after applying a snapshot
+	downloadCmd.Flags().BoolVar(&keepOldFlag, "keep-old", false, "Keep the replaced data as data.old and chaindata.old after applying a snapshot")
+
+	// Flags for running without prompts, e.g. from cron or Ansible
+	downloadCmd.Flags().StringVar(&selectedProvider, "provider", "", "Snapshot provider to use (Itrocket, Jnode or Krews)")
+	downloadCmd.Flags().StringVar(&pruningMode, "mode", "", "Pruning mode of the snapshot (pruned or archive)")
+	downloadCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Don't prompt; fail if the provider or mode is not given")
+	downloadCmd.Flags().StringVar(&autoFlag, "auto", "", "Pick the provider automatically: freshest or smallest")
+	downloadCmd.Flags().StringVarP(&outputFlag, "output", "o", outputText, "Output format: text or json (json implies --yes)")
+
+	downloadCmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return provider.Names(), cobra.ShellCompDirectiveNoFileComp
+	})
+	downloadCmd.RegisterFlagCompletionFunc("mode", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"pruned", "archive"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	downloadCmd.RegisterFlagCompletionFunc("auto", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{autoFreshest, autoSmallest}, cobra.ShellCompDirectiveNoFileComp
+	})
+	downloadCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{outputText, outputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+	downloadCmd.MarkFlagsMutuallyExclusive("provider", "auto")
+}
+
+// resolveEndpoints resolves the provider endpoints the first time they are
+// needed, so commands that don't use them never touch the network
+func resolveEndpoints() config.Endpoints {
+	endpointsOnce.Do(func() {
+		var report config.EndpointReport
+		endpoints, report = config.ResolveEndpoints(network)
+		for _, err := range report.Failures {
+			pterm.Warning.Println(err.Error())
+		}
+		if len(report.Failures) > 0 {
+			pterm.Info.Printf("Using endpoints from: Itrocket pruned=%s, Itrocket archive=%s, Krews=%s, Jnode=%s\n", report.ItrocketPruned, report.ItrocketArchive, report.Krews, report.Jnode)
+		}
+	})
+	return endpoints
+}
+
+// selectNetwork looks up --network, or the network of the active profile,
+// in the network catalogue
+func selectNetwork() error {
+	name := networkFlag
+	if name == "" {
+		node, err := config.ActiveProfile()
+		if err != nil {
+			return err
+		}
+		name = node.Network
+	}
+	n, err := config.LookupNetwork(name)
+	if err != nil {
+		return err
+	}
+	network = n
+	return nil
 }
 
-// defaultHomeDir returns the default home directory path
-func defaultHomeDir() string {
-	home, err := os.UserHomeDir()
+// nodeProfile returns the active profile with --home and --network applied
+func nodeProfile() (config.Profile, error) {
+	node, err := config.ActiveProfile()
 	if err != nil {
-		pterm.Error.Println(fmt.Sprintf("Failed to get user home directory: %v", err))
-		os.Exit(1)
+		return node, err
+	}
+	node = node.WithNetwork(network)
+	if homeDirFlag != "" {
+		node.HomeDir = homeDirFlag
 	}
-	return filepath.Join(home)
+	return node, nil
 }
 
 // GetSnapshotCmd returns the main snapshot command so it can be added
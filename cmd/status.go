@@ -2,47 +2,222 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+)
+
+// Output formats of status
+const (
+	statusOutputText = "text"
+	statusOutputJSON = "json"
+)
+
+var (
+	statusOutput string
+	// statusReferenceRPC is the CometBFT RPC the block lag is measured
+	// against; the peers RPC of the network if empty
+	statusReferenceRPC string
+	statusNoReference  bool
+	statusServices     bool
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show the status of Story and Story-Geth services",
-	RunE:  runStatus,
+	Short: "Show the sync state, peers and heads of the Story and Story-Geth nodes",
+	Long: `Queries the CometBFT RPC and the geth JSON-RPC of the node and shows its latest
+height, whether it is catching up, its block lag against a reference RPC, its
+peers, its validator voting power and whether the consensus and execution heads
+agree. The RPC ports are read from rpc.laddr in config.toml and from the port
+flags of the geth service.`,
+	RunE: runStatus,
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", statusOutputText, "Output format: text or json")
+	statusCmd.Flags().StringVar(&statusReferenceRPC, "reference-rpc", "", "CometBFT RPC to measure the block lag against (default: the public RPC of the network)")
+	statusCmd.Flags().BoolVar(&statusNoReference, "no-reference", false, "Don't compare the height with a reference RPC")
+	statusCmd.Flags().BoolVar(&statusServices, "services", false, "Show the service manager status of the services instead")
+	statusCmd.MarkFlagsMutuallyExclusive("reference-rpc", "no-reference")
+	statusCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{statusOutputText, statusOutputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
-	pterm.Info.Printf("Checking service statuses...")
+	if statusOutput != statusOutputText && statusOutput != statusOutputJSON {
+		return fmt.Errorf("unsupported output format: %s (use text or json)", statusOutput)
+	}
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+
+	if statusServices {
+		pterm.Info.Printf("Checking service statuses...")
+		for _, service := range node.Services() {
+			if err := performServiceAction(service, displayServiceStatus); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	opts := referenceOptions(node, statusReferenceRPC, statusNoReference)
+	status := nodestatus.Collect(node, nodestatus.Discover(node), opts)
 
-	services := []string{"story", "story-geth"}
-	for _, service := range services {
-		if err := performServiceAction(service, displayServiceStatus); err != nil {
+	if statusOutput == statusOutputJSON {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
 			return err
 		}
+		fmt.Println(string(data))
+		return nil
 	}
+	return printStatus(status)
+}
 
-	return nil
+// referenceOptions returns the status options comparing with referenceRPC,
+// or with the public RPC of the network if it is empty
+func referenceOptions(node config.Profile, referenceRPC string, noReference bool) nodestatus.Options {
+	opts := nodestatus.Options{ReferenceRPC: referenceRPC}
+	if opts.ReferenceRPC == "" && !noReference {
+		if network, err := config.LookupNetwork(node.Network); err == nil {
+			opts.ReferenceRPC = network.PeersRPC
+		}
+	}
+	return opts
 }
 
 func displayServiceStatus(serviceName string) error {
-	cmd := exec.Command("systemctl", "status", serviceName, "--no-pager", "-n", "3")
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	manager, err := serviceManager()
+	if err != nil {
+		return err
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := manager.Status(serviceName, os.Stdout); err != nil {
 		pterm.Warning.Printf(fmt.Sprintf("Failed to get status for '%s' service.", serviceName))
 		return err
 	}
 
 	return nil
 }
+
+// printStatus renders status as tables
+func printStatus(s nodestatus.Status) error {
+	pterm.DefaultSection.Printf("Node %s (%s)", s.Profile, s.Network)
+	services := pterm.TableData{{"Service", "State"}}
+	for _, svc := range s.Services {
+		state := "inactive"
+		switch {
+		case svc.Error != "":
+			state = "unknown: " + svc.Error
+		case svc.Active:
+			state = "active"
+		}
+		services = append(services, []string{svc.Name, state})
+	}
+	if err := pterm.DefaultTable.WithHasHeader(true).WithData(services).Render(); err != nil {
+		return err
+	}
+
+	c := s.Consensus
+	pterm.DefaultSection.Println("Consensus client (CometBFT)")
+	consensus := pterm.TableData{{"RPC", fmt.Sprintf("%s (%s)", c.Endpoint, c.Source)}}
+	if !c.Up {
+		consensus = append(consensus, []string{"State", "unreachable: " + c.Error})
+	} else {
+		consensus = append(consensus,
+			[]string{"Moniker", c.Moniker},
+			[]string{"Version", c.Version},
+			[]string{"Latest height", fmt.Sprintf("%d (%s)", c.LatestHeight, age(s.Time, c.LatestBlockTime))},
+			[]string{"Catching up", yesNo(c.CatchingUp)},
+			[]string{"Block lag", blockLag(s)},
+			[]string{"Peers", fmt.Sprintf("%d (%d inbound, %d outbound)", c.Peers, c.InboundPeers, c.OutboundPeers)},
+			[]string{"Voting power", votingPower(c)},
+		)
+		if c.Error != "" {
+			consensus = append(consensus, []string{"Error", c.Error})
+		}
+	}
+	if err := pterm.DefaultTable.WithData(consensus).Render(); err != nil {
+		return err
+	}
+
+	e := s.Execution
+	pterm.DefaultSection.Println("Execution client (geth)")
+	execution := pterm.TableData{{"RPC", fmt.Sprintf("%s (%s)", e.Endpoint, e.Source)}}
+	if !e.Up {
+		execution = append(execution, []string{"State", "unreachable: " + e.Error})
+	} else {
+		syncing := "no"
+		if e.Syncing {
+			syncing = fmt.Sprintf("yes, block %d of %d", e.CurrentBlock, e.HighestBlock)
+		}
+		execution = append(execution,
+			[]string{"Chain ID", fmt.Sprint(e.ChainID)},
+			[]string{"Latest block", fmt.Sprintf("%d (%s)", e.BlockNumber, age(s.Time, e.BlockTime))},
+			[]string{"Syncing", syncing},
+			[]string{"Peers", fmt.Sprint(e.Peers)},
+		)
+		if e.Error != "" {
+			execution = append(execution, []string{"Error", e.Error})
+		}
+	}
+	execution = append(execution, []string{"Heads agree", headsAgree(s)})
+	return pterm.DefaultTable.WithData(execution).Render()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// age describes how long before now t was
+func age(now, t time.Time) string {
+	if t.IsZero() {
+		return "time unknown"
+	}
+	return now.Sub(t).Round(time.Second).String() + " ago"
+}
+
+func blockLag(s nodestatus.Status) string {
+	switch {
+	case s.Reference == nil:
+		return "not checked"
+	case s.BlockLag == nil:
+		return fmt.Sprintf("unknown, %s unreachable: %s", s.Reference.Endpoint, s.Reference.Error)
+	case *s.BlockLag <= 0:
+		return fmt.Sprintf("in sync with %s (height %d)", s.Reference.Endpoint, s.Reference.LatestHeight)
+	}
+	return fmt.Sprintf("%d blocks behind %s (height %d)", *s.BlockLag, s.Reference.Endpoint, s.Reference.LatestHeight)
+}
+
+func votingPower(c nodestatus.Consensus) string {
+	if c.VotingPower == 0 {
+		return "0 (not in the active validator set)"
+	}
+	return fmt.Sprintf("%d (validator %s)", c.VotingPower, strings.ToUpper(c.ValidatorAddress))
+}
+
+func headsAgree(s nodestatus.Status) string {
+	if s.HeadsAgree == nil {
+		return "unknown"
+	}
+	drift := time.Duration(*s.HeadDrift * float64(time.Second)).Round(time.Second)
+	if *s.HeadsAgree {
+		return fmt.Sprintf("yes (consensus head %s apart from execution head)", drift)
+	}
+	return fmt.Sprintf("no (consensus head %s apart from execution head, more than %s)", drift, nodestatus.HeadDriftTolerance)
+}
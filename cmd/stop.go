@@ -3,10 +3,11 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
 )
 
 var stopCmd = &cobra.Command{
@@ -22,7 +23,11 @@ func init() {
 func runStop(cmd *cobra.Command, args []string) error {
 	pterm.Info.Printf("Stopping services...")
 
-	services := []string{"story", "story-geth"}
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	services := node.Services()
 	for _, service := range services {
 		if err := performServiceAction(service, stopService); err != nil {
 			return err
@@ -34,9 +39,12 @@ func runStop(cmd *cobra.Command, args []string) error {
 }
 
 func stopService(serviceName string) error {
-	cmd := exec.Command("systemctl", "stop", serviceName)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		printError(fmt.Sprintf("Failed to stop '%s' service: %v\nOutput: %s", serviceName, err, string(output)))
+	manager, err := serviceManager()
+	if err != nil {
+		return err
+	}
+	if err := manager.Stop(serviceName); err != nil {
+		printError(fmt.Sprintf("Failed to stop '%s' service: %v", serviceName, err))
 		return err
 	}
 	return nil
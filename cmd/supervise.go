@@ -0,0 +1,29 @@
+// cmd/supervise.go
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/service"
+)
+
+// superviseDir is the directory of the process service manager
+var superviseDir string
+
+// superviseCmd runs a service for the process service manager, which starts
+// it detached from the terminal
+var superviseCmd = &cobra.Command{
+	Use:    "supervise <service>",
+	Short:  "Run a service of the process service manager, restarting it when it fails",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return service.Process{Dir: superviseDir}.Supervise(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(superviseCmd)
+	superviseCmd.Flags().StringVar(&superviseDir, "dir", "", "Directory holding the unit, pidfile and log of the service")
+	superviseCmd.MarkFlagRequired("dir")
+}
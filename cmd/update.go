@@ -1,77 +1,230 @@
-// cmd/update.go
 package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
-	"github.com/sSelmann/storycli/utils/bash"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/cosmovisor"
+	"github.com/sSelmann/storycli/utils/install"
+	"github.com/sSelmann/storycli/utils/service"
+)
+
+var (
+	// updateNetwork overrides the network of the active profile
+	updateNetwork string
+	// updateStory and updateGeth are the versions to install, or "latest"
+	updateStory string
+	updateGeth  string
 )
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	updateCmd.AddCommand(updateRollbackCmd)
+
+	updateCmd.Flags().StringVar(&updateStory, "story", "", "Story version to install, e.g. v1.1.0, or \"latest\"")
+	updateCmd.Flags().StringVar(&updateGeth, "geth", "", "Geth version to install, e.g. v1.0.1, or \"latest\"")
+	updateCmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Install release binaries even if the release publishes no checksum")
+	updateCmd.Flags().StringVar(&installMethod, "install-method", string(install.MethodRelease), "Download release binaries (release) or build them from source (source)")
+	updateCmd.RegisterFlagCompletionFunc("install-method", completeInstallMethods)
+	updateCmd.Flags().StringVar(&updateNetwork, "network", "", "Network the node runs (default: network of the profile)")
+	updateCmd.RegisterFlagCompletionFunc("network", completeNetworks)
 }
 
-// setupCmd represents the setup command
+// updateCmd represents the update command
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update story and geth binaries",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Update geth binary
-		pterm.Info.Printf("Updating geth...")
-		err := bash.RunCommand("cd $HOME && wget -O geth https://github.com/piplabs/story-geth/releases/latest/download/geth-linux-amd64")
-		if err != nil {
-			printError("Failed to download geth")
+	Long: `Install the given story and geth versions next to the ones already
+installed and switch to them. Each version is kept in <home>/versions and the
+binaries in the bin dir are symlinks into it, so 'scli update rollback'
+returns to the previous versions.`,
+	Example: `  scli update --story v1.1.0 --geth v1.0.1
+  scli update --geth latest
+  scli update rollback`,
+	RunE: runUpdate,
+}
+
+var updateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Switch story and geth back to the versions installed before the last update",
+	RunE:  runUpdateRollback,
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	if updateStory == "" && updateGeth == "" {
+		return fmt.Errorf("nothing to update: use --story and/or --geth")
+	}
+
+	node, network, err := selectNodeNetwork(updateNetwork)
+	if err != nil {
+		return err
+	}
+	if _, err := install.ParseMethod(installMethod); err != nil {
+		return err
+	}
+	if updateStory != "" && cosmovisor.ForProfile(node).Installed() {
+		return fmt.Errorf("story runs under Cosmovisor on this node; use 'scli upgrade schedule' to update it")
+	}
+
+	store := install.NewStore(node)
+	record, err := store.LoadRecord()
+	if err != nil {
+		return err
+	}
+	// Resolve the versions first, so binaries that are already current are
+	// not fetched again
+	requested := []struct {
+		source  install.Source
+		version string
+	}{
+		{install.GethSource, updateGeth},
+		{install.StorySource, updateStory},
+	}
+	versions := map[string]string{}
+	for _, r := range requested {
+		source, version := r.source, r.version
+		if version == "" {
+			continue
 		}
-		err = bash.RunCommand("chmod +x $HOME/geth")
-		if err != nil {
-			printError("Failed to make geth executable")
+		if version == "latest" {
+			version, err = latestVersion(source.Binary, network)
+			if err != nil {
+				return fmt.Errorf("failed to get %s version: %v", source.Binary, err)
+			}
 		}
-		err = bash.RunCommand("sudo mv $HOME/geth $(which geth)")
-		if err != nil {
-			printError("Failed to move geth binary")
+		if installed := record.Binaries[source.Binary]; installed != nil && installed.Current == version && store.Has(source.Binary, version) {
+			pterm.Info.Printf("%s %s is already installed.\n", source.Binary, version)
+			continue
 		}
-		err = bash.RunCommand("sudo systemctl restart story-geth")
-		if err != nil {
-			printError("Failed to restart story-geth")
+		versions[source.Binary] = version
+	}
+	if len(versions) == 0 {
+		pterm.Success.Println("Everything is up to date.")
+		return nil
+	}
+
+	workDir, err := os.MkdirTemp("", "storycli-update-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	// Fetch the new versions while the node keeps running
+	for _, r := range requested {
+		version, ok := versions[r.source.Binary]
+		if !ok {
+			continue
 		}
-		err = bash.RunCommand("sudo systemctl restart story && sudo journalctl -u story -f")
-		if err != nil {
-			printError("Failed to restart story")
+		if err := addBinary(store, r.source, version, workDir); err != nil {
+			return err
 		}
+	}
 
-		// Update story binary
-		pterm.Info.Printf("Updating story...")
-		err = bash.RunCommand("cd $HOME && rm -rf story")
-		if err != nil {
-			printError("Failed to remove old story")
-		}
-		err = bash.RunCommand("git clone https://github.com/piplabs/story")
-		if err != nil {
-			printError("Failed to clone story repository")
+	err = switchBinaries(node, versions, func(binary string) (string, error) {
+		return versions[binary], store.Activate(binary, versions[binary])
+	})
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Println("Update completed successfully. Use 'scli update rollback' to return to the previous versions.")
+	return nil
+}
+
+func runUpdateRollback(cmd *cobra.Command, args []string) error {
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	store := install.NewStore(node)
+	record, err := store.LoadRecord()
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]string{}
+	for _, binary := range []string{install.Story, install.Geth} {
+		if installed := record.Binaries[binary]; installed != nil && installed.Previous != "" {
+			previous[binary] = installed.Previous
 		}
+	}
+	if len(previous) == 0 {
+		return fmt.Errorf("no previous versions recorded in %s", store.Root)
+	}
+	if _, ok := previous[install.Story]; ok && cosmovisor.ForProfile(node).Installed() {
+		return fmt.Errorf("story runs under Cosmovisor on this node; roll it back through Cosmovisor")
+	}
 
-		// Get the latest release tag
-		tag, err := getLatestReleaseTag("piplabs/story")
-		if err != nil {
-			printError("Failed to get latest version")
+	err = switchBinaries(node, previous, store.Rollback)
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Println("Rollback completed successfully.")
+	return nil
+}
+
+// switchBinaries stops the services of the binaries being changed, calls
+// activate for each, and starts them again. Story is stopped before and
+// started after geth, as it needs geth's engine API.
+func switchBinaries(node config.Profile, changed map[string]string, activate func(binary string) (string, error)) error {
+	_, gethChanged := changed[install.Geth]
+	manager, err := service.ForProfile(node)
+	if err != nil {
+		return err
+	}
+
+	pterm.Info.Println("Stopping " + node.StoryService + "...")
+	if err := manager.Stop(node.StoryService); err != nil {
+		return fmt.Errorf("failed to stop %s: %v", node.StoryService, err)
+	}
+	if gethChanged {
+		pterm.Info.Println("Stopping " + node.GethService + "...")
+		if err := manager.Stop(node.GethService); err != nil {
+			return fmt.Errorf("failed to stop %s: %v", node.GethService, err)
 		}
+	}
 
-		err = bash.RunCommand(fmt.Sprintf("cd story && git checkout %s", tag))
-		if err != nil {
-			printError("Failed to checkout story version")
+	// A binary that fails to switch keeps its old version; the services are
+	// started again either way
+	var switchErr error
+	for _, binary := range []string{install.Geth, install.Story} {
+		if _, ok := changed[binary]; !ok {
+			continue
 		}
-		err = bash.RunCommand("cd $HOME/story && go build -o story ./client")
+		version, err := activate(binary)
 		if err != nil {
-			printError("Failed to build story")
+			printError(fmt.Sprintf("Failed to switch %s: %v", binary, err))
+			if switchErr == nil {
+				switchErr = fmt.Errorf("failed to switch %s: %v", binary, err)
+			}
+			continue
 		}
-		err = bash.RunCommand("mv $HOME/story/story $HOME/go/bin/")
-		if err != nil {
-			printError("Failed to move story binary")
+		pterm.Info.Printf("%s is now %s\n", binary, version)
+	}
+
+	if gethChanged {
+		pterm.Info.Println("Starting " + node.GethService + "...")
+		if err := manager.Start(node.GethService); err != nil {
+			return fmt.Errorf("failed to start %s: %v", node.GethService, err)
 		}
+	}
+	pterm.Info.Println("Starting " + node.StoryService + "...")
+	if err := manager.Start(node.StoryService); err != nil {
+		return fmt.Errorf("failed to start %s: %v", node.StoryService, err)
+	}
+	return switchErr
+}
 
-		fmt.Println("Update completed successfully.")
-	},
+// latestVersion returns the version "latest" stands for: the recommended
+// geth version of network, or the latest Story release
+func latestVersion(binary string, network config.Network) (string, error) {
+	if binary == install.Geth {
+		return getLatestGethVersion(network)
+	}
+	return getLatestReleaseTag(install.StoryRepo)
 }
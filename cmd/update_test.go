@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sSelmann/storycli/utils/install"
+)
+
+func TestRunUpdate(t *testing.T) {
+	buildGeth := []string{
+		"mkdir -p ~/.story/versions/geth/v1.0.2",
+		"rm -rf ~/.story/versions/geth/v1.0.2/geth.tmp",
+		"rm -rf ~/storycli-update-*/story-geth",
+		"cd ~/storycli-update-* && git clone --quiet --depth 1 --branch v1.0.2 https://github.com/piplabs/story-geth ~/storycli-update-*/story-geth",
+		"cd ~/storycli-update-*/story-geth && go build -o ~/.story/versions/geth/v1.0.2/geth.tmp ./cmd/geth",
+		"store ~/.story/versions/geth/v1.0.2/geth.tmp as ~/.story/versions/geth/v1.0.2/geth and record its sha256",
+	}
+	buildStory := []string{
+		"mkdir -p ~/.story/versions/story/v1.2.0",
+		"rm -rf ~/.story/versions/story/v1.2.0/story.tmp",
+		"rm -rf ~/storycli-update-*/story",
+		"cd ~/storycli-update-* && git clone --quiet --depth 1 --branch v1.2.0 https://github.com/piplabs/story ~/storycli-update-*/story",
+		"cd ~/storycli-update-*/story && go build -o ~/.story/versions/story/v1.2.0/story.tmp ./client",
+		"store ~/.story/versions/story/v1.2.0/story.tmp as ~/.story/versions/story/v1.2.0/story and record its sha256",
+	}
+
+	tests := []struct {
+		name    string
+		story   string
+		geth    string
+		network string
+		// installed is the geth version already in the store and current
+		installed string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name: "geth only",
+			geth: "v1.0.2",
+			want: concat(buildGeth, []string{
+				"sudo systemctl stop story",
+				"sudo systemctl stop story-geth",
+				"link ~/go/bin/geth to ~/.story/versions/geth/v1.0.2/geth",
+				"sudo systemctl start story-geth",
+				"sudo systemctl start story",
+			}),
+		},
+		{
+			name:    "latest story and geth",
+			story:   "latest",
+			geth:    "latest",
+			network: "aeneid",
+			want: concat(buildGeth, buildStory, []string{
+				"sudo systemctl stop story",
+				"sudo systemctl stop story-geth",
+				"link ~/go/bin/geth to ~/.story/versions/geth/v1.0.2/geth",
+				"link ~/go/bin/story to ~/.story/versions/story/v1.2.0/story",
+				"sudo systemctl start story-geth",
+				"sudo systemctl start story",
+			}),
+		},
+		{
+			name:      "already installed",
+			geth:      "v1.0.2",
+			installed: "v1.0.2",
+		},
+		{
+			name:      "latest story with geth current",
+			story:     "latest",
+			geth:      "latest",
+			network:   "aeneid",
+			installed: "v1.0.2",
+			want: concat(buildStory, []string{
+				"sudo systemctl stop story",
+				"link ~/go/bin/story to ~/.story/versions/story/v1.2.0/story",
+				"sudo systemctl start story",
+			}),
+		},
+		{
+			name:    "nothing to update",
+			wantErr: "nothing to update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, _, commands := offlineNode(t)
+			setFlag(t, &installMethod, "source")
+			setFlag(t, &updateStory, tt.story)
+			setFlag(t, &updateGeth, tt.geth)
+			setFlag(t, &updateNetwork, tt.network)
+			if tt.installed != "" {
+				installGeth(t, install.NewStore(node), tt.installed)
+			}
+
+			err := runUpdate(updateCmd, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			checkCommands(t, commands(), tt.want)
+		})
+	}
+}
+
+// installGeth puts version of geth into store as the current version
+func installGeth(t *testing.T, store install.Store, version string) {
+	t.Helper()
+	path := store.Path(install.Geth, version)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("geth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	record, err := store.LoadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Binaries[install.Geth] = &install.Installed{Current: version}
+	if err := store.SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+}
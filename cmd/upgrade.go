@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/cosmovisor"
+	"github.com/sSelmann/storycli/utils/install"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+)
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Prepare chain upgrades for nodes running under Cosmovisor",
+	Long: `Prepare chain upgrades ahead of time for nodes set up with
+'scli setup node --cosmovisor'. Cosmovisor switches to the new binary at the
+upgrade height without anyone having to be online.`,
+}
+
+var upgradeScheduleCmd = &cobra.Command{
+	Use:   "schedule <name> <version>",
+	Short: "Install story <version> into the Cosmovisor folder of upgrade <name>",
+	Long: `Install story at the given release tag into the version store, the same way
+'scli update' does, and put it into cosmovisor/upgrades/<name>/bin. With
+--height, Cosmovisor switches to it at that block height; otherwise it switches
+when the chain halts for the upgrade named <name>.`,
+	Example: `  scli upgrade schedule v1.1.0 v1.1.0 --height 1398000
+  scli upgrade schedule v1.1.0 v1.1.0 --binary ./story`,
+	Args: cobra.ExactArgs(2),
+	RunE: runUpgradeSchedule,
+}
+
+var upgradeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the upgrades prepared for Cosmovisor",
+	RunE:  runUpgradeList,
+}
+
+var (
+	upgradeHeight int64
+	upgradeBinary string
+	upgradeForce  bool
+)
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.AddCommand(upgradeScheduleCmd)
+	upgradeCmd.AddCommand(upgradeListCmd)
+
+	upgradeScheduleCmd.Flags().Int64Var(&upgradeHeight, "height", 0, "Block height to switch to the new binary at")
+	upgradeScheduleCmd.Flags().StringVar(&upgradeBinary, "binary", "", "Use this story binary instead of building it")
+	upgradeScheduleCmd.Flags().BoolVar(&upgradeForce, "force", false, "Replace an upgrade that is already prepared")
+	upgradeScheduleCmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Install the release binary even if the release publishes no checksum")
+	upgradeScheduleCmd.Flags().StringVar(&installMethod, "install-method", string(install.MethodRelease), "Download the release binary (release) or build it from source (source)")
+	upgradeScheduleCmd.RegisterFlagCompletionFunc("install-method", completeInstallMethods)
+	upgradeScheduleCmd.MarkFlagsMutuallyExclusive("binary", "install-method")
+	upgradeScheduleCmd.MarkFlagsMutuallyExclusive("binary", "skip-verify")
+}
+
+func runUpgradeSchedule(cmd *cobra.Command, args []string) error {
+	name, version := args[0], args[1]
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	layout := cosmovisor.ForProfile(node)
+
+	if !layout.Installed() {
+		return fmt.Errorf("no Cosmovisor setup found in %s (set the node up with 'scli setup node --cosmovisor')", layout.Root())
+	}
+	if _, err := os.Stat(layout.Cosmovisor); err != nil {
+		return fmt.Errorf("cosmovisor not found at %s: %v", layout.Cosmovisor, err)
+	}
+	if upgradeBinary == "" {
+		if _, err := install.ParseMethod(installMethod); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(layout.UpgradeBin(name)); err == nil && !upgradeForce {
+		return fmt.Errorf("upgrade %s is already prepared in %s (use --force to replace it)", name, filepath.Dir(layout.UpgradeBin(name)))
+	}
+
+	if upgradeHeight > 0 {
+		current, err := nodestatus.LatestHeight(nodestatus.Discover(node).CometRPC)
+		if err != nil {
+			pterm.Warning.Printf("Could not check the current block height: %v\n", err)
+		} else if upgradeHeight <= current {
+			return fmt.Errorf("upgrade height %d is not ahead of the current height %d", upgradeHeight, current)
+		} else {
+			pterm.Info.Printf("Current height is %d, %d blocks before the upgrade.\n", current, upgradeHeight-current)
+		}
+	}
+
+	binary := upgradeBinary
+	if binary == "" {
+		workDir, err := os.MkdirTemp("", "storycli-upgrade-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(workDir)
+
+		// Resolved, verified and kept like an update, so the upgrade binary
+		// can be checked with 'scli verify binaries' later
+		store := install.NewStore(node)
+		if err := addBinary(store, install.StorySource, version, workDir); err != nil {
+			return err
+		}
+		binary = store.Path(install.Story, version)
+	}
+
+	out, err := install.BinaryVersion(binary)
+	if err != nil {
+		return err
+	}
+	pterm.Info.Printf("Binary reports:\n%s\n", out)
+
+	if err := layout.AddUpgrade(name, binary, upgradeHeight, upgradeForce); err != nil {
+		return err
+	}
+
+	if upgradeHeight > 0 {
+		pterm.Success.Printf("Upgrade %s (story %s) scheduled at height %d.\n", name, version, upgradeHeight)
+	} else {
+		pterm.Success.Printf("Upgrade %s (story %s) prepared; Cosmovisor switches when the chain halts for it.\n", name, version)
+	}
+	return nil
+}
+
+func runUpgradeList(cmd *cobra.Command, args []string) error {
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	layout := cosmovisor.ForProfile(node)
+	if !layout.Installed() {
+		return fmt.Errorf("no Cosmovisor setup found in %s", layout.Root())
+	}
+
+	if current, err := layout.Current(); err == nil {
+		pterm.Info.Printf("Current binary: %s\n", current)
+	}
+	pending, err := layout.PendingUpgrade()
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		pterm.Info.Printf("Pending upgrade: %s at height %d\n", pending.Name, pending.Height)
+	}
+
+	upgrades, err := layout.Upgrades()
+	if err != nil {
+		return err
+	}
+	if len(upgrades) == 0 {
+		pterm.Info.Println("No upgrades prepared.")
+		return nil
+	}
+	tableData := pterm.TableData{{"Upgrade", "Binary"}}
+	for _, name := range upgrades {
+		tableData = append(tableData, []string{name, layout.UpgradeBin(name)})
+	}
+	return pterm.DefaultTable.WithHasHeader(true).WithData(tableData).Render()
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sSelmann/storycli/utils/cosmovisor"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/install"
+)
+
+func TestRunUpgradeSchedule(t *testing.T) {
+	tests := []struct {
+		name string
+		// stored puts the version into the store before the upgrade
+		stored bool
+		want   []string
+	}{
+		{
+			name: "builds into the store",
+			want: []string{
+				"mkdir -p ~/.story/versions/story/v1.3.0",
+				"rm -rf ~/.story/versions/story/v1.3.0/story.tmp",
+				"rm -rf ~/storycli-upgrade-*/story",
+				"cd ~/storycli-upgrade-* && git clone --quiet --depth 1 --branch v1.3.0 https://github.com/piplabs/story ~/storycli-upgrade-*/story",
+				"cd ~/storycli-upgrade-*/story && go build -o ~/.story/versions/story/v1.3.0/story.tmp ./client",
+				"store ~/.story/versions/story/v1.3.0/story.tmp as ~/.story/versions/story/v1.3.0/story and record its sha256",
+				"~/.story/versions/story/v1.3.0/story version",
+				"DAEMON_NAME=story DAEMON_HOME=~/.story/story ~/go/bin/cosmovisor add-upgrade v1.3.0 ~/.story/versions/story/v1.3.0/story",
+			},
+		},
+		{
+			name:   "uses the stored version",
+			stored: true,
+			want: []string{
+				"check ~/.story/versions/story/v1.3.0/story against its recorded sha256",
+				"~/.story/versions/story/v1.3.0/story version",
+				"DAEMON_NAME=story DAEMON_HOME=~/.story/story ~/go/bin/cosmovisor add-upgrade v1.3.0 ~/.story/versions/story/v1.3.0/story",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, rec, commands := offlineNode(t)
+			setFlag(t, &installMethod, "source")
+			layout := cosmovisor.ForProfile(node)
+			for _, path := range []string{layout.GenesisBin(), layout.Cosmovisor} {
+				writeExecutable(t, path)
+			}
+			store := install.NewStore(node)
+			if tt.stored {
+				writeExecutable(t, store.Path(install.Story, "v1.3.0"))
+			}
+			rec.Respond(executor.Cmd(store.Path(install.Story, "v1.3.0"), "version").String(), []byte("1.3.0-stable"), nil)
+
+			if err := runUpgradeSchedule(upgradeScheduleCmd, []string{"v1.3.0", "v1.3.0"}); err != nil {
+				t.Fatal(err)
+			}
+			checkCommands(t, commands(), tt.want)
+		})
+	}
+}
+
+// writeExecutable creates an empty executable at path
+func writeExecutable(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
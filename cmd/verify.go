@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/install"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the integrity of the node installation",
+}
+
+var verifyBinariesCmd = &cobra.Command{
+	Use:   "binaries",
+	Short: "Check the installed story and geth binaries against their recorded checksums",
+	Long: `Compare every story and geth binary in <home>/versions with the SHA256
+recorded when scli installed it, and check that the binaries in the bin dir
+still link to the current versions. Exits with an error if a binary was
+changed, removed or replaced outside scli.`,
+	RunE: runVerifyBinaries,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.AddCommand(verifyBinariesCmd)
+}
+
+func runVerifyBinaries(cmd *cobra.Command, args []string) error {
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	store := install.NewStore(node)
+	checks, err := store.Verify()
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("no binaries recorded in %s (install them with 'scli setup node' or 'scli update')", store.Root)
+	}
+
+	failed := 0
+	tableData := pterm.TableData{{"Binary", "Version", "Path", "Status"}}
+	for _, c := range checks {
+		version := c.Version
+		if c.Active {
+			version += " (current)"
+		}
+		status := pterm.Green("ok")
+		if c.Problem != "" {
+			status = pterm.Red(c.Problem)
+			failed++
+		}
+		tableData = append(tableData, []string{c.Binary, version, c.Path, status})
+	}
+	if err := pterm.DefaultTable.WithHasHeader(true).WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	pterm.Success.Println("All binaries match their recorded checksums.")
+	return nil
+}
@@ -0,0 +1,435 @@
+// cmd/watch.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+)
+
+const (
+	// watchWindow is the time the block rates and the peer churn are
+	// measured over
+	watchWindow = 2 * time.Minute
+	// watchPeerHistory is the number of peer counts shown
+	watchPeerHistory = 40
+	// watchDiskInterval is how often the node home is measured; walking a
+	// large geth database takes a while
+	watchDiskInterval = 30 * time.Second
+)
+
+var (
+	watchInterval     time.Duration
+	watchReferenceRPC string
+	watchNoReference  bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow sync progress, peers and resource use of the node live",
+	Long: `Polls the CometBFT RPC and the geth JSON-RPC of the node and shows, refreshed in
+place, the blocks per second, the ETA to the tip of the network, the history of
+the peer counts, the growth of the node home directory and the CPU and memory
+use of the services. Press Ctrl-C to quit.`,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "i", 2*time.Second, "Time between updates")
+	watchCmd.Flags().StringVar(&watchReferenceRPC, "reference-rpc", "", "CometBFT RPC giving the tip of the network (default: the public RPC of the network)")
+	watchCmd.Flags().BoolVar(&watchNoReference, "no-reference", false, "Don't compare the height with a reference RPC (no ETA)")
+	watchCmd.MarkFlagsMutuallyExclusive("reference-rpc", "no-reference")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval < 500*time.Millisecond {
+		return fmt.Errorf("--interval must be at least 500ms")
+	}
+	node, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	w := &watcher{
+		node:      node,
+		endpoints: nodestatus.Discover(node),
+		opts:      referenceOptions(node, watchReferenceRPC, watchNoReference),
+		cpu:       map[string]cpuSample{},
+	}
+
+	area, err := pterm.DefaultArea.Start()
+	if err != nil {
+		return err
+	}
+	defer area.Stop()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		w.poll(time.Now())
+		area.Update(w.render())
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type heightSample struct {
+	at     time.Time
+	height int64
+}
+
+type cpuSample struct {
+	at      time.Time
+	seconds float64
+}
+
+type sizeSample struct {
+	at   time.Time
+	size uint64
+}
+
+// watcher keeps the history behind the watch view
+type watcher struct {
+	node      config.Profile
+	endpoints nodestatus.Endpoints
+	opts      nodestatus.Options
+
+	status nodestatus.Status
+	// heights and tips are the local and reference heights within
+	// watchWindow
+	heights []heightSample
+	tips    []heightSample
+	// consensusPeers and executionPeers are the last peer counts
+	consensusPeers []int
+	executionPeers []int
+	// peers are the consensus peers of the last poll; joins and leaves
+	// the times peers came and went within watchWindow
+	peers         map[string]bool
+	joins, leaves []time.Time
+
+	procs      []nodestatus.ProcessStats
+	procsErr   error
+	cpu        map[string]cpuSample
+	cpuPercent map[string]float64
+
+	// The disk fields are written by the measuring goroutine
+	diskMu     sync.Mutex
+	measuring  bool
+	measuredAt time.Time
+	firstSize  sizeSample
+	lastSize   sizeSample
+	sizeErr    error
+}
+
+// poll collects a new sample
+func (w *watcher) poll(now time.Time) {
+	st := nodestatus.Collect(w.node, w.endpoints, w.opts)
+	w.status = st
+
+	consensusPeers := 0
+	if st.Consensus.Up {
+		w.heights = appendSample(w.heights, heightSample{now, st.Consensus.LatestHeight}, now)
+		consensusPeers = st.Consensus.Peers
+		w.trackChurn(st.Consensus.PeerIDs, now)
+	}
+	if st.Reference != nil && st.Reference.Up {
+		w.tips = appendSample(w.tips, heightSample{now, st.Reference.LatestHeight}, now)
+	}
+	executionPeers := 0
+	if st.Execution.Up {
+		executionPeers = int(st.Execution.Peers)
+	}
+	w.consensusPeers = appendHistory(w.consensusPeers, consensusPeers)
+	w.executionPeers = appendHistory(w.executionPeers, executionPeers)
+
+	w.procs, w.procsErr = nodestatus.ServiceProcesses(w.node)
+	percent := map[string]float64{}
+	for _, p := range w.procs {
+		if prev, ok := w.cpu[p.Service]; ok && now.After(prev.at) {
+			percent[p.Service] = (p.CPUSeconds - prev.seconds) / now.Sub(prev.at).Seconds() * 100
+		}
+		w.cpu[p.Service] = cpuSample{now, p.CPUSeconds}
+	}
+	w.cpuPercent = percent
+
+	w.diskMu.Lock()
+	if !w.measuring && now.Sub(w.measuredAt) >= watchDiskInterval {
+		w.measuring = true
+		go w.measureDisk()
+	}
+	w.diskMu.Unlock()
+}
+
+// trackChurn records the peers that joined and left since the last poll
+func (w *watcher) trackChurn(ids []string, now time.Time) {
+	current := map[string]bool{}
+	for _, id := range ids {
+		current[id] = true
+	}
+	if w.peers != nil {
+		for id := range current {
+			if !w.peers[id] {
+				w.joins = append(w.joins, now)
+			}
+		}
+		for id := range w.peers {
+			if !current[id] {
+				w.leaves = append(w.leaves, now)
+			}
+		}
+	}
+	w.peers = current
+	w.joins = trimTimes(w.joins, now)
+	w.leaves = trimTimes(w.leaves, now)
+}
+
+func (w *watcher) measureDisk() {
+	size, err := nodestatus.DirSize(w.node.HomeDir)
+	now := time.Now()
+
+	w.diskMu.Lock()
+	defer w.diskMu.Unlock()
+	w.measuring = false
+	w.measuredAt = now
+	w.sizeErr = err
+	if err != nil {
+		return
+	}
+	w.lastSize = sizeSample{now, size}
+	if w.firstSize.at.IsZero() {
+		w.firstSize = w.lastSize
+	}
+}
+
+// render returns the watch view
+func (w *watcher) render() string {
+	st := w.status
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", pterm.Bold.Sprintf("scli watch: %s (%s), updated %s every %s, Ctrl-C to quit",
+		st.Profile, st.Network, st.Time.Local().Format("15:04:05"), watchInterval))
+
+	b.WriteString(pterm.DefaultSection.Sprint("Sync"))
+	b.WriteString(renderTable(w.syncRows()))
+	b.WriteString(pterm.DefaultSection.Sprint("Peers"))
+	b.WriteString(renderTable(w.peerRows()))
+	b.WriteString(pterm.DefaultSection.Sprint("Resources"))
+	b.WriteString(renderTable(w.resourceRows()))
+	return b.String()
+}
+
+func (w *watcher) syncRows() pterm.TableData {
+	st := w.status
+	c := st.Consensus
+	if !c.Up {
+		return pterm.TableData{{"Consensus RPC", "unreachable: " + c.Error}}
+	}
+
+	state := "synced"
+	if c.CatchingUp {
+		state = "catching up"
+	}
+	rows := pterm.TableData{{"Height", fmt.Sprintf("%d (%s, block %s)", c.LatestHeight, state, age(st.Time, c.LatestBlockTime))}}
+
+	speed, haveSpeed := blockRate(w.heights)
+	tipSpeed, haveTip := blockRate(w.tips)
+	switch {
+	case haveSpeed && haveTip:
+		rows = append(rows, []string{"Speed", fmt.Sprintf("%.2f blocks/s (network %.2f blocks/s)", speed, tipSpeed)})
+	case haveSpeed:
+		rows = append(rows, []string{"Speed", fmt.Sprintf("%.2f blocks/s", speed)})
+	default:
+		rows = append(rows, []string{"Speed", "measuring..."})
+	}
+
+	rows = append(rows, []string{"Behind", blockLag(st)})
+	rows = append(rows, []string{"ETA to tip", eta(st, speed, tipSpeed, haveSpeed && haveTip)})
+
+	e := st.Execution
+	if e.Up {
+		block := fmt.Sprintf("%d (block %s)", e.BlockNumber, age(st.Time, e.BlockTime))
+		if e.Syncing {
+			block += fmt.Sprintf(", syncing %d of %d", e.CurrentBlock, e.HighestBlock)
+		}
+		rows = append(rows, []string{"Execution block", block})
+	} else {
+		rows = append(rows, []string{"Execution RPC", "unreachable: " + e.Error})
+	}
+	return append(rows, []string{"Heads agree", headsAgree(st)})
+}
+
+func (w *watcher) peerRows() pterm.TableData {
+	return pterm.TableData{
+		{"Consensus", fmt.Sprintf("%3d %s", last(w.consensusPeers), sparkline(w.consensusPeers))},
+		{"Execution", fmt.Sprintf("%3d %s", last(w.executionPeers), sparkline(w.executionPeers))},
+		{"Churn", fmt.Sprintf("%d joined, %d left in the last %s", len(w.joins), len(w.leaves), watchWindow)},
+	}
+}
+
+func (w *watcher) resourceRows() pterm.TableData {
+	var rows pterm.TableData
+	if w.procsErr != nil {
+		rows = append(rows, []string{"Processes", "unknown: " + w.procsErr.Error()})
+	}
+	running := map[string]bool{}
+	for _, p := range w.procs {
+		running[p.Service] = true
+		cpu := "measuring..."
+		if percent, ok := w.cpuPercent[p.Service]; ok {
+			cpu = fmt.Sprintf("%.1f%% CPU", percent)
+		}
+		processes := "1 process"
+		if p.Processes > 1 {
+			processes = fmt.Sprintf("%d processes", p.Processes)
+		}
+		rows = append(rows, []string{p.Service, fmt.Sprintf("pid %d (%s), %s, %s memory", p.PID, processes, cpu, formatBytes(p.RSSBytes))})
+	}
+	for _, name := range w.node.Services() {
+		if !running[name] && w.procsErr == nil {
+			rows = append(rows, []string{name, "not running"})
+		}
+	}
+
+	w.diskMu.Lock()
+	defer w.diskMu.Unlock()
+	disk := "measuring..."
+	switch {
+	case w.sizeErr != nil:
+		disk = "unknown: " + w.sizeErr.Error()
+	case !w.lastSize.at.IsZero():
+		disk = formatBytes(w.lastSize.size)
+		if elapsed := w.lastSize.at.Sub(w.firstSize.at); elapsed > 0 {
+			growth := (float64(w.lastSize.size) - float64(w.firstSize.size)) / elapsed.Hours()
+			sign := "+"
+			if growth < 0 {
+				sign, growth = "-", -growth
+			}
+			disk += fmt.Sprintf(", %s%s/h", sign, formatBytes(uint64(growth)))
+		}
+		disk += fmt.Sprintf(" (measured %s ago)", time.Since(w.lastSize.at).Round(time.Second))
+	}
+	return append(rows, []string{w.node.HomeDir, disk})
+}
+
+func renderTable(data pterm.TableData) string {
+	s, err := pterm.DefaultTable.WithData(data).Srender()
+	if err != nil {
+		return err.Error() + "\n"
+	}
+	return s + "\n"
+}
+
+// appendSample appends s and drops the samples older than watchWindow
+func appendSample(samples []heightSample, s heightSample, now time.Time) []heightSample {
+	samples = append(samples, s)
+	for len(samples) > 2 && now.Sub(samples[0].at) > watchWindow {
+		samples = samples[1:]
+	}
+	return samples
+}
+
+// appendHistory appends n and keeps the last watchPeerHistory values
+func appendHistory(history []int, n int) []int {
+	history = append(history, n)
+	if len(history) > watchPeerHistory {
+		history = history[len(history)-watchPeerHistory:]
+	}
+	return history
+}
+
+// trimTimes drops the times older than watchWindow
+func trimTimes(times []time.Time, now time.Time) []time.Time {
+	for len(times) > 0 && now.Sub(times[0]) > watchWindow {
+		times = times[1:]
+	}
+	return times
+}
+
+// blockRate returns the blocks per second over samples
+func blockRate(samples []heightSample) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(last.height-first.height) / elapsed, true
+}
+
+// eta estimates when the node reaches the tip, which keeps moving at
+// tipSpeed
+func eta(st nodestatus.Status, speed, tipSpeed float64, known bool) string {
+	switch {
+	case st.BlockLag == nil:
+		return "unknown without a reference RPC"
+	case *st.BlockLag <= 0:
+		return "at the tip"
+	case !known:
+		return "measuring..."
+	case speed <= tipSpeed:
+		return "not closing in on the tip"
+	}
+	seconds := float64(*st.BlockLag) / (speed - tipSpeed)
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("%s (around %s)", d, time.Now().Add(d).Format("Jan 2 15:04"))
+}
+
+func last(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as bars scaled to the largest one
+func sparkline(values []int) string {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = v * (len(sparkBars) - 1) / max
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
+
+// formatBytes formats n with a binary unit, e.g. 1.5 GiB
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
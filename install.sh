@@ -10,9 +10,18 @@ OS=$(uname -s | tr '[:upper:]' '[:lower:]')
 ARCH=$(uname -m)
 
 # Adjust ARCH format if necessary
-if [ "$ARCH" == "x86_64" ]; then
-    ARCH="amd64"
-fi
+case "$ARCH" in
+    x86_64|amd64)
+        ARCH="amd64"
+        ;;
+    aarch64|arm64)
+        ARCH="arm64"
+        ;;
+    *)
+        echo "Unsupported architecture: $ARCH (storycli is built for amd64 and arm64)."
+        exit 1
+        ;;
+esac
 
 # Define the download URL for storycli
 DOWNLOAD_URL="https://github.com/sSelmann/storycli/releases/download/${VERSION}/scli-${OS}-${ARCH}"
@@ -29,9 +38,9 @@ if command -v go >/dev/null 2>&1; then
             # Remove old Go version
             sudo rm -rf /usr/local/go
             # Download and install the required Go version
-            wget "https://golang.org/dl/${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
-            sudo tar -C /usr/local -xzf "${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
-            rm "${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
+            wget "https://golang.org/dl/${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
+            sudo tar -C /usr/local -xzf "${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
+            rm "${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
         else
             echo "Go version was not updated. Exiting."
             exit 1
@@ -41,9 +50,9 @@ if command -v go >/dev/null 2>&1; then
     fi
 else
     echo "Installing Go version $REQUIRED_GO_VERSION..."
-    wget "https://golang.org/dl/${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
-    sudo tar -C /usr/local -xzf "${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
-    rm "${REQUIRED_GO_VERSION}.linux-amd64.tar.gz"
+    wget "https://golang.org/dl/${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
+    sudo tar -C /usr/local -xzf "${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
+    rm "${REQUIRED_GO_VERSION}.${OS}-${ARCH}.tar.gz"
     # Add Go to PATH
     [ ! -f ~/.bash_profile ] && touch ~/.bash_profile
     echo 'export PATH=$PATH:/usr/local/go/bin:$HOME/go/bin' >> ~/.bash_profile
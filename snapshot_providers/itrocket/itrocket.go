@@ -1,29 +1,40 @@
 package itrocket
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pterm/pterm"
 
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
 	"github.com/sSelmann/storycli/utils/bash"
 	"github.com/sSelmann/storycli/utils/config"
 	"github.com/sSelmann/storycli/utils/file"
 )
 
-var (
-	// bestPrunedServerURL and bestArchiveServerURL are used to keep track
-	// of the best server URLs for Itrocket (pruned vs. archive).
-	bestPrunedServerURL  string
-	bestArchiveServerURL string
-)
+func init() {
+	// The endpoints already point at the network's state files
+	provider.Register("Itrocket", func(endpoints config.Endpoints, network config.Network) provider.SnapshotProvider {
+		return &Provider{endpoints: endpoints.Itrocket, network: network}
+	})
+}
+
+// Provider implements provider.SnapshotProvider for Itrocket snapshots.
+type Provider struct {
+	endpoints config.ItrocketEndpoints
+	network   config.Network
+}
+
+// Name returns the provider display name.
+func (p *Provider) Name() string {
+	return "Itrocket"
+}
 
 type ItrocketSnapshotState struct {
 	SnapshotName      string `json:"snapshot_name"`
@@ -39,87 +50,30 @@ type itrocketServerData struct {
 	serverURL string
 }
 
-func DownloadSnapshotItrocket(homeDir, mode string) error {
-	var serverURL string
-	if strings.ToLower(mode) == "pruned" {
-		serverURL = bestPrunedServerURL
-	} else {
-		serverURL = bestArchiveServerURL
-	}
-	if serverURL == "" {
-		return errors.New("no best server URL found for the selected mode")
-	}
-
-	pterm.Info.Println(fmt.Sprintf("Fetching snapshot data from Itrocket (%s)...", serverURL))
-	resp, err := http.Get(serverURL)
+// Apply downloads the Itrocket snapshot described by info and applies it to
+// the node of profile.
+func (p *Provider) Apply(node config.Profile, info provider.SnapshotInfo, opts provider.Options) error {
+	storySnapshotURL, gethSnapshotURL, err := p.snapshotURLs(info)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	var snapshotState ItrocketSnapshotState
-	if err := json.NewDecoder(resp.Body).Decode(&snapshotState); err != nil {
-		return err
-	}
-	if snapshotState.SnapshotName == "" || snapshotState.SnapshotGethName == "" {
-		return errors.New("failed to fetch snapshot names from Itrocket")
-	}
 
 	pterm.Info.Println("Installing required packages for Itrocket snapshot...")
 	if err := bash.RunCommand("sudo", "apt", "install", "curl", "tmux", "jq", "lz4", "unzip", "-y"); err != nil {
 		return err
 	}
 
-	pterm.Info.Println("Stopping Story and Story-Geth services...")
-	if err := bash.RunCommand("sudo", "systemctl", "stop", "story", "story-geth"); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Backup priv_validator_state.json...")
-	if err := bash.RunCommand("cp", homeDir+"/.story/story/data/priv_validator_state.json", homeDir+"/.story/story/priv_validator_state.json.backup"); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Removing old Story data...")
-	storySnapshotURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(serverURL, "/.current_state.json"), snapshotState.SnapshotName)
-	storySnapshotPath := filepath.Join(homeDir, ".story", "story_snapshot.tar.lz4")
-
-	pterm.Info.Println("Downloading Story snapshot...")
-	if err := file.DownloadFileWithAria2(storySnapshotURL, storySnapshotPath); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Extracting Story snapshot...")
-	if err := file.DecompressAndExtractLz4Tar(storySnapshotPath, filepath.Join(homeDir, ".story", "story")); err != nil {
-		return err
-	}
-	if err := os.Remove(storySnapshotPath); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Removing old Geth data...")
-	gethSnapshotURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(serverURL, "/.current_state.json"), snapshotState.SnapshotGethName)
-	gethSnapshotPath := filepath.Join(homeDir, ".story", "geth_snapshot.tar.lz4")
-
-	pterm.Info.Println("Downloading Geth snapshot...")
-	if err := file.DownloadFileWithAria2(gethSnapshotURL, gethSnapshotPath); err != nil {
-		return err
-	}
-	pterm.Info.Println("Extracting Geth snapshot...")
-	if err := file.DecompressAndExtractLz4Tar(gethSnapshotPath, filepath.Join(homeDir, ".story", "geth", "odyssey", "geth")); err != nil {
-		return err
-	}
-	if err := os.Remove(gethSnapshotPath); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Restoring priv_validator_state.json...")
-	if err := bash.RunCommand("mv", homeDir+"/.story/story/priv_validator_state.json.backup", homeDir+"/.story/story/data/priv_validator_state.json"); err != nil {
-		return err
-	}
+	archiveDir := node.HomeDir
+	err = provider.ApplySnapshot(node, opts, func(tx *provider.Transaction) error {
+		pterm.Info.Println("Downloading and extracting Story snapshot...")
+		if err := provider.DownloadAndExtract(storySnapshotURL, archiveDir, tx.StagingDir("story"), opts); err != nil {
+			return err
+		}
 
-	pterm.Info.Println("Starting Story and Story-Geth services...")
-	if err := bash.RunCommand("sudo", "systemctl", "restart", "story", "story-geth"); err != nil {
+		pterm.Info.Println("Downloading and extracting Geth snapshot...")
+		return provider.DownloadAndExtract(gethSnapshotURL, archiveDir, tx.StagingDir("geth", node.GethDataSubdir(), "geth"), opts)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -127,37 +81,25 @@ func DownloadSnapshotItrocket(homeDir, mode string) error {
 	return nil
 }
 
-func DownloadSnapshotToPathItrocket(mode, path string, endpoint config.ItrocketEndpoints) error {
-	var urls []string
-	if mode == "pruned" {
-		urls = endpoint.Pruned
-	} else {
-		urls = endpoint.Archive
-	}
-
-	best, err := fetchItrocketBestSnapshot(urls)
+// DownloadToPath downloads the Itrocket snapshot files described by info to
+// path without applying them.
+func (p *Provider) DownloadToPath(info provider.SnapshotInfo, path string, opts provider.Options) error {
+	storySnapshotURL, gethSnapshotURL, err := p.snapshotURLs(info)
 	if err != nil {
-		return fmt.Errorf("failed to fetch best Itrocket snapshot: %v", err)
+		return err
 	}
 
-	// Build full Story and Geth snapshot URLs
-	storySnapshotURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(best.serverURL, "/.current_state.json"), best.state.SnapshotName)
-	gethSnapshotURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(best.serverURL, "/.current_state.json"), best.state.SnapshotGethName)
-
-	storyFileName := best.state.SnapshotName
-	gethFileName := best.state.SnapshotGethName
-
-	storyDestPath := filepath.Join(path, storyFileName)
-	gethDestPath := filepath.Join(path, gethFileName)
+	storyDestPath := filepath.Join(path, file.ArchiveName(storySnapshotURL, "story_snapshot.tar.lz4"))
+	gethDestPath := filepath.Join(path, file.ArchiveName(gethSnapshotURL, "geth_snapshot.tar.lz4"))
 
 	pterm.Info.Println(fmt.Sprintf("Downloading Itrocket Story snapshot from %s to %s...", storySnapshotURL, storyDestPath))
-	err = file.DownloadFileWithAria2(storySnapshotURL, storyDestPath)
+	err = provider.Download(storySnapshotURL, storyDestPath, opts)
 	if err != nil {
 		return fmt.Errorf("failed to download Itrocket Story snapshot: %v", err)
 	}
 
 	pterm.Info.Println(fmt.Sprintf("Downloading Itrocket Geth snapshot from %s to %s...", gethSnapshotURL, gethDestPath))
-	err = file.DownloadFileWithAria2(gethSnapshotURL, gethDestPath)
+	err = provider.Download(gethSnapshotURL, gethDestPath, opts)
 	if err != nil {
 		return fmt.Errorf("failed to download Itrocket Geth snapshot: %v", err)
 	}
@@ -165,75 +107,108 @@ func DownloadSnapshotToPathItrocket(mode, path string, endpoint config.ItrocketE
 	return nil
 }
 
-// fetchItrocketForMode fetches Itrocket data based on pruning mode
-func FetchItrocketForMode(mode string, endpoint config.ItrocketEndpoints) (string, string, string, error) {
-	var urls []string
-	if mode == "pruned" {
-		urls = endpoint.Pruned
-	} else {
-		urls = endpoint.Archive
+// snapshotURLs returns the Story and Geth archives of info, looking up the
+// best server again only if info doesn't name them. Cached info can name
+// archives of a network Itrocket no longer serves, so it checks that first.
+func (p *Provider) snapshotURLs(info provider.SnapshotInfo) (string, string, error) {
+	if err := p.supported(); err != nil {
+		return "", "", err
 	}
-
-	best, err := fetchItrocketBestSnapshot(urls)
+	info, err := provider.Resolve(context.Background(), p, info, "story", "geth")
 	if err != nil {
-		return "", "", "", err
+		return "", "", fmt.Errorf("failed to fetch best Itrocket snapshot: %v", err)
 	}
+	return info.Files["story"], info.Files["geth"], nil
+}
 
-	// Store the best server URL based on the pruning mode
-	if mode == "pruned" {
-		bestPrunedServerURL = best.serverURL
-	} else {
-		bestArchiveServerURL = best.serverURL
+// FetchSnapshotInfo fetches Itrocket data based on pruning mode
+func (p *Provider) FetchSnapshotInfo(ctx context.Context, mode string) (provider.SnapshotInfo, error) {
+	if err := p.supported(); err != nil {
+		return provider.SnapshotInfo{}, err
 	}
+	best, err := fetchItrocketBestSnapshot(ctx, p.urlsForMode(mode))
+	if err != nil {
+		return provider.SnapshotInfo{}, err
+	}
+	if best.state.SnapshotName == "" || best.state.SnapshotGethName == "" {
+		return provider.SnapshotInfo{}, fmt.Errorf("%s has no snapshot names", best.serverURL)
+	}
+
+	// The archives are next to the state file of the server
+	baseURL := strings.TrimSuffix(best.serverURL, "/.current_state.json")
+	return provider.SnapshotInfo{
+		ProviderName: p.Name(),
+		Mode:         mode,
+		// Sum snapshot_size + geth_snapshot_size
+		TotalSize:   sumSnapshotSizes(best.state.SnapshotSize, best.state.GethSnapshotSize),
+		BlockHeight: best.state.SnapshotHeight,
+		TimeAgo:     timeDifferenceString(best.state.SnapshotBlockTime),
+		Files: map[string]string{
+			"story": baseURL + "/" + best.state.SnapshotName,
+			"geth":  baseURL + "/" + best.state.SnapshotGethName,
+		},
+	}, nil
+}
 
-	// Sum snapshot_size + geth_snapshot_size
-	totalSize := sumSnapshotSizes(best.state.SnapshotSize, best.state.GethSnapshotSize)
-	blockHeight := best.state.SnapshotHeight
-	timeAgo := timeDifferenceString(best.state.SnapshotBlockTime)
+// supported returns ErrNetworkUnsupported if Itrocket doesn't serve the
+// network
+func (p *Provider) supported() error {
+	if p.network.ItrocketChain == "" {
+		return fmt.Errorf("%w: %s", provider.ErrNetworkUnsupported, p.network.Name)
+	}
+	return nil
+}
 
-	return totalSize, blockHeight, timeAgo, nil
+func (p *Provider) urlsForMode(mode string) []string {
+	if mode == "pruned" {
+		return p.endpoints.Pruned
+	}
+	return p.endpoints.Archive
 }
 
-// fetchItrocketBestSnapshot fetches the best (latest) snapshot from the given URLs
-func fetchItrocketBestSnapshot(urls []string) (*itrocketServerData, error) {
-	var bestData *itrocketServerData
+// fetchItrocketBestSnapshot fetches the state of every server in parallel
+// and returns the one with the latest snapshot
+func fetchItrocketBestSnapshot(ctx context.Context, urls []string) (*itrocketServerData, error) {
 	const layout = "2006-01-02T15:04:05.999999999Z07"
+	if len(urls) == 0 {
+		return nil, errors.New("no Itrocket servers known for this mode")
+	}
+
+	states := make([]*ItrocketSnapshotState, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			var state ItrocketSnapshotState
+			if err := provider.GetJSON(ctx, url, &state); err != nil {
+				pterm.Warning.Printf("Could not fetch from %s: %v\n", url, err)
+				return
+			}
+			states[i] = &state
+		}(i, url)
+	}
+	wg.Wait()
 
-	for _, url := range urls {
-		resp, err := http.Get(url)
-		if err != nil {
-			pterm.Warning.Printf("Could not fetch from %s: %v\n", url, err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		var state ItrocketSnapshotState
-		if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
-			pterm.Warning.Printf("Could not decode JSON from %s: %v\n", url, err)
+	var bestData *itrocketServerData
+	var bestBT time.Time
+	for i, state := range states {
+		if state == nil {
 			continue
 		}
 
 		bt, err := time.Parse(layout, state.SnapshotBlockTime)
 		if err != nil {
-			pterm.Warning.Printf("Could not parse snapshot_block_time from %s: %v\n", url, err)
+			pterm.Warning.Printf("Could not parse snapshot_block_time from %s: %v\n", urls[i], err)
 			continue
 		}
 
-		currentData := &itrocketServerData{
-			state:     state,
-			serverURL: url,
-		}
-		if bestData == nil {
-			bestData = currentData
-		} else {
-			bestBT, err2 := time.Parse(layout, bestData.state.SnapshotBlockTime)
-			if err2 != nil {
-				bestData = currentData
-				continue
-			}
-			if bt.After(bestBT) {
-				bestData = currentData
+		if bestData == nil || bt.After(bestBT) {
+			bestData = &itrocketServerData{
+				state:     *state,
+				serverURL: urls[i],
 			}
+			bestBT = bt
 		}
 	}
 	if bestData == nil {
@@ -0,0 +1,45 @@
+package itrocket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+func TestUnsupportedNetworkWithCachedInfo(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+	rec := executor.NewRecorder()
+	active := executor.Active()
+	executor.SetActive(rec)
+	t.Cleanup(func() { executor.SetActive(active) })
+
+	network, err := config.LookupNetwork("aeneid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Provider{network: network}
+	info := provider.SnapshotInfo{
+		ProviderName: "Itrocket",
+		Mode:         "pruned",
+		Files: map[string]string{
+			"story": "https://server-1.itrocket.net/testnet/story/story_2024-10-01_1000_snap.tar.lz4",
+			"geth":  "https://server-1.itrocket.net/testnet/story/geth_story_2024-10-01_1000_snap.tar.lz4",
+		},
+	}
+
+	if err := p.DownloadToPath(info, t.TempDir(), provider.Options{}); !errors.Is(err, provider.ErrNetworkUnsupported) {
+		t.Errorf("DownloadToPath: got %v, want %v", err, provider.ErrNetworkUnsupported)
+	}
+	if err := p.Apply(config.Profile{Name: "node-1"}, info, provider.Options{}); !errors.Is(err, provider.ErrNetworkUnsupported) {
+		t.Errorf("Apply: got %v, want %v", err, provider.ErrNetworkUnsupported)
+	}
+	if got := rec.Commands(); len(got) != 0 {
+		t.Errorf("recorded calls %q, want none", got)
+	}
+}
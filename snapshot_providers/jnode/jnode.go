@@ -1,16 +1,39 @@
 package jnode
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"path/filepath"
+	"sync"
 
 	"github.com/pterm/pterm"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
 	"github.com/sSelmann/storycli/utils/bash"
+	"github.com/sSelmann/storycli/utils/config"
 	"github.com/sSelmann/storycli/utils/file"
 )
 
+func init() {
+	provider.Register("Jnode", func(endpoints config.Endpoints, network config.Network) provider.SnapshotProvider {
+		return &Provider{endpoint: endpoints.Jnode, network: network}
+	})
+}
+
+// Provider implements provider.SnapshotProvider for Jnode snapshots.
+type Provider struct {
+	endpoint string
+	network  config.Network
+
+	// response caches the API response, which covers both modes
+	mu       sync.Mutex
+	response *JnodeSnapshotResponse
+}
+
+// Name returns the provider display name.
+func (p *Provider) Name() string {
+	return "Jnode"
+}
+
 // JnodeSnapshotFiles represents the files section in jnode API response
 type JnodeSnapshotFiles struct {
 	Geth  JnodeSnapshotFile `json:"geth"`
@@ -36,96 +59,90 @@ type JnodeSnapshotResponse struct {
 	Pruned  JnodeSnapshotMode `json:"pruned"`
 }
 
-// FetchSnapshotSizesJnode fetches snapshot sizes and details from jnode API
-func FetchSnapshotSizesJnode() (
-	prunedSize, archiveSize string,
-	prunedBlockHeight, archiveBlockHeight string,
-	prunedTimeAgo, archiveTimeAgo string,
-	err error,
-) {
-	apiURL := "https://snapshot-external-providers-api.krews.xyz/snapshots/jnode"
-
-	resp, err := http.Get(apiURL)
+// FetchSnapshotInfo fetches snapshot sizes and details for mode from jnode API
+func (p *Provider) FetchSnapshotInfo(ctx context.Context, mode string) (provider.SnapshotInfo, error) {
+	snapshotResp, err := p.fetchSnapshotResponse(ctx)
 	if err != nil {
-		return "", "", "", "", "", "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", "", "", "", "", "", fmt.Errorf("received non-OK HTTP status: %s", resp.Status)
+		return provider.SnapshotInfo{}, err
 	}
 
-	var snapshotResp JnodeSnapshotResponse
-	if err := json.NewDecoder(resp.Body).Decode(&snapshotResp); err != nil {
-		return "", "", "", "", "", "", err
-	}
-
-	// PRUNED
-	prunedStoryGB := snapshotResp.Pruned.Files.Story.SizeGB
-	prunedGethGB := snapshotResp.Pruned.Files.Geth.SizeGB
-	prunedSumGB := prunedStoryGB + prunedGethGB // Combine
-	prunedSize = fmt.Sprintf("%.2fG", prunedSumGB)
-	prunedBlockHeight = snapshotResp.Pruned.SnapshotHeight
-	prunedTimeAgo = snapshotResp.Pruned.TimeAgo
-
-	// ARCHIVE
-	archiveStoryGB := snapshotResp.Archive.Files.Story.SizeGB
-	archiveGethGB := snapshotResp.Archive.Files.Geth.SizeGB
-	archiveSumGB := archiveStoryGB + archiveGethGB
-	archiveSize = fmt.Sprintf("%.2fG", archiveSumGB)
-	archiveBlockHeight = snapshotResp.Archive.SnapshotHeight
-	archiveTimeAgo = snapshotResp.Archive.TimeAgo
-
-	return prunedSize, archiveSize,
-		prunedBlockHeight, archiveBlockHeight,
-		prunedTimeAgo, archiveTimeAgo,
-		nil
+	snapshotMode, err := snapshotResp.forMode(mode)
+	if err != nil {
+		return provider.SnapshotInfo{}, err
+	}
+
+	// Combine story and geth sizes
+	sumGB := snapshotMode.Files.Story.SizeGB + snapshotMode.Files.Geth.SizeGB
+	return provider.SnapshotInfo{
+		ProviderName: p.Name(),
+		Mode:         mode,
+		TotalSize:    fmt.Sprintf("%.2fG", sumGB),
+		BlockHeight:  snapshotMode.SnapshotHeight,
+		TimeAgo:      snapshotMode.TimeAgo,
+		Files: map[string]string{
+			"story": snapshotMode.Files.Story.URL,
+			"geth":  snapshotMode.Files.Geth.URL,
+		},
+	}, nil
 }
 
-// DownloadSnapshotToPathJnode downloads the Jnode snapshot to a specified path without applying it
-func DownloadSnapshotToPathJnode(mode, path string, endpoint string) error {
-	apiURL := endpoint
+// fetchSnapshotResponse fetches and decodes the Jnode snapshot API response.
+// The response is fetched once and reused for both modes.
+func (p *Provider) fetchSnapshotResponse(ctx context.Context) (*JnodeSnapshotResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch Jnode snapshot data: %v", err)
+	if p.response != nil {
+		return p.response, nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("received non-OK HTTP status from Jnode API: %s", resp.Status)
+	if !p.network.Jnode {
+		return nil, fmt.Errorf("%w: %s", provider.ErrNetworkUnsupported, p.network.Name)
 	}
 
 	var snapshotResp JnodeSnapshotResponse
-	if err := json.NewDecoder(resp.Body).Decode(&snapshotResp); err != nil {
-		return fmt.Errorf("failed to decode Jnode API response: %v", err)
+	if err := provider.GetJSON(ctx, p.endpoint, &snapshotResp); err != nil {
+		return nil, fmt.Errorf("failed to fetch Jnode snapshot data: %v", err)
 	}
+	p.response = &snapshotResp
+	return p.response, nil
+}
 
-	var storySnapshotURL, gethSnapshotURL string
-	if mode == "pruned" {
-		storySnapshotURL = snapshotResp.Pruned.Files.Story.URL
-		gethSnapshotURL = snapshotResp.Pruned.Files.Geth.URL
-	} else if mode == "archive" {
-		storySnapshotURL = snapshotResp.Archive.Files.Story.URL
-		gethSnapshotURL = snapshotResp.Archive.Files.Geth.URL
-	} else {
-		return fmt.Errorf("unsupported mode: %s", mode)
+// forMode returns the pruned or archive section of the response
+func (r *JnodeSnapshotResponse) forMode(mode string) (*JnodeSnapshotMode, error) {
+	switch mode {
+	case "pruned":
+		return &r.Pruned, nil
+	case "archive":
+		return &r.Archive, nil
+	default:
+		return nil, fmt.Errorf("unsupported mode: %s", mode)
 	}
+}
 
-	storyFileName := filepath.Base(storySnapshotURL)
-	gethFileName := filepath.Base(gethSnapshotURL)
+// DownloadToPath downloads the Jnode snapshot described by info to a
+// specified path without applying it
+func (p *Provider) DownloadToPath(info provider.SnapshotInfo, path string, opts provider.Options) error {
+	info, err := provider.Resolve(context.Background(), p, info, "story", "geth")
+	if err != nil {
+		return err
+	}
+	storySnapshotURL := info.Files["story"]
+	gethSnapshotURL := info.Files["geth"]
+
+	storyFileName := file.ArchiveName(storySnapshotURL, "story_snapshot.tar")
+	gethFileName := file.ArchiveName(gethSnapshotURL, "geth_snapshot.tar")
 
 	storyDestPath := filepath.Join(path, storyFileName)
 	gethDestPath := filepath.Join(path, gethFileName)
 
 	pterm.Info.Println(fmt.Sprintf("Downloading Jnode Story snapshot from %s to %s...", storySnapshotURL, storyDestPath))
-	err = file.DownloadFileWithAria2(storySnapshotURL, storyDestPath)
+	err = provider.Download(storySnapshotURL, storyDestPath, opts)
 	if err != nil {
 		return fmt.Errorf("failed to download Jnode Story snapshot: %v", err)
 	}
 
 	pterm.Info.Println(fmt.Sprintf("Downloading Jnode Geth snapshot from %s to %s...", gethSnapshotURL, gethDestPath))
-	err = file.DownloadFileWithAria2(gethSnapshotURL, gethDestPath)
+	err = provider.Download(gethSnapshotURL, gethDestPath, opts)
 	if err != nil {
 		return fmt.Errorf("failed to download Jnode Geth snapshot: %v", err)
 	}
@@ -133,90 +150,30 @@ func DownloadSnapshotToPathJnode(mode, path string, endpoint string) error {
 	return nil
 }
 
-// DownloadSnapshotJnode downloads and applies the Jnode snapshot
-func DownloadSnapshotJnode(homeDir, mode string, endpoint string) error {
+// Apply downloads and applies the Jnode snapshot described by info
+func (p *Provider) Apply(node config.Profile, info provider.SnapshotInfo, opts provider.Options) error {
 	pterm.Info.Println("Installing required packages for Jnode snapshot...")
 	if err := bash.RunCommand("sudo", "apt-get", "install", "wget", "lz4", "aria2", "pv", "-y"); err != nil {
 		return err
 	}
 
-	pterm.Info.Println("Stopping Story and Story-Geth services...")
-	if err := bash.RunCommand("sudo", "systemctl", "stop", "story", "story-geth"); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Backing up priv_validator_state.json...")
-	if err := bash.RunCommand("cp", homeDir+"/.story/story/data/priv_validator_state.json", homeDir+"/.story/priv_validator_state.json.backup"); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Removing old Story and Geth data...")
-	if err := bash.RunCommand("rm", "-rf", homeDir+"/.story/story/data/*"); err != nil {
-		return err
-	}
-	if err := bash.RunCommand("rm", "-rf", homeDir+"/.story/geth/odyssey/geth/chaindata"); err != nil {
-		return err
-	}
-
-	// Fetch snapshot URLs from the Jnode API
-	apiURL := endpoint
-	resp, err := http.Get(apiURL)
+	info, err := provider.Resolve(context.Background(), p, info, "story", "geth")
 	if err != nil {
-		return fmt.Errorf("failed to fetch Jnode snapshot data: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("received non-OK HTTP status from Jnode API: %s", resp.Status)
-	}
-
-	var snapshotResp JnodeSnapshotResponse
-	if err := json.NewDecoder(resp.Body).Decode(&snapshotResp); err != nil {
-		return fmt.Errorf("failed to decode Jnode API response: %v", err)
-	}
-
-	var storySnapshotURL, gethSnapshotURL string
-	if mode == "pruned" {
-		storySnapshotURL = snapshotResp.Pruned.Files.Story.URL
-		gethSnapshotURL = snapshotResp.Pruned.Files.Geth.URL
-	} else if mode == "archive" {
-		storySnapshotURL = snapshotResp.Archive.Files.Story.URL
-		gethSnapshotURL = snapshotResp.Archive.Files.Geth.URL
-	} else {
-		return fmt.Errorf("unsupported mode: %s", mode)
-	}
-
-	pterm.Info.Println("Downloading Story snapshot...")
-	storySnapshotPath := filepath.Join(homeDir, "Story_snapshot.lz4")
-	file.DownloadFileWithAria2(storySnapshotURL, storySnapshotPath)
-
-	pterm.Info.Println("Downloading Geth snapshot...")
-	gethSnapshotPath := filepath.Join(homeDir, "Geth_snapshot.lz4")
-	file.DownloadFileWithAria2(gethSnapshotURL, gethSnapshotPath)
-
-	pterm.Info.Println("Extracting Story snapshot...")
-	if err := file.DecompressAndExtractLz4Tar(storySnapshotPath, filepath.Join(homeDir, ".story", "story")); err != nil {
-		return err
-	}
-	if err := bash.RunCommand("rm", "-f", storySnapshotPath); err != nil {
 		return err
 	}
+	storySnapshotURL := info.Files["story"]
+	gethSnapshotURL := info.Files["geth"]
 
-	pterm.Info.Println("Extracting Geth snapshot...")
-	if err := file.DecompressAndExtractLz4Tar(gethSnapshotPath, filepath.Join(homeDir, ".story", "geth", "odyssey", "geth")); err != nil {
-		return err
-	}
-	if err := bash.RunCommand("rm", "-f", gethSnapshotPath); err != nil {
-		return err
-	}
-
-	pterm.Info.Println("Restoring priv_validator_state.json...")
-	if err := bash.RunCommand("cp", homeDir+"/.story/priv_validator_state.json.backup", homeDir+"/.story/story/data/priv_validator_state.json"); err != nil {
-		return err
-	}
+	err = provider.ApplySnapshot(node, opts, func(tx *provider.Transaction) error {
+		pterm.Info.Println("Downloading and extracting Story snapshot...")
+		if err := provider.DownloadAndExtract(storySnapshotURL, node.HomeDir, tx.StagingDir("story"), opts); err != nil {
+			return err
+		}
 
-	pterm.Info.Println("Starting Story and Story-Geth services...")
-	if err := bash.RunCommand("sudo", "systemctl", "restart", "story", "story-geth"); err != nil {
+		pterm.Info.Println("Downloading and extracting Geth snapshot...")
+		return provider.DownloadAndExtract(gethSnapshotURL, node.HomeDir, tx.StagingDir("geth", node.GethDataSubdir(), "geth"), opts)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -1,18 +1,44 @@
 package krews
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pterm/pterm"
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
 	"github.com/sSelmann/storycli/utils/bash"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
 )
 
+func init() {
+	provider.Register("Krews", func(endpoints config.Endpoints, network config.Network) provider.SnapshotProvider {
+		return &Provider{endpoint: endpoints.Krews, network: network}
+	})
+}
+
+// Provider implements provider.SnapshotProvider for Krews snapshots.
+type Provider struct {
+	endpoint string
+	network  config.Network
+
+	// response caches the API response, which covers both modes
+	mu       sync.Mutex
+	response *KrewsSnapshotResponse
+}
+
+// Name returns the provider display name.
+func (p *Provider) Name() string {
+	return "Krews"
+}
+
 type SnapshotKrews struct {
 	Name         string      `json:"name"`
 	Pruned       bool        `json:"pruned"`
@@ -26,108 +52,146 @@ type KrewsSnapshotResponse struct {
 	Snapshots []SnapshotKrews `json:"details"`
 }
 
-func DownloadSnapshotKrews(homeDir, pruningMode string) error {
-	snapshotName := fmt.Sprintf("story_testnet_%s_snapshot", pruningMode)
-	snapshotURL := fmt.Sprintf("krews-snapshot:krews-1-eu/%s", snapshotName)
-	destDir := filepath.Join(homeDir, ".story")
-
-	pterm.Info.Println("Installing and configuring Rclone for Krews snapshot...")
-	err := installAndConfigureRcloneKrews(homeDir)
+// Apply downloads the Krews snapshot described by info into the node of
+// profile. Krews names its snapshots after the network and mode, so only
+// the mode of info is used.
+func (p *Provider) Apply(node config.Profile, info provider.SnapshotInfo, opts provider.Options) error {
+	snapshotName, err := p.snapshotName(info.Mode)
 	if err != nil {
 		return err
 	}
+	snapshotURL := fmt.Sprintf("krews-snapshot:krews-1-eu/%s", snapshotName)
 
-	pterm.Info.Println("Backup priv_validator_state.json...")
-	err = bash.RunCommand("cp", homeDir+"/.story/story/data/priv_validator_state.json", homeDir+"/.story/story/priv_validator_state.json.backup")
+	pterm.Info.Println("Installing and configuring Rclone for Krews snapshot...")
+	conf, err := installAndConfigureRcloneKrews()
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command("rclone", "copy", "--no-check-certificate", "--transfers=6", "--checkers=6", snapshotURL, destDir, "--progress")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	err = cmd.Run()
-	if err != nil {
-		return err
-	}
+	err = provider.ApplySnapshot(node, opts, func(tx *provider.Transaction) error {
+		pterm.Info.Println("Downloading Krews snapshot...")
+		destDir := tx.StagingDir()
+		cmd := rclone(conf, "copy", "--no-check-certificate", "--transfers=6", "--checkers=6", snapshotURL, destDir, "--progress")
+		cmd.Stdout = opts.ProgressOutput()
+		cmd.Stderr = os.Stderr
+		if err := executor.Run(cmd); err != nil {
+			return err
+		}
 
-	pterm.Info.Println("Restoring priv_validator_state.json...")
-	err = bash.RunCommand("mv", homeDir+"/.story/story/priv_validator_state.json.backup"+homeDir+"/.story/story/data/priv_validator_state.json")
+		if opts.Verify {
+			return verifyRcloneCopy(conf, snapshotURL, destDir, opts.ProgressOutput())
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	pterm.Success.Println("Snapshot successfully downloaded from Krews.")
+	pterm.Success.Println("Snapshot successfully downloaded and applied from Krews.")
 	return nil
 }
 
-func DownloadSnapshotToPathKrews(mode, path string) error {
-	snapshotName := fmt.Sprintf("story_testnet_%s_snapshot", mode)
-	snapshotURL := fmt.Sprintf("krews-snapshot:krews-1-eu/%s", snapshotName)
-	homeDir, err := os.UserHomeDir()
+// DownloadToPath downloads the Krews snapshot described by info to path
+// without applying it.
+func (p *Provider) DownloadToPath(info provider.SnapshotInfo, path string, opts provider.Options) error {
+	snapshotName, err := p.snapshotName(info.Mode)
 	if err != nil {
 		return err
 	}
+	snapshotURL := fmt.Sprintf("krews-snapshot:krews-1-eu/%s", snapshotName)
 
 	pterm.Info.Println("Installing and configuring Rclone for Krews snapshot...")
-	err = installAndConfigureRcloneKrews(homeDir)
+	conf, err := installAndConfigureRcloneKrews()
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command("rclone", "copy", "--no-check-certificate", "--transfers=6", "--checkers=6", snapshotURL, path+"/"+snapshotName, "--progress")
-	cmd.Stdout = os.Stdout
+	cmd := rclone(conf, "copy", "--no-check-certificate", "--transfers=6", "--checkers=6", snapshotURL, path+"/"+snapshotName, "--progress")
+	cmd.Stdout = opts.ProgressOutput()
 	cmd.Stderr = os.Stderr
 
-	err = cmd.Run()
+	err = executor.Run(cmd)
 	if err != nil {
 		return fmt.Errorf("rclone copy failed: %v", err)
 	}
 
+	if opts.Verify {
+		return verifyRcloneCopy(conf, snapshotURL, path+"/"+snapshotName, opts.ProgressOutput())
+	}
 	return nil
 }
 
-func FetchSnapshotSizesKrews(endpoint string) (
-	prunedSize, archiveSize string,
-	prunedBlockHeight, archiveBlockHeight string,
-	prunedTimeAgo, archiveTimeAgo string,
-	err error,
-) {
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return "", "", "", "", "", "", err
+// verifyRcloneCopy checks that every file of the remote snapshot was copied
+// to destDir with a matching size and checksum. The output of rclone goes to
+// out.
+func verifyRcloneCopy(conf, snapshotURL, destDir string, out io.Writer) error {
+	pterm.Info.Println("Verifying Krews snapshot files...")
+	cmd := rclone(conf, "check", "--no-check-certificate", "--one-way", "--checkers=6", snapshotURL, destDir)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	if err := executor.Run(cmd); err != nil {
+		return fmt.Errorf("Krews snapshot verification failed: %v", err)
 	}
-	defer resp.Body.Close()
+	pterm.Success.Println("Krews snapshot files verified.")
+	return nil
+}
 
-	var snapshotResp KrewsSnapshotResponse
-	if err := json.NewDecoder(resp.Body).Decode(&snapshotResp); err != nil {
-		return "", "", "", "", "", "", err
+// FetchSnapshotInfo fetches the Krews snapshot details for the given mode
+func (p *Provider) FetchSnapshotInfo(ctx context.Context, mode string) (provider.SnapshotInfo, error) {
+	info := provider.SnapshotInfo{
+		ProviderName: p.Name(),
+		Mode:         mode,
+		TotalSize:    "unknown",
+	}
+
+	if p.network.KrewsSnapshot == "" {
+		return info, fmt.Errorf("%w: %s", provider.ErrNetworkUnsupported, p.network.Name)
+	}
+
+	snapshotResp, err := p.fetchSnapshotResponse(ctx)
+	if err != nil {
+		return info, err
 	}
 
 	for _, snapshot := range snapshotResp.Snapshots {
-		sz := snapshot.Size
-		blk := snapshot.Block.String()
-		timeAgo := parseKrewsSnapshotDate(snapshot.SnapshotDate)
-
-		if snapshot.Pruned {
-			prunedSize = sz
-			prunedBlockHeight = blk
-			prunedTimeAgo = timeAgo
-		} else {
-			archiveSize = sz
-			archiveBlockHeight = blk
-			archiveTimeAgo = timeAgo
+		if snapshot.Pruned != (mode == "pruned") {
+			continue
 		}
+		if snapshot.Size != "" {
+			info.TotalSize = snapshot.Size
+		}
+		info.BlockHeight = snapshot.Block.String()
+		info.TimeAgo = parseKrewsSnapshotDate(snapshot.SnapshotDate)
+	}
+
+	return info, nil
+}
+
+// fetchSnapshotResponse fetches the Krews snapshot API once and reuses the
+// response for both modes
+func (p *Provider) fetchSnapshotResponse(ctx context.Context) (*KrewsSnapshotResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.response != nil {
+		return p.response, nil
 	}
 
-	if prunedSize == "" {
-		prunedSize = "unknown"
+	var snapshotResp KrewsSnapshotResponse
+	if err := provider.GetJSON(ctx, p.endpoint, &snapshotResp); err != nil {
+		return nil, err
 	}
-	if archiveSize == "" {
-		archiveSize = "unknown"
+	p.response = &snapshotResp
+	return p.response, nil
+}
+
+// snapshotName returns the name of the Krews snapshot for mode on the
+// provider's network
+func (p *Provider) snapshotName(mode string) (string, error) {
+	if p.network.KrewsSnapshot == "" {
+		return "", fmt.Errorf("%w: %s", provider.ErrNetworkUnsupported, p.network.Name)
 	}
-	return prunedSize, archiveSize, prunedBlockHeight, archiveBlockHeight, prunedTimeAgo, archiveTimeAgo, nil
+	return fmt.Sprintf(p.network.KrewsSnapshot, mode), nil
 }
 
 // parseKrewsSnapshotDate parses date strings like "26 Dec 2024, 18:17:50"
@@ -158,7 +222,33 @@ func parseKrewsSnapshotDate(dateStr string) string {
 	}
 }
 
-func installAndConfigureRcloneKrews(homeDir string) error {
+// rcloneConfig defines the rclone remote the Krews snapshots are served from
+const rcloneConfig = `[krews-snapshot]
+type = s3
+provider = DigitalOcean
+region = fra1
+endpoint = https://fra1.cdn.digitaloceanspaces.com
+`
+
+// rcloneConfigPath is the rclone config holding the Krews remote. It is
+// kept apart from ~/.config/rclone/rclone.conf, so the remotes of the user
+// are left alone.
+func rcloneConfigPath() (string, error) {
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "rclone-krews.conf"), nil
+}
+
+// rclone returns an rclone command using the config at conf
+func rclone(conf string, args ...string) executor.Command {
+	return executor.Cmd("rclone", append([]string{"--config", conf}, args...)...)
+}
+
+// installAndConfigureRcloneKrews installs rclone if needed and writes the
+// Krews remote config, returning its path
+func installAndConfigureRcloneKrews() (string, error) {
 	// Check if Rclone is installed
 	_, err := exec.LookPath("rclone")
 	if err != nil {
@@ -166,7 +256,7 @@ func installAndConfigureRcloneKrews(homeDir string) error {
 		// Install Rclone
 		err = bash.RunCommand("bash", "-c", "sudo -v; curl https://rclone.org/install.sh | sudo bash")
 		if err != nil {
-			return err
+			return "", err
 		}
 		pterm.Success.Println("Rclone Installed")
 	} else {
@@ -175,21 +265,18 @@ func installAndConfigureRcloneKrews(homeDir string) error {
 
 	// Configure Rclone for Krews
 	pterm.Info.Println("Configuring Rclone for Krews...")
-	rcloneConf := `[krews-snapshot]
-type = s3
-provider = DigitalOcean
-region = fra1
-endpoint = https://fra1.cdn.digitaloceanspaces.com
-`
-	rcloneConfDir := fmt.Sprintf("%s/.config/rclone", homeDir)
-	err = os.MkdirAll(rcloneConfDir, os.ModePerm)
+	conf, err := rcloneConfigPath()
 	if err != nil {
-		return err
+		return "", err
 	}
-	err = os.WriteFile(fmt.Sprintf("%s/rclone.conf", rcloneConfDir), []byte(rcloneConf), 0644)
+	err = executor.Active().MkdirAll(filepath.Dir(conf), 0755)
 	if err != nil {
-		return err
+		return "", err
+	}
+	err = executor.Active().WriteFile(conf, []byte(rcloneConfig), 0644)
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	return conf, nil
 }
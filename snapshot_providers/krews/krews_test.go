@@ -0,0 +1,62 @@
+package krews
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/snapshot_providers/provider"
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+func TestDownloadToPathKeepsRcloneConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+	rec := executor.NewRecorder()
+	active := executor.Active()
+	executor.SetActive(rec)
+	t.Cleanup(func() { executor.SetActive(active) })
+
+	network, err := config.LookupNetwork("odyssey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Provider{network: network}
+	if err := p.DownloadToPath(provider.SnapshotInfo{ProviderName: "Krews", Mode: "pruned"}, "/snapshots", provider.Options{Verify: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range rec.Commands() {
+		got = append(got, strings.ReplaceAll(c, home, "~"))
+	}
+	want := []string{
+		"mkdir -p ~/.config/storycli",
+		"write ~/.config/storycli/rclone-krews.conf (N bytes, mode 0644)",
+		"rclone --config ~/.config/storycli/rclone-krews.conf copy --no-check-certificate --transfers=6 --checkers=6 krews-snapshot:krews-1-eu/story_testnet_pruned_snapshot /snapshots/story_testnet_pruned_snapshot --progress",
+		"rclone --config ~/.config/storycli/rclone-krews.conf check --no-check-certificate --one-way --checkers=6 krews-snapshot:krews-1-eu/story_testnet_pruned_snapshot /snapshots/story_testnet_pruned_snapshot",
+	}
+	if _, err := exec.LookPath("rclone"); err != nil {
+		want = append([]string{"bash -c 'sudo -v; curl https://rclone.org/install.sh | sudo bash'"}, want...)
+	}
+	for i := range got {
+		if strings.HasPrefix(got[i], "write ") {
+			got[i] = got[i][:strings.Index(got[i], "(")] + "(N bytes, mode 0644)"
+		}
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("recorded calls:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+
+	conf, ok := rec.Written(filepath.Join(home, ".config", "storycli", "rclone-krews.conf"))
+	if !ok || !strings.HasPrefix(string(conf), "[krews-snapshot]\n") {
+		t.Errorf("Krews remote not written, got %q", conf)
+	}
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/file"
+)
+
+// DownloadAndExtract extracts the tar archive at url (compressed with lz4,
+// zstd, gzip or not at all) into destDir. In streaming mode the archive is
+// extracted while it is downloaded; otherwise it is downloaded into
+// archiveDir first, checked and removed after extraction.
+func DownloadAndExtract(url, archiveDir, destDir string, opts Options) error {
+	return executor.Perform("download "+url+" and extract it into "+destDir, func() error {
+		if opts.Streaming() {
+			return file.StreamExtractArchive(url, destDir, opts.extractOptions(), opts.Progress)
+		}
+
+		archivePath := filepath.Join(archiveDir, file.ArchiveName(url, "snapshot.tar"))
+		if err := file.DownloadVerified(url, archivePath, opts.Verify, opts.Progress); err != nil {
+			return err
+		}
+		if err := file.ExtractArchiveFile(archivePath, destDir, opts.extractOptions()); err != nil {
+			return err
+		}
+		return os.Remove(archivePath)
+	})
+}
+
+// Download downloads url to dest and checks it as set in opts.
+func Download(url, dest string, opts Options) error {
+	return executor.Perform("download "+url+" to "+dest, func() error {
+		return file.DownloadVerified(url, dest, opts.Verify, opts.Progress)
+	})
+}
+
+func (o Options) extractOptions() file.ExtractOptions {
+	return file.ExtractOptions{PreserveOwnership: o.PreserveOwnership}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sSelmann/storycli/utils/config"
+)
+
+// SnapshotInfo holds the metadata a provider reports for one pruning mode
+type SnapshotInfo struct {
+	ProviderName string `json:"provider"`
+	Mode         string `json:"mode"`
+	TotalSize    string `json:"total_size"` // sum of the story and geth snapshot sizes
+	BlockHeight  string `json:"block_height"`
+	TimeAgo      string `json:"time_ago"`
+	// Files are the archives of the snapshot by part, e.g. "story" and
+	// "geth", as resolved by FetchSnapshotInfo. Downloads use them, so the
+	// snapshot that was listed is the one downloaded.
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// Height parses BlockHeight, which providers report like "1234567" or
+// "1,234,567". It reports false if the height is unknown.
+func (s SnapshotInfo) Height() (int64, bool) {
+	height, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(s.BlockHeight), ",", ""), 10, 64)
+	if err != nil || height <= 0 {
+		return 0, false
+	}
+	return height, true
+}
+
+// ErrNetworkUnsupported is returned by providers that have no snapshots for
+// the network they were built for.
+var ErrNetworkUnsupported = errors.New("provider has no snapshots for this network")
+
+// Options controls how a snapshot is downloaded and applied.
+type Options struct {
+	// Verify refuses to extract or apply files that don't match the size or
+	// checksum published by the provider, instead of only warning.
+	Verify bool
+
+	// Resumable downloads each archive to disk before extracting it, so an
+	// interrupted download can be continued later. Otherwise archives are
+	// extracted while they are downloaded, which needs no extra disk space.
+	// Verify implies Resumable, as an archive can only be checked before
+	// extraction once it is on disk.
+	Resumable bool
+
+	// PreserveOwnership keeps the file owners stored in the archives.
+	PreserveOwnership bool
+
+	// KeepOld keeps the replaced data next to the new data (data.old,
+	// chaindata.old) instead of deleting it after a successful apply.
+	KeepOld bool
+
+	// Progress receives the progress bars and the output of the tools that
+	// download snapshots. Nil is stdout.
+	Progress io.Writer
+}
+
+// ProgressOutput returns where progress is written.
+func (o Options) ProgressOutput() io.Writer {
+	if o.Progress == nil {
+		return os.Stdout
+	}
+	return o.Progress
+}
+
+// Streaming reports whether archives are extracted while downloading.
+func (o Options) Streaming() bool {
+	return !o.Resumable && !o.Verify
+}
+
+// SnapshotProvider is implemented by every snapshot source that storycli can
+// list, download and apply.
+type SnapshotProvider interface {
+	// Name returns the display name of the provider (e.g. "Itrocket").
+	Name() string
+
+	// FetchSnapshotInfo returns the latest snapshot metadata for the given
+	// mode. Providers whose API covers both modes in one response only
+	// request it once per instance.
+	FetchSnapshotInfo(ctx context.Context, mode string) (SnapshotInfo, error)
+
+	// DownloadToPath downloads the snapshot described by info, as returned
+	// by FetchSnapshotInfo, into path without applying it.
+	DownloadToPath(info SnapshotInfo, path string, opts Options) error
+
+	// Apply downloads the snapshot described by info and applies it to the
+	// node of profile, which runs the network the provider was built for.
+	Apply(node config.Profile, info SnapshotInfo, opts Options) error
+}
+
+// Resolve returns info if it has every file in parts, and fetches the
+// latest snapshot info for its mode from p otherwise, e.g. for info cached
+// before it had files.
+func Resolve(ctx context.Context, p SnapshotProvider, info SnapshotInfo, parts ...string) (SnapshotInfo, error) {
+	complete := true
+	for _, part := range parts {
+		if info.Files[part] == "" {
+			complete = false
+		}
+	}
+	if complete {
+		return info, nil
+	}
+	fetched, err := p.FetchSnapshotInfo(ctx, info.Mode)
+	if err != nil {
+		return fetched, err
+	}
+	for _, part := range parts {
+		if fetched.Files[part] == "" {
+			return fetched, fmt.Errorf("%s reported no %s archive for the %s snapshot", p.Name(), part, info.Mode)
+		}
+	}
+	return fetched, nil
+}
+
+// Factory builds a provider for network from the resolved API endpoints.
+type Factory func(endpoints config.Endpoints, network config.Network) SnapshotProvider
+
+type registration struct {
+	name    string
+	factory Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registration
+)
+
+// Register makes a snapshot provider available under the given name.
+// Providers are listed in registration order. Register panics if the same
+// name is registered twice.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("provider: Register factory is nil for " + name)
+	}
+	for _, r := range registry {
+		if strings.EqualFold(r.name, name) {
+			panic("provider: Register called twice for " + name)
+		}
+	}
+	registry = append(registry, registration{name: name, factory: factory})
+}
+
+// Names returns the names of all registered providers in registration order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for _, r := range registry {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// All builds every registered provider for network with the given endpoints.
+func All(endpoints config.Endpoints, network config.Network) []SnapshotProvider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	providers := make([]SnapshotProvider, 0, len(registry))
+	for _, r := range registry {
+		providers = append(providers, r.factory(endpoints, network))
+	}
+	return providers
+}
+
+// Find returns the provider with the given name (case-insensitive) from providers.
+func Find(providers []SnapshotProvider, name string) (SnapshotProvider, error) {
+	for _, p := range providers {
+		if strings.EqualFold(p.Name(), name) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported provider: %s", name)
+}
@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/service"
+)
+
+// Target is a data directory under the node home that is replaced by a
+// snapshot.
+type Target struct {
+	// Name is used in messages, e.g. "Story".
+	Name string
+	// Path is the data directory relative to the node home, e.g. "story/data".
+	Path string
+}
+
+// StoryTarget is the consensus data of the story node. Snapshots come
+// without priv_validator_state.json; ApplySnapshot carries the one of the
+// node over with a StateGuard.
+func StoryTarget() Target {
+	return Target{
+		Name: "Story",
+		Path: filepath.Join("story", "data"),
+	}
+}
+
+// GethTarget is the chain data of story-geth in the given geth data subdir
+// (see config.Network.GethDataSubdir)
+func GethTarget(dataSubdir string) Target {
+	return Target{
+		Name: "Geth",
+		Path: filepath.Join("geth", dataSubdir, "geth", "chaindata"),
+	}
+}
+
+// stagingDirName is the directory in the node home that snapshots are extracted
+// into before they replace the live data
+const stagingDirName = ".snapshot-staging"
+
+// Transaction replaces the node data with a snapshot. The snapshot is first
+// put into a staging directory on the same filesystem, laid out like the
+// node home. Commit then checks it and swaps it in with renames, restoring
+// the previous data if anything goes wrong.
+type Transaction struct {
+	root    string
+	staging string
+	targets []Target
+	keepOld bool
+}
+
+// NewTransaction creates an empty staging directory under the node home.
+func NewTransaction(nodeHome string, opts Options, targets ...Target) (*Transaction, error) {
+	tx := &Transaction{
+		root:    nodeHome,
+		staging: filepath.Join(nodeHome, stagingDirName),
+		targets: targets,
+		keepOld: opts.KeepOld,
+	}
+
+	// Leftovers from an interrupted run can't be trusted
+	ex := executor.Active()
+	if err := ex.RemoveAll(tx.staging); err != nil {
+		return nil, fmt.Errorf("failed to clean staging directory: %v", err)
+	}
+	if err := ex.MkdirAll(tx.staging, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %v", err)
+	}
+	return tx, nil
+}
+
+// StagingDir returns a path inside the staging directory, mirroring the
+// layout of the node home (e.g. StagingDir("story") for the story home).
+func (tx *Transaction) StagingDir(elem ...string) string {
+	return filepath.Join(append([]string{tx.staging}, elem...)...)
+}
+
+// Cleanup removes the staging directory.
+func (tx *Transaction) Cleanup() {
+	if err := executor.Active().RemoveAll(tx.staging); err != nil {
+		pterm.Warning.Printf("Failed to remove staging directory %s: %v\n", tx.staging, err)
+	}
+}
+
+// Validate checks that every target has staged data. A validator state
+// restored into the staged data doesn't count, as it didn't come with the
+// snapshot.
+func (tx *Transaction) Validate() error {
+	for _, t := range tx.targets {
+		entries, err := os.ReadDir(tx.StagingDir(t.Path))
+		if err != nil || !hasSnapshotData(entries) {
+			return fmt.Errorf("%s snapshot is missing %s", t.Name, t.Path)
+		}
+	}
+	return nil
+}
+
+// hasSnapshotData reports whether entries has anything besides the
+// validator state
+func hasSnapshotData(entries []os.DirEntry) bool {
+	for _, e := range entries {
+		if e.Name() != validatorStateFile {
+			return true
+		}
+	}
+	return false
+}
+
+// Commit swaps the staged data in; check it with Validate first. The
+// previous data is moved aside to <path>.old and removed afterwards unless
+// KeepOld is set. If a swap fails, every target is restored to its previous
+// data.
+func (tx *Transaction) Commit() error {
+	var swapped []Target
+	for _, t := range tx.targets {
+		if err := tx.swap(t); err != nil {
+			pterm.Warning.Printf("Failed to swap in %s data, rolling back...\n", t.Name)
+			for i := len(swapped) - 1; i >= 0; i-- {
+				if rerr := tx.restore(swapped[i]); rerr != nil {
+					pterm.Error.Printf("Failed to restore %s data: %v\n", swapped[i].Name, rerr)
+				}
+			}
+			return fmt.Errorf("failed to swap in %s data: %v", t.Name, err)
+		}
+		swapped = append(swapped, t)
+	}
+
+	for _, t := range tx.targets {
+		old := tx.oldPath(t)
+		if tx.keepOld {
+			if _, err := os.Stat(old); err == nil {
+				pterm.Info.Printf("Previous %s data kept in %s\n", t.Name, old)
+			}
+			continue
+		}
+		if err := executor.Active().RemoveAll(old); err != nil {
+			pterm.Warning.Printf("Failed to remove previous %s data %s: %v\n", t.Name, old, err)
+		}
+	}
+	return nil
+}
+
+func (tx *Transaction) oldPath(t Target) string {
+	return filepath.Join(tx.root, t.Path+".old")
+}
+
+// swap moves the live data of t aside and the staged data into its place
+func (tx *Transaction) swap(t Target) error {
+	ex := executor.Active()
+	live := filepath.Join(tx.root, t.Path)
+	old := tx.oldPath(t)
+
+	if err := ex.RemoveAll(old); err != nil {
+		return err
+	}
+	if err := ex.MkdirAll(filepath.Dir(live), 0755); err != nil {
+		return err
+	}
+
+	hadLive := true
+	if err := ex.Rename(live, old); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		hadLive = false
+	}
+
+	if err := ex.Rename(tx.StagingDir(t.Path), live); err != nil {
+		if hadLive {
+			if rerr := ex.Rename(old, live); rerr != nil {
+				return fmt.Errorf("%v (restoring previous data also failed: %v)", err, rerr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// restore undoes a successful swap of t
+func (tx *Transaction) restore(t Target) error {
+	ex := executor.Active()
+	live := filepath.Join(tx.root, t.Path)
+	old := tx.oldPath(t)
+
+	if err := ex.Rename(live, tx.StagingDir(t.Path)); err != nil {
+		return err
+	}
+	if _, err := os.Stat(old); os.IsNotExist(err) {
+		return nil
+	}
+	return ex.Rename(old, live)
+}
+
+// ApplySnapshot runs the whole apply transaction for the node of profile.
+// fetch fills the staging directory while the node keeps running, and the
+// staged data is checked before the services are stopped to swap it in.
+// The validator signing state is carried over into the new data; the
+// services are started again whatever happens, unless the state ended up
+// behind the recorded one.
+func ApplySnapshot(node config.Profile, opts Options, fetch func(tx *Transaction) error) error {
+	tx, err := NewTransaction(node.HomeDir, opts, StoryTarget(), GethTarget(node.GethDataSubdir()))
+	if err != nil {
+		return err
+	}
+	defer tx.Cleanup()
+
+	if err := fetch(tx); err != nil {
+		return err
+	}
+	if err := executor.Perform("check the snapshot staged in "+tx.staging, tx.Validate); err != nil {
+		return err
+	}
+
+	manager, err := service.ForProfile(node)
+	if err != nil {
+		return err
+	}
+	pterm.Info.Printf("Stopping %s and %s services...\n", node.StoryService, node.GethService)
+	if err := manager.Stop(node.StoryService, node.GethService); err != nil {
+		return err
+	}
+
+	guard := NewStateGuard(node.HomeDir)
+	err = executor.Perform("back up the validator signing state and carry it over into the new data", func() error {
+		if err := guard.Record(); err != nil {
+			return err
+		}
+		return guard.Restore(tx.StagingDir(StoryTarget().Path))
+	})
+	if err != nil {
+		// The live data is untouched
+		pterm.Info.Printf("Starting %s and %s services...\n", node.StoryService, node.GethService)
+		if rerr := manager.Restart(node.StoryService, node.GethService); rerr != nil {
+			pterm.Error.Printf("Failed to start the services: %v\n", rerr)
+		}
+		return err
+	}
+
+	pterm.Info.Println("Swapping in the new Story and Geth data...")
+	commitErr := tx.Commit()
+
+	if err := executor.Perform("check the validator signing state", guard.Check); err != nil {
+		pterm.Error.Println("Not starting the services to avoid double signing.")
+		return err
+	}
+
+	pterm.Info.Printf("Starting %s and %s services...\n", node.StoryService, node.GethService)
+	if err := manager.Restart(node.StoryService, node.GethService); err != nil {
+		if commitErr != nil {
+			return commitErr
+		}
+		return err
+	}
+	return commitErr
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransactionValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		// staged lists the files put into the staging directory
+		staged  []string
+		wantErr string
+	}{
+		{
+			name:   "snapshot without a validator state",
+			staged: []string{"story/data/blockstore.db/000001.log", "geth/odyssey/geth/chaindata/CURRENT"},
+		},
+		{
+			name:    "missing geth data",
+			staged:  []string{"story/data/blockstore.db/000001.log"},
+			wantErr: "Geth snapshot is missing",
+		},
+		{
+			name:    "empty story data",
+			staged:  []string{"story/data/", "geth/odyssey/geth/chaindata/CURRENT"},
+			wantErr: "Story snapshot is missing",
+		},
+		{
+			name:    "story data with only the restored validator state",
+			staged:  []string{"story/data/priv_validator_state.json", "geth/odyssey/geth/chaindata/CURRENT"},
+			wantErr: "Story snapshot is missing",
+		},
+		{
+			name:    "missing story data",
+			staged:  []string{"story/config/", "geth/odyssey/geth/chaindata/CURRENT"},
+			wantErr: "Story snapshot is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewTransaction(t.TempDir(), Options{}, StoryTarget(), GethTarget("odyssey"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.staged {
+				path := tx.StagingDir(name)
+				if strings.HasSuffix(name, "/") {
+					err = os.MkdirAll(path, 0755)
+				} else if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+					err = os.WriteFile(path, []byte("x"), 0644)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = tx.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
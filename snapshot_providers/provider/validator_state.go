@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pterm/pterm"
+)
+
+// ErrStateRegression is returned when the validator signing state after a
+// snapshot apply is behind the state recorded before it. Starting the node
+// in that state risks double signing.
+var ErrStateRegression = errors.New("validator signing state went backwards")
+
+// validatorStateFile is the CometBFT signing state in the story data dir
+const validatorStateFile = "priv_validator_state.json"
+
+// ValidatorState is the last signed height, round and step of a validator.
+type ValidatorState struct {
+	Height int64
+	Round  int32
+	Step   int8
+}
+
+// Less reports whether s comes before other.
+func (s ValidatorState) Less(other ValidatorState) bool {
+	if s.Height != other.Height {
+		return s.Height < other.Height
+	}
+	if s.Round != other.Round {
+		return s.Round < other.Round
+	}
+	return s.Step < other.Step
+}
+
+func (s ValidatorState) String() string {
+	return fmt.Sprintf("height %d, round %d, step %d", s.Height, s.Round, s.Step)
+}
+
+// ParseValidatorState decodes the contents of priv_validator_state.json.
+// CometBFT writes the height as a string and round and step as numbers.
+func ParseValidatorState(data []byte) (ValidatorState, error) {
+	var raw struct {
+		Height json.RawMessage `json:"height"`
+		Round  int32           `json:"round"`
+		Step   int8            `json:"step"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ValidatorState{}, err
+	}
+
+	var heightStr string
+	if err := json.Unmarshal(raw.Height, &heightStr); err != nil {
+		// Accept a plain number as well
+		heightStr = string(raw.Height)
+	}
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil {
+		return ValidatorState{}, fmt.Errorf("invalid height %q: %v", heightStr, err)
+	}
+	return ValidatorState{Height: height, Round: raw.Round, Step: raw.Step}, nil
+}
+
+// StateGuard protects the validator signing state while the node data is
+// replaced. Record saves the current state before the apply, Restore puts
+// it into the new data and Check refuses a state that went backwards.
+type StateGuard struct {
+	nodeHome string
+	raw      []byte
+	saved    ValidatorState
+	found    bool
+}
+
+// NewStateGuard returns a guard for the node with the given home, e.g.
+// ~/.story.
+func NewStateGuard(nodeHome string) *StateGuard {
+	return &StateGuard{nodeHome: nodeHome}
+}
+
+// livePath is priv_validator_state.json of the running node
+func (g *StateGuard) livePath() string {
+	return filepath.Join(g.nodeHome, "story", "data", validatorStateFile)
+}
+
+// BackupPath is where the recorded state is kept during the apply.
+func (g *StateGuard) BackupPath() string {
+	return filepath.Join(g.nodeHome, "story", validatorStateFile+".backup")
+}
+
+// Record reads the current signing state and writes a backup of it. The
+// services must be stopped so the state can't change afterwards. A node
+// without a state file has nothing to protect.
+func (g *StateGuard) Record() error {
+	data, err := os.ReadFile(g.livePath())
+	if os.IsNotExist(err) {
+		pterm.Warning.Println("No priv_validator_state.json found, nothing to back up.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read validator state: %v", err)
+	}
+
+	state, err := ParseValidatorState(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", g.livePath(), err)
+	}
+
+	if err := writeFileSync(g.BackupPath(), data); err != nil {
+		return fmt.Errorf("failed to back up validator state: %v", err)
+	}
+
+	g.raw = data
+	g.saved = state
+	g.found = true
+	pterm.Info.Printf("Backed up priv_validator_state.json (%s) to %s\n", state, g.BackupPath())
+	return nil
+}
+
+// Restore writes the recorded state into dataDir, which is the story data
+// directory that is about to go live.
+func (g *StateGuard) Restore(dataDir string) error {
+	if !g.found {
+		return nil
+	}
+
+	target := filepath.Join(dataDir, validatorStateFile)
+	// Don't write through a symlink that came with the snapshot
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := writeFileSync(target, g.raw); err != nil {
+		return fmt.Errorf("failed to restore validator state: %v", err)
+	}
+	return nil
+}
+
+// Check compares the live signing state against the recorded one and
+// returns ErrStateRegression if it is behind.
+func (g *StateGuard) Check() error {
+	if !g.found {
+		return nil
+	}
+
+	data, err := os.ReadFile(g.livePath())
+	if err != nil {
+		return fmt.Errorf("%w: %s is missing, restore it from %s", ErrStateRegression, g.livePath(), g.BackupPath())
+	}
+	state, err := ParseValidatorState(data)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse %s (%v), restore it from %s", ErrStateRegression, g.livePath(), err, g.BackupPath())
+	}
+	if state.Less(g.saved) {
+		return fmt.Errorf("%w: %s is at %s but was at %s, restore it from %s", ErrStateRegression, g.livePath(), state, g.saved, g.BackupPath())
+	}
+	return nil
+}
+
+// writeFileSync writes data to path and flushes it to disk
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,27 +1,24 @@
 package bash
 
 import (
-	"bytes"
-	"fmt"
-	"os"
-	"os/exec"
-)
-
-func RunCommand(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+	"errors"
 
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	"github.com/pterm/pterm"
 
-	cmd.Env = os.Environ()
+	"github.com/sSelmann/storycli/utils/executor"
+)
 
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("\n✖ Error executing command: %s %v\n", name, args)
-		fmt.Println("Error:", err)
-		fmt.Println("Stdout:", stdout.String())
-		fmt.Println("Stderr:", stderr.String())
+// RunCommand runs a command with the active executor and prints its
+// output through pterm if it fails, so it never mixes into JSON output.
+func RunCommand(name string, args ...string) error {
+	err := executor.Run(executor.Cmd(name, args...))
+	if err != nil {
+		pterm.Error.Printfln("Error executing command: %s %v: %v", name, args, err)
+		var cmdErr *executor.CommandError
+		if errors.As(err, &cmdErr) {
+			pterm.Println("Stdout:", cmdErr.Stdout)
+			pterm.Println("Stderr:", cmdErr.Stderr)
+		}
 		return err
 	}
 
@@ -0,0 +1,46 @@
+package bash
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+func TestRunCommandFailureSkipsStdout(t *testing.T) {
+	rec := executor.NewRecorder()
+	rec.Fail("sudo apt install curl -y", errors.New("exit status 100"))
+	active := executor.Active()
+	executor.SetActive(rec)
+	t.Cleanup(func() { executor.SetActive(active) })
+
+	var out bytes.Buffer
+	pterm.SetDefaultOutput(&out)
+	t.Cleanup(func() { pterm.SetDefaultOutput(os.Stdout) })
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = RunCommand("sudo", "apt", "install", "curl", "-y")
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(printed) != 0 {
+		t.Errorf("printed %q to stdout", printed)
+	}
+	if !strings.Contains(out.String(), "exit status 100") {
+		t.Errorf("pterm output %q doesn't mention the error", out.String())
+	}
+}
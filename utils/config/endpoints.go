@@ -2,8 +2,15 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
 )
 
 // ItrocketEndpoints holds the URLs for Itrocket provider based on pruning mode.
@@ -19,6 +26,41 @@ type Endpoints struct {
 	Jnode    string
 }
 
+// EndpointSource tells where an endpoint was resolved from.
+type EndpointSource string
+
+const (
+	SourceOverride EndpointSource = "override file"
+	SourceAPI      EndpointSource = "endpoint API"
+	SourceBundled  EndpointSource = "bundled defaults"
+	SourceNone     EndpointSource = "none"
+)
+
+// EndpointReport describes how ResolveEndpoints arrived at its result.
+type EndpointReport struct {
+	ItrocketPruned  EndpointSource
+	ItrocketArchive EndpointSource
+	Krews           EndpointSource
+	Jnode           EndpointSource
+	// Failures lists every source that was tried and failed, in order.
+	Failures []error
+}
+
+// endpointOverrides is the format of ~/.config/storycli/endpoints.toml.
+// Every field is optional.
+type endpointOverrides struct {
+	// ItrocketAPI replaces the API that lists the Itrocket servers
+	ItrocketAPI string `toml:"itrocket_api"`
+	// ItrocketRoot is the host serving genesis.json and addrbook.json
+	ItrocketRoot string `toml:"itrocket_root"`
+	Krews        string `toml:"krews"`
+	Jnode        string `toml:"jnode"`
+	Itrocket     struct {
+		Pruned  []string `toml:"pruned"`
+		Archive []string `toml:"archive"`
+	} `toml:"itrocket"`
+}
+
 type itrocketAPIResponse struct {
 	Archive map[string]string `json:"archive"`
 	Pruned  map[string]string `json:"pruned"`
@@ -26,87 +68,196 @@ type itrocketAPIResponse struct {
 
 var itrocketApiUrl = "https://snapshot-external-providers-api.krews.xyz/snapshots/itrocket"
 
-// fetchItrocketEndpointsFromAPI fetches the dynamic endpoints from the external API
-// and converts them to ItrocketEndpoints (pruned + archive URLs).
-func fetchItrocketEndpointsFromAPI() (ItrocketEndpoints, error) {
-	var result ItrocketEndpoints
+// Bundled defaults, used when neither an override nor the endpoint API
+// provides a value
+const (
+	bundledItrocketRoot = "server-3.itrocket.net"
+	bundledKrews        = "https://snapshots-api.krews.xyz/api/snapshots/story"
+	bundledJnode        = "https://snapshot-external-providers-api.krews.xyz/snapshots/jnode"
+)
+
+// endpointAPITimeout bounds the request to the Itrocket endpoint API
+const endpointAPITimeout = 10 * time.Second
 
-	apiURL := itrocketApiUrl
-	resp, err := http.Get(apiURL)
+// EndpointsFilePath returns the path of the endpoint override file,
+// ~/.config/storycli/endpoints.toml.
+func EndpointsFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
 	if err != nil {
-		return result, fmt.Errorf("failed to GET Itrocket endpoint API: %v", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	return filepath.Join(dir, "storycli", "endpoints.toml"), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf("got non-OK status code %d from Itrocket endpoint API", resp.StatusCode)
+// loadEndpointOverrides reads the override file. A missing file is not an
+// error.
+func loadEndpointOverrides() (endpointOverrides, error) {
+	var overrides endpointOverrides
+
+	path, err := EndpointsFilePath()
+	if err != nil {
+		return overrides, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return overrides, nil
+	}
+	if err != nil {
+		return overrides, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := toml.Unmarshal(data, &overrides); err != nil {
+		return endpointOverrides{}, fmt.Errorf("failed to parse %s: %v", path, err)
 	}
+	return overrides, nil
+}
 
-	var apiResp itrocketAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return result, fmt.Errorf("failed to decode Itrocket endpoint API response: %v", err)
+// ResolveEndpoints resolves the provider endpoints for network. Each
+// endpoint is taken from the override file if it sets one, then from the
+// endpoint API, and finally from the bundled defaults. It never fails; the
+// report says where each endpoint came from and which sources could not be
+// used.
+func ResolveEndpoints(network Network) (Endpoints, EndpointReport) {
+	var endpoints Endpoints
+	var report EndpointReport
+
+	overrides, err := loadEndpointOverrides()
+	if err != nil {
+		report.Failures = append(report.Failures, err)
 	}
 
-	// Convert the map data (e.g. "server-3.itrocket.net") to full URLs.
-	// For pruned:
-	prunedList := []string{}
-	for _, endpoint := range apiResp.Pruned {
-		// e.g. "server-3.itrocket.net" => "https://server-3.itrocket.net/testnet/story/.current_state.json"
-		fullURL := fmt.Sprintf("https://%s/testnet/story/.current_state.json", endpoint)
-		prunedList = append(prunedList, fullURL)
+	// Itrocket, each pruning mode on its own, so a mode the override file
+	// leaves out still comes from the API or the bundled defaults
+	var fromAPI ItrocketEndpoints
+	if len(overrides.Itrocket.Pruned) == 0 || len(overrides.Itrocket.Archive) == 0 {
+		apiResp, err := fetchItrocketAPI(overrides.apiURL())
+		if err == nil {
+			fromAPI = apiResp.endpoints(network)
+		} else {
+			report.Failures = append(report.Failures, err)
+		}
 	}
+	bundled := bundledItrocket(network)
+	endpoints.Itrocket.Pruned, report.ItrocketPruned = firstEndpoints(overrides.Itrocket.Pruned, fromAPI.Pruned, bundled.Pruned)
+	endpoints.Itrocket.Archive, report.ItrocketArchive = firstEndpoints(overrides.Itrocket.Archive, fromAPI.Archive, bundled.Archive)
 
-	// For archive:
-	archiveList := []string{}
-	for _, endpoint := range apiResp.Archive {
-		fullURL := fmt.Sprintf("https://%s/testnet/story/.current_state.json", endpoint)
-		archiveList = append(archiveList, fullURL)
+	// Krews and Jnode are not served by the endpoint API
+	endpoints.Krews, report.Krews = bundledKrews, SourceBundled
+	if overrides.Krews != "" {
+		endpoints.Krews, report.Krews = overrides.Krews, SourceOverride
+	}
+	endpoints.Jnode, report.Jnode = bundledJnode, SourceBundled
+	if overrides.Jnode != "" {
+		endpoints.Jnode, report.Jnode = overrides.Jnode, SourceOverride
 	}
 
-	result = ItrocketEndpoints{
-		Pruned:  prunedList,
-		Archive: archiveList,
+	return endpoints, report
+}
+
+// firstEndpoints returns the first of the override, API and bundled lists
+// that isn't empty, with where it came from
+func firstEndpoints(override, api, bundled []string) ([]string, EndpointSource) {
+	switch {
+	case len(override) > 0:
+		return override, SourceOverride
+	case len(api) > 0:
+		return api, SourceAPI
+	case len(bundled) > 0:
+		return bundled, SourceBundled
+	}
+	return nil, SourceNone
+}
+
+// bundledItrocket returns the Itrocket servers known without the endpoint
+// API. Itrocket has no fixed archive server, so archive snapshots need the
+// API or an override.
+func bundledItrocket(network Network) ItrocketEndpoints {
+	var endpoints ItrocketEndpoints
+	if url := network.ItrocketStateURL(bundledItrocketRoot); url != "" {
+		endpoints.Pruned = []string{url}
+	}
+	return endpoints
+}
+
+// ResolveItrocketRootEndpoint returns the Itrocket host serving genesis.json
+// and addrbook.json, trying the override file, the endpoint API and the
+// bundled default in that order. Failed sources are returned alongside.
+func ResolveItrocketRootEndpoint() (string, []error) {
+	var failures []error
+
+	overrides, err := loadEndpointOverrides()
+	if err != nil {
+		failures = append(failures, err)
+	}
+	if overrides.ItrocketRoot != "" {
+		return overrides.ItrocketRoot, failures
+	}
+
+	apiResp, err := fetchItrocketAPI(overrides.apiURL())
+	if err == nil {
+		if endpoint, ok := apiResp.Pruned["endpoint-1"]; ok {
+			return endpoint, failures
+		}
+		err = errors.New("root endpoint not found in Itrocket API response")
+	}
+	failures = append(failures, err)
+	return bundledItrocketRoot, failures
+}
+
+func (o endpointOverrides) apiURL() string {
+	if o.ItrocketAPI != "" {
+		return o.ItrocketAPI
 	}
-	return result, nil
+	return itrocketApiUrl
 }
 
-func FetchItrocketRootEndpointFromAPI() (string, error) {
-	apiURL := itrocketApiUrl
+// fetchItrocketAPI fetches the list of Itrocket servers
+func fetchItrocketAPI(apiURL string) (itrocketAPIResponse, error) {
+	var apiResp itrocketAPIResponse
 
-	resp, err := http.Get(apiURL)
+	client := &http.Client{Timeout: endpointAPITimeout}
+	resp, err := client.Get(apiURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to GET Itrocket endpoint API: %v", err)
+		return apiResp, fmt.Errorf("failed to GET Itrocket endpoint API: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("got non-OK status code %d from Itrocket endpoint API", resp.StatusCode)
+		return apiResp, fmt.Errorf("got non-OK status code %d from Itrocket endpoint API", resp.StatusCode)
 	}
 
-	var apiResp itrocketAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("failed to decode Itrocket endpoint API response: %v", err)
+		return apiResp, fmt.Errorf("failed to decode Itrocket endpoint API response: %v", err)
 	}
-
-	if endpoint, ok := apiResp.Pruned["endpoint-1"]; ok {
-		return endpoint, nil
+	if len(apiResp.Pruned) == 0 && len(apiResp.Archive) == 0 {
+		return apiResp, errors.New("Itrocket endpoint API returned no servers")
 	}
+	return apiResp, nil
+}
 
-	return "", fmt.Errorf("root endpoint not found in Itrocket API response")
+// endpoints converts the server hosts (e.g. "server-3.itrocket.net") to
+// full state URLs for network
+func (r itrocketAPIResponse) endpoints(network Network) ItrocketEndpoints {
+	return ItrocketEndpoints{
+		Pruned:  itrocketStateURLs(network, r.Pruned),
+		Archive: itrocketStateURLs(network, r.Archive),
+	}
 }
 
-// DefaultEndpoints returns the default API endpoints.
-// It dynamically fetches Itrocket endpoints via fetchItrocketEndpointsFromAPI,
-// and if there's a failure, it panics (no fallback).
-func DefaultEndpoints() Endpoints {
-	dynamicItrocket, err := fetchItrocketEndpointsFromAPI()
-	if err != nil {
-		panic(fmt.Sprintf("Failed to fetch Itrocket endpoints from API: %v", err))
+func itrocketStateURLs(network Network, hosts map[string]string) []string {
+	if network.ItrocketChain == "" {
+		return nil
+	}
+
+	// Sort by key so the order is stable
+	keys := make([]string, 0, len(hosts))
+	for key := range hosts {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return Endpoints{
-		Itrocket: dynamicItrocket,
-		Krews:    "https://snapshots-api.krews.xyz/api/snapshots/story",
-		Jnode:    "https://snapshot-external-providers-api.krews.xyz/snapshots/jnode",
+	urls := make([]string, 0, len(keys))
+	for _, key := range keys {
+		urls = append(urls, network.ItrocketStateURL(hosts[key]))
 	}
+	return urls
 }
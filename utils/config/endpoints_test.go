@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveEndpointsItrocket(t *testing.T) {
+	network, err := LookupNetwork("odyssey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"pruned": {"endpoint-1": "pruned.api.test"}, "archive": {"endpoint-1": "archive.api.test"}}`)
+	}))
+	defer api.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	var (
+		prunedAPI  = network.ItrocketStateURL("pruned.api.test")
+		archiveAPI = network.ItrocketStateURL("archive.api.test")
+		bundled    = network.ItrocketStateURL(bundledItrocketRoot)
+	)
+	tests := []struct {
+		name string
+		// overrides is added to endpoints.toml after itrocket_api = api
+		overrides   string
+		api         string
+		wantPruned  []string
+		wantArchive []string
+		wantReport  [2]EndpointSource
+	}{
+		{
+			name:        "from the API",
+			api:         api.URL,
+			wantPruned:  []string{prunedAPI},
+			wantArchive: []string{archiveAPI},
+			wantReport:  [2]EndpointSource{SourceAPI, SourceAPI},
+		},
+		{
+			name:        "pruned override keeps archive from the API",
+			overrides:   "[itrocket]\npruned = [\"https://mirror.test/p\"]\n",
+			api:         api.URL,
+			wantPruned:  []string{"https://mirror.test/p"},
+			wantArchive: []string{archiveAPI},
+			wantReport:  [2]EndpointSource{SourceOverride, SourceAPI},
+		},
+		{
+			name:        "archive override keeps pruned from the bundled defaults",
+			overrides:   "[itrocket]\narchive = [\"https://mirror.test/a\"]\n",
+			api:         down.URL,
+			wantPruned:  []string{bundled},
+			wantArchive: []string{"https://mirror.test/a"},
+			wantReport:  [2]EndpointSource{SourceBundled, SourceOverride},
+		},
+		{
+			name:       "bundled defaults without the API",
+			api:        down.URL,
+			wantPruned: []string{bundled},
+			wantReport: [2]EndpointSource{SourceBundled, SourceNone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			path, err := EndpointsFilePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			overrides := fmt.Sprintf("itrocket_api = %q\n", tt.api) + tt.overrides
+			if err := os.WriteFile(path, []byte(overrides), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			endpoints, report := ResolveEndpoints(network)
+			if got := strings.Join(endpoints.Itrocket.Pruned, " "); got != strings.Join(tt.wantPruned, " ") {
+				t.Errorf("pruned = %q, want %q", endpoints.Itrocket.Pruned, tt.wantPruned)
+			}
+			if got := strings.Join(endpoints.Itrocket.Archive, " "); got != strings.Join(tt.wantArchive, " ") {
+				t.Errorf("archive = %q, want %q", endpoints.Itrocket.Archive, tt.wantArchive)
+			}
+			if got := [2]EndpointSource{report.ItrocketPruned, report.ItrocketArchive}; got != tt.wantReport {
+				t.Errorf("sources = %q, want %q", got, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestActiveProfileWithoutHome(t *testing.T) {
+	active := activeProfile
+	activeProfile = nil
+	t.Cleanup(func() { activeProfile = active })
+	t.Setenv("HOME", "")
+
+	if _, err := ActiveProfile(); err == nil {
+		t.Error("expected an error without a home directory")
+	}
+}
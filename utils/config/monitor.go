@@ -0,0 +1,109 @@
+package config
+
+import (
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "5m" in the
+// config file.
+type Duration time.Duration
+
+// UnmarshalText parses a duration such as "90s" or "1h30m".
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats d like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// MonitorConfig is the [monitor] section of the storycli config file. It
+// sets the rules `scli monitor` evaluates and where it sends alerts. A
+// threshold of 0 turns its rule off.
+type MonitorConfig struct {
+	// Interval is the time between checks.
+	Interval Duration `toml:"interval"`
+	// Repeat resends alerts that are still firing this often; 0 sends
+	// every alert once until it resolves.
+	Repeat Duration `toml:"repeat"`
+
+	// StalledAfter is how long a height may stay the same before the node
+	// counts as stalled.
+	StalledAfter Duration `toml:"stalled_after"`
+	// MaxBlockLag is how many blocks the node may be behind the reference
+	// RPC.
+	MaxBlockLag int64 `toml:"max_block_lag"`
+	// MaxMissedBlocks is how many of the last 100 blocks the validator may
+	// miss.
+	MaxMissedBlocks int `toml:"max_missed_blocks"`
+	// ExpectValidator alerts when the node has no voting power, e.g. when
+	// it is jailed, even if it had none when the monitor started.
+	ExpectValidator bool `toml:"expect_validator"`
+	// MinPeers and MinGethPeers are the fewest consensus and execution
+	// peers the node may have.
+	MinPeers     int `toml:"min_peers"`
+	MinGethPeers int `toml:"min_geth_peers"`
+	// MaxDiskUsedPercent is how full the file system of the node home may
+	// get.
+	MaxDiskUsedPercent float64 `toml:"max_disk_used_percent"`
+
+	Notifiers []NotifierConfig `toml:"notifiers"`
+}
+
+// NotifierConfig is one [[monitor.notifiers]] entry. Type selects which of
+// the other fields are used.
+type NotifierConfig struct {
+	// Type is webhook, telegram or smtp.
+	Type string `toml:"type"`
+	// Name tells notifiers of the same type apart in messages; the type if
+	// empty.
+	Name string `toml:"name"`
+
+	// URL receives the alerts as JSON (webhook).
+	URL     string            `toml:"url"`
+	Headers map[string]string `toml:"headers"`
+
+	// BotToken and ChatID address a Telegram chat; APIURL replaces
+	// https://api.telegram.org, e.g. for a local Bot API server (telegram).
+	BotToken string `toml:"bot_token"`
+	ChatID   string `toml:"chat_id"`
+	APIURL   string `toml:"api_url"`
+
+	// Host and Port are the SMTP server; port 465 uses implicit TLS,
+	// other ports STARTTLS when the server offers it (smtp).
+	Host     string   `toml:"host"`
+	Port     int      `toml:"port"`
+	Username string   `toml:"username"`
+	Password string   `toml:"password"`
+	From     string   `toml:"from"`
+	To       []string `toml:"to"`
+}
+
+// DefaultMonitorConfig returns the rules used where the [monitor] section
+// leaves them out.
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Interval:           Duration(time.Minute),
+		StalledAfter:       Duration(5 * time.Minute),
+		MaxBlockLag:        100,
+		MaxMissedBlocks:    10,
+		MinPeers:           3,
+		MaxDiskUsedPercent: 90,
+	}
+}
+
+// LoadMonitorConfig returns the [monitor] section of the config file.
+// Settings it leaves out keep their defaults.
+func LoadMonitorConfig() (MonitorConfig, error) {
+	cfg, _, err := loadStorycliConfig()
+	if err != nil {
+		return MonitorConfig{}, err
+	}
+	return cfg.Monitor, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Network describes a Story network: how the binaries are told to use it,
+// where its data lives and where its genesis, peers and snapshots come from.
+type Network struct {
+	// Name is the name used by storycli, e.g. "aeneid".
+	Name string
+	// StoryNetwork is the value of `story init --network`.
+	StoryNetwork string
+	// ChainID is the EVM chain id.
+	ChainID string
+
+	// GethFlag selects the network in story-geth, e.g. "--aeneid".
+	GethFlag string
+	// GethDataSubdir is the directory under <home>/geth that story-geth
+	// keeps the network's data in.
+	GethDataSubdir string
+
+	// ItrocketChain is the path of the network on Itrocket servers
+	// ("testnet" or "mainnet"). Genesis, addrbook and snapshots are served
+	// below https://<server>/<chain>/story/. Empty if Itrocket doesn't
+	// serve the network.
+	ItrocketChain string
+	// Seeds is the seeds setting for config.toml; empty keeps the seeds
+	// written by `story init`.
+	Seeds string
+	// PeersRPC is an RPC whose net_info is used to fill persistent_peers.
+	// Empty keeps the peers written by `story init`.
+	PeersRPC string
+
+	// KrewsSnapshot is the Krews snapshot name with a %s for the pruning
+	// mode. Empty if Krews has no snapshots for the network.
+	KrewsSnapshot string
+	// Jnode reports whether the Jnode snapshot API serves the network.
+	Jnode bool
+
+	// VersionsAPI returns the recommended story and geth versions. Empty
+	// means the latest GitHub releases are used.
+	VersionsAPI string
+}
+
+// Networks is the catalogue of known Story networks.
+var Networks = []Network{
+	{
+		Name:           "odyssey",
+		StoryNetwork:   "odyssey",
+		ChainID:        "1516",
+		GethFlag:       "--odyssey",
+		GethDataSubdir: "odyssey",
+		ItrocketChain:  "testnet",
+		Seeds:          "51ff395354c13fab493a03268249a74860b5f9cc@story-testnet-seed.itrocket.net:26656",
+		PeersRPC:       "https://story-testnet-rpc.itrocket.net",
+		KrewsSnapshot:  "story_testnet_%s_snapshot",
+		Jnode:          true,
+		VersionsAPI:    "https://snapshot-external-providers-api.krews.xyz/story/latest_versions",
+	},
+	{
+		Name:           "aeneid",
+		StoryNetwork:   "aeneid",
+		ChainID:        "1315",
+		GethFlag:       "--aeneid",
+		GethDataSubdir: "aeneid",
+	},
+	{
+		Name:           "mainnet",
+		StoryNetwork:   "story",
+		ChainID:        "1514",
+		GethFlag:       "--story",
+		GethDataSubdir: "story",
+		ItrocketChain:  "mainnet",
+		PeersRPC:       "https://story-mainnet-rpc.itrocket.net",
+	},
+}
+
+// DefaultNetworkName is the network used when a profile doesn't name one.
+const DefaultNetworkName = "odyssey"
+
+// LookupNetwork returns the catalogue entry for name. The name story uses
+// for a network (e.g. "story" for mainnet) is accepted as well.
+func LookupNetwork(name string) (Network, error) {
+	for _, n := range Networks {
+		if strings.EqualFold(n.Name, name) || strings.EqualFold(n.StoryNetwork, name) {
+			return n, nil
+		}
+	}
+	return Network{}, fmt.Errorf("unknown network %q (known networks: %s)", name, strings.Join(NetworkNames(), ", "))
+}
+
+// NetworkNames returns the names of the known networks.
+func NetworkNames() []string {
+	names := make([]string, 0, len(Networks))
+	for _, n := range Networks {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// GenesisURL returns the URL of genesis.json on the Itrocket server host,
+// or "" if Itrocket doesn't serve the network.
+func (n Network) GenesisURL(host string) string {
+	return n.itrocketURL(host, "genesis.json")
+}
+
+// AddrbookURL returns the URL of addrbook.json on the Itrocket server host,
+// or "" if Itrocket doesn't serve the network.
+func (n Network) AddrbookURL(host string) string {
+	return n.itrocketURL(host, "addrbook.json")
+}
+
+// ItrocketStateURL returns the URL of the snapshot state on the Itrocket
+// server host, e.g.
+// "https://server-3.itrocket.net/testnet/story/.current_state.json", or ""
+// if Itrocket doesn't serve the network.
+func (n Network) ItrocketStateURL(host string) string {
+	return n.itrocketURL(host, ".current_state.json")
+}
+
+func (n Network) itrocketURL(host, name string) string {
+	if n.ItrocketChain == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/story/%s", host, n.ItrocketChain, name)
+}
@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+func TestNetworkURLsAreUnique(t *testing.T) {
+	urls := map[string]func(n Network, host string) string{
+		"genesis":  Network.GenesisURL,
+		"addrbook": Network.AddrbookURL,
+		"state":    Network.ItrocketStateURL,
+	}
+	for kind, url := range urls {
+		seen := map[string]string{}
+		for _, n := range Networks {
+			u := url(n, bundledItrocketRoot)
+			if u == "" {
+				continue
+			}
+			if other, ok := seen[u]; ok {
+				t.Errorf("%s and %s share the %s URL %s", other, n.Name, kind, u)
+			}
+			seen[u] = n.Name
+		}
+	}
+}
+
+func TestNetworkPeersAreUnique(t *testing.T) {
+	seeds := map[string]string{}
+	rpcs := map[string]string{}
+	for _, n := range Networks {
+		if other, ok := seeds[n.Seeds]; ok && n.Seeds != "" {
+			t.Errorf("%s and %s share the seeds %s", other, n.Name, n.Seeds)
+		}
+		seeds[n.Seeds] = n.Name
+		if other, ok := rpcs[n.PeersRPC]; ok && n.PeersRPC != "" {
+			t.Errorf("%s and %s share the peers RPC %s", other, n.Name, n.PeersRPC)
+		}
+		rpcs[n.PeersRPC] = n.Name
+	}
+}
+
+func TestNetworkWithoutItrocket(t *testing.T) {
+	n := Network{Name: "devnet"}
+	for name, u := range map[string]string{
+		"GenesisURL":       n.GenesisURL("host"),
+		"AddrbookURL":      n.AddrbookURL("host"),
+		"ItrocketStateURL": n.ItrocketStateURL("host"),
+	} {
+		if u != "" {
+			t.Errorf("%s = %q, want none", name, u)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// DefaultProfileName is used when neither --profile nor the config file
+// names a profile.
+const DefaultProfileName = "default"
+
+// ProfileEnv selects the profile when --profile is not given.
+const ProfileEnv = "STORYCLI_PROFILE"
+
+// Profile describes one node on this machine: where it lives, how its
+// services are called and which network it runs.
+type Profile struct {
+	Name string `toml:"-"`
+
+	// HomeDir is the node home holding story/ and geth/, e.g. ~/.story.
+	HomeDir string `toml:"home_dir"`
+	// BinDir is where the story and geth binaries are installed.
+	BinDir string `toml:"bin_dir"`
+
+	StoryService string `toml:"story_service"`
+	GethService  string `toml:"geth_service"`
+	// ServiceManager runs the services: systemd, systemd-user, docker,
+	// podman or process.
+	ServiceManager string `toml:"service_manager"`
+	// ContainerImage is the image the docker and podman service managers
+	// run the binaries in.
+	ContainerImage string `toml:"container_image"`
+
+	Network string `toml:"network"`
+	ChainID string `toml:"chain_id"`
+
+	// RPCPort is the CometBFT RPC port, GethRPCPort the geth HTTP RPC port.
+	RPCPort     int `toml:"rpc_port"`
+	GethRPCPort int `toml:"geth_rpc_port"`
+}
+
+// StoryHome returns the story home directory (config and data).
+func (p Profile) StoryHome() string {
+	return filepath.Join(p.HomeDir, "story")
+}
+
+// ConfigDir returns the directory holding story.toml and config.toml.
+func (p Profile) ConfigDir() string {
+	return filepath.Join(p.StoryHome(), "config")
+}
+
+// GethDataSubdir returns the directory under <home>/geth that holds the
+// data of the profile's network.
+func (p Profile) GethDataSubdir() string {
+	if n, err := LookupNetwork(p.Network); err == nil {
+		return n.GethDataSubdir
+	}
+	return p.Network
+}
+
+// GethDataDir returns the geth data directory for the profile's network,
+// e.g. ~/.story/geth/odyssey.
+func (p Profile) GethDataDir() string {
+	return filepath.Join(p.HomeDir, "geth", p.GethDataSubdir())
+}
+
+// Binary returns the path of an installed binary, e.g. Binary("story").
+func (p Profile) Binary(name string) string {
+	return filepath.Join(p.BinDir, name)
+}
+
+// Services returns the story and geth service names.
+func (p Profile) Services() []string {
+	return []string{p.StoryService, p.GethService}
+}
+
+// WithNetwork returns a copy of p set up for network, e.g. from a --network
+// flag. The chain-id follows the network.
+func (p Profile) WithNetwork(network Network) Profile {
+	if p.Network != network.Name {
+		p.Network = network.Name
+		p.ChainID = network.ChainID
+	}
+	return p
+}
+
+// storycliConfig is the format of ~/.config/storycli/config.toml.
+type storycliConfig struct {
+	DefaultProfile string             `toml:"default_profile"`
+	Profiles       map[string]Profile `toml:"profiles"`
+	Monitor        MonitorConfig      `toml:"monitor"`
+}
+
+// ConfigFilePath returns the path of the storycli config file,
+// ~/.config/storycli/config.toml.
+func ConfigFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "storycli", "config.toml"), nil
+}
+
+// DefaultProfile returns the built-in profile, which matches the layout
+// created by `scli setup node` on an odyssey node.
+func DefaultProfile() (Profile, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	network, err := LookupNetwork(DefaultNetworkName)
+	if err != nil {
+		return Profile{}, err
+	}
+	return Profile{
+		Name:           DefaultProfileName,
+		HomeDir:        filepath.Join(home, ".story"),
+		BinDir:         filepath.Join(home, "go", "bin"),
+		StoryService:   "story",
+		GethService:    "story-geth",
+		ServiceManager: "systemd",
+		ContainerImage: "ubuntu:24.04",
+		Network:        network.Name,
+		ChainID:        network.ChainID,
+		RPCPort:        26657,
+		GethRPCPort:    8545,
+	}, nil
+}
+
+func loadStorycliConfig() (storycliConfig, string, error) {
+	// Settings the file leaves out keep these values
+	cfg := storycliConfig{Monitor: DefaultMonitorConfig()}
+
+	path, err := ConfigFilePath()
+	if err != nil {
+		return cfg, "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, path, nil
+	}
+	if err != nil {
+		return cfg, path, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return storycliConfig{}, path, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return cfg, path, nil
+}
+
+// LoadProfile returns the named profile from the config file. An empty name
+// selects $STORYCLI_PROFILE, then default_profile from the file, then the
+// built-in default. Fields a profile leaves out keep their default values.
+func LoadProfile(name string) (Profile, error) {
+	defaults, err := DefaultProfile()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	cfg, path, err := loadStorycliConfig()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		if name != DefaultProfileName {
+			return Profile{}, fmt.Errorf("unknown profile %q (profiles in %s: %s)", name, path, strings.Join(ProfileNamesIn(cfg.Profiles), ", "))
+		}
+		return defaults, nil
+	}
+
+	profile.Name = name
+	profile.fillDefaults(defaults)
+	if err := profile.expandPaths(); err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of the profiles in the config file.
+func ProfileNames() ([]string, error) {
+	cfg, _, err := loadStorycliConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ProfileNamesIn(cfg.Profiles), nil
+}
+
+// ProfileNamesIn returns the sorted keys of profiles, always including the
+// built-in default.
+func ProfileNamesIn(profiles map[string]Profile) []string {
+	names := []string{DefaultProfileName}
+	for name := range profiles {
+		if name != DefaultProfileName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+func (p *Profile) fillDefaults(d Profile) {
+	if p.HomeDir == "" {
+		p.HomeDir = d.HomeDir
+	}
+	if p.BinDir == "" {
+		p.BinDir = d.BinDir
+	}
+	if p.StoryService == "" {
+		p.StoryService = d.StoryService
+	}
+	if p.GethService == "" {
+		p.GethService = d.GethService
+	}
+	if p.ServiceManager == "" {
+		p.ServiceManager = d.ServiceManager
+	}
+	if p.ContainerImage == "" {
+		p.ContainerImage = d.ContainerImage
+	}
+	if p.Network == "" {
+		p.Network = d.Network
+	}
+	if p.ChainID == "" {
+		if n, err := LookupNetwork(p.Network); err == nil {
+			p.ChainID = n.ChainID
+		}
+	}
+	if p.RPCPort == 0 {
+		p.RPCPort = d.RPCPort
+	}
+	if p.GethRPCPort == 0 {
+		p.GethRPCPort = d.GethRPCPort
+	}
+}
+
+// expandPaths resolves a leading ~ in the directory settings
+func (p *Profile) expandPaths() error {
+	var err error
+	if p.HomeDir, err = ExpandHome(p.HomeDir); err != nil {
+		return err
+	}
+	p.BinDir, err = ExpandHome(p.BinDir)
+	return err
+}
+
+// ExpandHome replaces a leading "~" in path with the user home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+var activeProfile *Profile
+
+// SetActiveProfile makes p the profile returned by ActiveProfile.
+func SetActiveProfile(p Profile) {
+	activeProfile = &p
+}
+
+// ActiveProfile returns the profile selected for this run, falling back to
+// the built-in default if none was set, e.g. during shell completion.
+func ActiveProfile() (Profile, error) {
+	if activeProfile != nil {
+		return *activeProfile, nil
+	}
+	return DefaultProfile()
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NodeConfigFile identifies one of the Story node configuration files.
+type NodeConfigFile string
+
+const (
+	// StoryToml is story.toml, the Story consensus client config.
+	StoryToml NodeConfigFile = "story"
+	// CometToml is config.toml, the CometBFT config.
+	CometToml NodeConfigFile = "config"
+)
+
+// FileName returns the file name of the configuration file.
+func (f NodeConfigFile) FileName() string {
+	return string(f) + ".toml"
+}
+
+// ParseNodeConfigFile parses "story", "config", "story.toml" or "config.toml".
+func ParseNodeConfigFile(s string) (NodeConfigFile, error) {
+	switch strings.TrimSuffix(strings.ToLower(s), ".toml") {
+	case "story":
+		return StoryToml, nil
+	case "config", "comet", "cometbft":
+		return CometToml, nil
+	default:
+		return "", fmt.Errorf("unknown config file %q (expected story or config)", s)
+	}
+}
+
+// ValueType is the TOML type of a configuration value.
+type ValueType string
+
+const (
+	TypeString   ValueType = "string"
+	TypeInt      ValueType = "int"
+	TypeBool     ValueType = "bool"
+	TypeDuration ValueType = "duration" // stored as a string such as "1s"
+	TypeStrings  ValueType = "[]string" // comma separated on the command line
+)
+
+// ConfigKey describes a single key in story.toml or config.toml.
+type ConfigKey struct {
+	File        NodeConfigFile
+	Key         string // dotted path inside the file, e.g. "p2p.max_num_inbound_peers"
+	Type        ValueType
+	Allowed     []string // optional list of allowed values
+	Alias       string   // legacy `scli set/show` subcommand name
+	Description string
+}
+
+// ID returns the key prefixed with its file, e.g. "config:p2p.seeds".
+func (k ConfigKey) ID() string {
+	return string(k.File) + ":" + k.Key
+}
+
+// Parse converts a command line value into a typed value for the key,
+// validating it against the schema.
+func (k ConfigKey) Parse(input string) (interface{}, error) {
+	if len(k.Allowed) > 0 {
+		valid := false
+		for _, v := range k.Allowed {
+			if input == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid %s value: %s. Allowed values are: %v", k.Key, input, k.Allowed)
+		}
+	}
+
+	switch k.Type {
+	case TypeInt:
+		v, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %s. Must be an integer", k.Key, input)
+		}
+		return v, nil
+	case TypeBool:
+		v, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %s. Must be true or false", k.Key, input)
+		}
+		return v, nil
+	case TypeDuration:
+		if _, err := time.ParseDuration(input); err != nil {
+			return nil, fmt.Errorf("invalid %s value: %s. Must be a duration such as 500ms or 10s", k.Key, input)
+		}
+		return input, nil
+	case TypeStrings:
+		var items []string
+		for _, item := range strings.Split(input, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return input, nil
+	}
+}
+
+// InferValueType guesses the type of a key that is not in the schema from
+// its raw TOML value.
+func InferValueType(raw string) ValueType {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "true" || raw == "false":
+		return TypeBool
+	case strings.HasPrefix(raw, "["):
+		return TypeStrings
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		return TypeString
+	}
+	if _, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64); err == nil {
+		return TypeInt
+	}
+	return TypeString
+}
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"plain", "json"}
+	dbBackends = []string{"goleveldb", "cleveldb", "boltdb", "rocksdb", "badgerdb", "pebbledb"}
+)
+
+// NodeConfigSchema lists the known keys of story.toml and config.toml.
+var NodeConfigSchema = []ConfigKey{
+	// story.toml
+	{File: StoryToml, Key: "pruning", Type: TypeString, Allowed: []string{"default", "nothing", "everything", "custom"}, Alias: "pruning-mode", Description: "Pruning strategy of the application state"},
+	{File: StoryToml, Key: "snapshot-interval", Type: TypeInt, Alias: "snapshot-interval", Description: "Block interval at which state sync snapshots are taken"},
+	{File: StoryToml, Key: "snapshot-keep-recent", Type: TypeInt, Alias: "snapshot-keep-recent", Description: "Number of recent state sync snapshots to keep"},
+	{File: StoryToml, Key: "min-retain-blocks", Type: TypeInt, Alias: "min-retain-blocks", Description: "Minimum block height offset during ABCI commit to prune CometBFT blocks"},
+	{File: StoryToml, Key: "app-db-backend", Type: TypeString, Allowed: dbBackends, Alias: "app-db-backend", Description: "Database backend of the application"},
+	{File: StoryToml, Key: "evm-build-delay", Type: TypeDuration, Alias: "evm-build-delay", Description: "Minimum delay between EVM payload builds"},
+	{File: StoryToml, Key: "evm-build-optimistic", Type: TypeBool, Alias: "evm-build-optimistic", Description: "Build EVM payloads optimistically"},
+	{File: StoryToml, Key: "api-enable", Type: TypeBool, Alias: "api-enable", Description: "Enable the API server"},
+	{File: StoryToml, Key: "api-address", Type: TypeString, Alias: "api-address", Description: "Listen address of the API server"},
+	{File: StoryToml, Key: "enabled-unsafe-cors", Type: TypeBool, Alias: "enabled-unsafe-cors", Description: "Enable CORS on the API server"},
+	{File: StoryToml, Key: "log.level", Type: TypeString, Allowed: logLevels, Alias: "log-level", Description: "Story log level"},
+	{File: StoryToml, Key: "log.format", Type: TypeString, Allowed: logFormats, Alias: "log-format", Description: "Story log format"},
+	{File: StoryToml, Key: "version", Type: TypeString, Description: "Story config version"},
+	{File: StoryToml, Key: "network", Type: TypeString, Description: "Story network the node participates in"},
+	{File: StoryToml, Key: "engine-endpoint", Type: TypeString, Description: "Execution client Engine API endpoint"},
+	{File: StoryToml, Key: "engine-jwt-file", Type: TypeString, Description: "Execution client Engine API JWT file"},
+
+	// config.toml base
+	{File: CometToml, Key: "version", Type: TypeString, Alias: "config-version", Description: "CometBFT config version"},
+	{File: CometToml, Key: "proxy_app", Type: TypeString, Alias: "proxy-app", Description: "ABCI application socket address"},
+	{File: CometToml, Key: "moniker", Type: TypeString, Alias: "moniker", Description: "Node moniker"},
+	{File: CometToml, Key: "db_backend", Type: TypeString, Allowed: dbBackends, Alias: "db-backend", Description: "CometBFT database backend"},
+	{File: CometToml, Key: "db_dir", Type: TypeString, Alias: "db-dir", Description: "Database directory"},
+	{File: CometToml, Key: "log_level", Type: TypeString, Alias: "log-level-config", Description: "CometBFT log level"},
+	{File: CometToml, Key: "log_format", Type: TypeString, Allowed: logFormats, Alias: "log-format-config", Description: "CometBFT log format"},
+	{File: CometToml, Key: "genesis_file", Type: TypeString, Alias: "genesis-file", Description: "Path to the genesis file"},
+	{File: CometToml, Key: "priv_validator_key_file", Type: TypeString, Alias: "priv-validator-key-file", Description: "Path to the validator private key"},
+	{File: CometToml, Key: "priv_validator_state_file", Type: TypeString, Alias: "priv-validator-state-file", Description: "Path to the last sign state of the validator"},
+	{File: CometToml, Key: "node_key_file", Type: TypeString, Alias: "node-key-file", Description: "Path to the node key used for p2p authentication"},
+	{File: CometToml, Key: "abci", Type: TypeString, Allowed: []string{"socket", "grpc"}, Alias: "abci", Description: "ABCI connection mechanism"},
+	{File: CometToml, Key: "filter_peers", Type: TypeBool, Alias: "filter-peers", Description: "Query the ABCI app on connecting to a new peer"},
+
+	// config.toml [rpc]
+	{File: CometToml, Key: "rpc.laddr", Type: TypeString, Alias: "rpc-laddr", Description: "RPC listen address"},
+	{File: CometToml, Key: "rpc.cors_allowed_origins", Type: TypeStrings, Description: "Origins allowed to make cross-domain requests"},
+	{File: CometToml, Key: "rpc.grpc_laddr", Type: TypeString, Alias: "rpc-grpc-laddr", Description: "gRPC listen address"},
+	{File: CometToml, Key: "rpc.grpc_max_open_connections", Type: TypeInt, Alias: "rpc-grpc-max-open-connections", Description: "Maximum number of simultaneous gRPC connections"},
+	{File: CometToml, Key: "rpc.unsafe", Type: TypeBool, Alias: "rpc-unsafe", Description: "Activate unsafe RPC commands"},
+	{File: CometToml, Key: "rpc.max_open_connections", Type: TypeInt, Alias: "rpc-max-open-connections", Description: "Maximum number of simultaneous RPC connections"},
+	{File: CometToml, Key: "rpc.max_subscription_clients", Type: TypeInt, Alias: "rpc-max-subscription-clients", Description: "Maximum number of unique subscribing clients"},
+	{File: CometToml, Key: "rpc.max_subscriptions_per_client", Type: TypeInt, Alias: "rpc-max-subscriptions-per-client", Description: "Maximum number of subscriptions per client"},
+	{File: CometToml, Key: "rpc.experimental_subscription_buffer_size", Type: TypeInt, Alias: "rpc-experimental-subscription-buffer-size", Description: "Events buffered per subscription"},
+	{File: CometToml, Key: "rpc.experimental_websocket_write_buffer_size", Type: TypeInt, Alias: "rpc-experimental-websocket-write-buffer-size", Description: "Websocket write buffer size"},
+	{File: CometToml, Key: "rpc.experimental_close_on_slow_client", Type: TypeBool, Alias: "rpc-experimental-close-on-slow-client", Description: "Close slow websocket clients"},
+	{File: CometToml, Key: "rpc.timeout_broadcast_tx_commit", Type: TypeDuration, Alias: "rpc-timeout-broadcast-tx-commit", Description: "Timeout of broadcast_tx_commit"},
+	{File: CometToml, Key: "rpc.max_request_batch_size", Type: TypeInt, Alias: "rpc-max-request-batch-size", Description: "Maximum number of requests per batch"},
+	{File: CometToml, Key: "rpc.max_body_bytes", Type: TypeInt, Alias: "rpc-max-body-bytes", Description: "Maximum size of a request body"},
+	{File: CometToml, Key: "rpc.max_header_bytes", Type: TypeInt, Alias: "rpc-max-header-bytes", Description: "Maximum size of request headers"},
+	{File: CometToml, Key: "rpc.pprof_laddr", Type: TypeString, Alias: "rpc-pprof-laddr", Description: "pprof listen address"},
+
+	// config.toml [p2p]
+	{File: CometToml, Key: "p2p.laddr", Type: TypeString, Alias: "p2p-laddr", Description: "P2P listen address"},
+	{File: CometToml, Key: "p2p.external_address", Type: TypeString, Alias: "p2p-external-address", Description: "Address advertised to peers"},
+	{File: CometToml, Key: "p2p.seeds", Type: TypeString, Alias: "p2p-seeds", Description: "Comma separated list of seed nodes"},
+	{File: CometToml, Key: "p2p.persistent_peers", Type: TypeString, Alias: "p2p-persistent-peers", Description: "Comma separated list of persistent peers"},
+	{File: CometToml, Key: "p2p.addr_book_file", Type: TypeString, Alias: "p2p-addr-book-file", Description: "Path to the address book"},
+	{File: CometToml, Key: "p2p.addr_book_strict", Type: TypeBool, Alias: "p2p-addr-book-strict", Description: "Only accept routable addresses"},
+	{File: CometToml, Key: "p2p.max_num_inbound_peers", Type: TypeInt, Alias: "p2p-max-num-inbound-peers", Description: "Maximum number of inbound peers"},
+	{File: CometToml, Key: "p2p.max_num_outbound_peers", Type: TypeInt, Alias: "p2p-max-num-outbound-peers", Description: "Maximum number of outbound peers"},
+	{File: CometToml, Key: "p2p.unconditional_peer_ids", Type: TypeString, Alias: "p2p-unconditional-peer-ids", Description: "Peers exempt from the peer limits"},
+	{File: CometToml, Key: "p2p.persistent_peers_max_dial_period", Type: TypeDuration, Alias: "p2p-persistent-peers-max-dial-period", Description: "Maximum pause between dials to persistent peers"},
+	{File: CometToml, Key: "p2p.flush_throttle_timeout", Type: TypeDuration, Alias: "p2p-flush-throttle-timeout", Description: "Time to wait before flushing messages out"},
+	{File: CometToml, Key: "p2p.max_packet_msg_payload_size", Type: TypeInt, Alias: "p2p-max-packet-msg-payload-size", Description: "Maximum size of a message packet payload"},
+	{File: CometToml, Key: "p2p.send_rate", Type: TypeInt, Alias: "p2p-send-rate", Description: "Rate at which packets can be sent, in bytes/second"},
+	{File: CometToml, Key: "p2p.recv_rate", Type: TypeInt, Alias: "p2p-recv-rate", Description: "Rate at which packets can be received, in bytes/second"},
+	{File: CometToml, Key: "p2p.pex", Type: TypeBool, Alias: "p2p-pex", Description: "Enable the peer exchange reactor"},
+	{File: CometToml, Key: "p2p.seed_mode", Type: TypeBool, Alias: "p2p-seed-mode", Description: "Run the node in seed mode"},
+	{File: CometToml, Key: "p2p.private_peer_ids", Type: TypeString, Description: "Peers that are not gossiped"},
+	{File: CometToml, Key: "p2p.allow_duplicate_ip", Type: TypeBool, Alias: "p2p-allow-duplicate-ip", Description: "Allow multiple peers from the same IP"},
+	{File: CometToml, Key: "p2p.handshake_timeout", Type: TypeDuration, Alias: "p2p-handshake-timeout", Description: "Peer handshake timeout"},
+	{File: CometToml, Key: "p2p.dial_timeout", Type: TypeDuration, Alias: "p2p-dial-timeout", Description: "Peer dial timeout"},
+
+	// config.toml [mempool]
+	{File: CometToml, Key: "mempool.type", Type: TypeString, Allowed: []string{"flood", "nop"}, Alias: "mempool-type", Description: "Mempool type"},
+	{File: CometToml, Key: "mempool.recheck", Type: TypeBool, Alias: "mempool-recheck", Description: "Recheck transactions after a block"},
+	{File: CometToml, Key: "mempool.recheck_timeout", Type: TypeDuration, Alias: "mempool-recheck-timeout", Description: "Timeout of rechecking transactions"},
+	{File: CometToml, Key: "mempool.broadcast", Type: TypeBool, Alias: "mempool-broadcast", Description: "Broadcast transactions to peers"},
+	{File: CometToml, Key: "mempool.size", Type: TypeInt, Alias: "mempool-size", Description: "Maximum number of transactions in the mempool"},
+	{File: CometToml, Key: "mempool.max_txs_bytes", Type: TypeInt, Alias: "mempool-max-txs-bytes", Description: "Maximum total size of all transactions"},
+	{File: CometToml, Key: "mempool.cache_size", Type: TypeInt, Alias: "mempool-cache-size", Description: "Size of the transaction cache"},
+	{File: CometToml, Key: "mempool.keep-invalid-txs-in-cache", Type: TypeBool, Alias: "mempool-keep-invalid-txs-in-cache", Description: "Keep invalid transactions in the cache"},
+	{File: CometToml, Key: "mempool.max_tx_bytes", Type: TypeInt, Alias: "mempool-max-tx-bytes", Description: "Maximum size of a single transaction"},
+	{File: CometToml, Key: "mempool.max_batch_bytes", Type: TypeInt, Alias: "mempool-max-batch-bytes", Description: "Maximum size of a transaction batch"},
+
+	// config.toml [statesync]
+	{File: CometToml, Key: "statesync.enable", Type: TypeBool, Alias: "statesync-enable", Description: "Enable state sync"},
+	{File: CometToml, Key: "statesync.rpc_servers", Type: TypeString, Alias: "statesync-rpc-servers", Description: "Comma separated list of RPC servers for light client verification"},
+	{File: CometToml, Key: "statesync.trust_height", Type: TypeInt, Alias: "statesync-trust-height", Description: "Trusted block height"},
+	{File: CometToml, Key: "statesync.trust_hash", Type: TypeString, Alias: "statesync-trust-hash", Description: "Trusted block hash"},
+	{File: CometToml, Key: "statesync.trust_period", Type: TypeDuration, Alias: "statesync-trust-period", Description: "Trust period of the light client"},
+	{File: CometToml, Key: "statesync.discovery_time", Type: TypeDuration, Alias: "statesync-discovery-time", Description: "Time to spend discovering snapshots"},
+	{File: CometToml, Key: "statesync.temp_dir", Type: TypeString, Alias: "statesync-temp-dir", Description: "Temporary directory for state sync chunks"},
+	{File: CometToml, Key: "statesync.chunk_request_timeout", Type: TypeDuration, Alias: "statesync-chunk-request-timeout", Description: "Timeout of a chunk request"},
+	{File: CometToml, Key: "statesync.chunk_fetchers", Type: TypeString, Alias: "statesync-chunk-fetchers", Description: "Number of concurrent chunk fetchers"},
+
+	// config.toml [blocksync]
+	{File: CometToml, Key: "blocksync.version", Type: TypeString, Alias: "blocksync-version", Description: "Block sync protocol version"},
+
+	// config.toml [consensus]
+	{File: CometToml, Key: "consensus.wal_file", Type: TypeString, Alias: "consensus-wal-file", Description: "Path to the consensus WAL"},
+	{File: CometToml, Key: "consensus.timeout_propose", Type: TypeDuration, Alias: "consensus-timeout-propose", Description: "Time to wait for a proposal"},
+	{File: CometToml, Key: "consensus.timeout_propose_delta", Type: TypeDuration, Alias: "consensus-timeout-propose-delta", Description: "Propose timeout increase per round"},
+	{File: CometToml, Key: "consensus.timeout_prevote", Type: TypeDuration, Alias: "consensus-timeout-prevote", Description: "Time to wait after +2/3 prevotes"},
+	{File: CometToml, Key: "consensus.timeout_prevote_delta", Type: TypeDuration, Alias: "consensus-timeout-prevote-delta", Description: "Prevote timeout increase per round"},
+	{File: CometToml, Key: "consensus.timeout_precommit", Type: TypeDuration, Alias: "consensus-timeout-precommit", Description: "Time to wait after +2/3 precommits"},
+	{File: CometToml, Key: "consensus.timeout_precommit_delta", Type: TypeDuration, Alias: "consensus-timeout-precommit-delta", Description: "Precommit timeout increase per round"},
+	{File: CometToml, Key: "consensus.timeout_commit", Type: TypeDuration, Alias: "consensus-timeout-commit", Description: "Time to wait after committing a block"},
+	{File: CometToml, Key: "consensus.double_sign_check_height", Type: TypeInt, Alias: "consensus-double-sign-check-height", Description: "Blocks to look back for double signing before starting"},
+	{File: CometToml, Key: "consensus.skip_timeout_commit", Type: TypeBool, Alias: "consensus-skip-timeout-commit", Description: "Make progress as soon as all precommits are in"},
+	{File: CometToml, Key: "consensus.create_empty_blocks", Type: TypeBool, Alias: "consensus-create-empty-blocks", Description: "Create empty blocks"},
+	{File: CometToml, Key: "consensus.create_empty_blocks_interval", Type: TypeDuration, Alias: "consensus-create-empty-blocks-interval", Description: "Interval between empty blocks"},
+	{File: CometToml, Key: "consensus.peer_gossip_sleep_duration", Type: TypeDuration, Alias: "consensus-peer-gossip-sleep-duration", Description: "Gossip sleep duration"},
+	{File: CometToml, Key: "consensus.peer_query_maj23_sleep_duration", Type: TypeDuration, Alias: "consensus-peer-query-maj23-sleep-duration", Description: "Maj23 query sleep duration"},
+
+	// config.toml [storage], [tx_index], [instrumentation]
+	{File: CometToml, Key: "storage.discard_abci_responses", Type: TypeBool, Alias: "storage-discard-abci-responses", Description: "Discard ABCI responses from the state store"},
+	{File: CometToml, Key: "tx_index.indexer", Type: TypeString, Allowed: []string{"kv", "null", "psql"}, Alias: "tx-index-indexer", Description: "Transaction indexer"},
+	{File: CometToml, Key: "tx_index.psql-conn", Type: TypeString, Alias: "tx-index-psql-conn", Description: "PostgreSQL connection string of the psql indexer"},
+	{File: CometToml, Key: "instrumentation.prometheus", Type: TypeBool, Alias: "instrumentation-prometheus", Description: "Expose Prometheus metrics"},
+	{File: CometToml, Key: "instrumentation.prometheus_listen_addr", Type: TypeString, Alias: "instrumentation-prometheus-listen-addr", Description: "Prometheus listen address"},
+	{File: CometToml, Key: "instrumentation.max_open_connections", Type: TypeInt, Alias: "instrumentation-max-open-connections", Description: "Maximum number of simultaneous Prometheus connections"},
+	{File: CometToml, Key: "instrumentation.namespace", Type: TypeString, Alias: "instrumentation-namespace", Description: "Prometheus metrics namespace"},
+}
+
+// LookupConfigKey finds the schema entries matching key. When file is empty
+// both files are searched, so a key such as "version" can match twice.
+func LookupConfigKey(file NodeConfigFile, key string) []ConfigKey {
+	var matches []ConfigKey
+	for _, k := range NodeConfigSchema {
+		if k.Key == key && (file == "" || k.File == file) {
+			matches = append(matches, k)
+		}
+	}
+	return matches
+}
+
+// ConfigKeyNames returns all schema keys, sorted and deduplicated.
+func ConfigKeyNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, k := range NodeConfigSchema {
+		if !seen[k.Key] {
+			seen[k.Key] = true
+			names = append(names, k.Key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
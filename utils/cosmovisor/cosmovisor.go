@@ -0,0 +1,170 @@
+package cosmovisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/install"
+)
+
+// Version is the cosmovisor release that is installed. It is pinned so every
+// node runs the upgrade logic it was tested with.
+const Version = "v1.7.0"
+
+// Module is the Go package cosmovisor is installed from.
+const Module = "cosmossdk.io/tools/cosmovisor/cmd/cosmovisor@" + Version
+
+// Install installs cosmovisor into binDir with go install.
+func Install(binDir string) error {
+	cmd := executor.Cmd("go", "install", Module)
+	cmd.Env = append([]string{"GOBIN=" + binDir}, install.GoPathEnv()...)
+	if err := executor.Run(cmd); err != nil {
+		return fmt.Errorf("failed to install cosmovisor: %v", err)
+	}
+	return nil
+}
+
+// Layout is the Cosmovisor directory tree of a daemon:
+//
+//	<home>/cosmovisor/genesis/bin/<name>
+//	<home>/cosmovisor/upgrades/<upgrade>/bin/<name>
+//	<home>/cosmovisor/current -> genesis or upgrades/<upgrade>
+type Layout struct {
+	// Home is DAEMON_HOME, the story home directory.
+	Home string
+	// Name is DAEMON_NAME, the binary name.
+	Name string
+	// Cosmovisor is the path of the cosmovisor binary.
+	Cosmovisor string
+}
+
+// ForProfile returns the layout of the story daemon of node.
+func ForProfile(node config.Profile) Layout {
+	return Layout{
+		Home:       node.StoryHome(),
+		Name:       "story",
+		Cosmovisor: node.Binary("cosmovisor"),
+	}
+}
+
+// Root returns the cosmovisor directory.
+func (l Layout) Root() string {
+	return filepath.Join(l.Home, "cosmovisor")
+}
+
+// GenesisBin returns the path of the genesis binary.
+func (l Layout) GenesisBin() string {
+	return filepath.Join(l.Root(), "genesis", "bin", l.Name)
+}
+
+// UpgradeBin returns the path of the binary for the named upgrade.
+func (l Layout) UpgradeBin(upgrade string) string {
+	return filepath.Join(l.Root(), "upgrades", upgrade, "bin", l.Name)
+}
+
+// UpgradeInfoPath returns data/upgrade-info.json, which tells cosmovisor
+// which upgrade to switch to and at what height.
+func (l Layout) UpgradeInfoPath() string {
+	return filepath.Join(l.Home, "data", "upgrade-info.json")
+}
+
+// Installed reports whether the node has been set up with Cosmovisor.
+func (l Layout) Installed() bool {
+	_, err := os.Stat(l.GenesisBin())
+	return err == nil
+}
+
+// Env returns the environment cosmovisor needs to find the daemon.
+func (l Layout) Env() []string {
+	return []string{"DAEMON_NAME=" + l.Name, "DAEMON_HOME=" + l.Home}
+}
+
+// Init creates the genesis directory with binary and points current at it.
+func (l Layout) Init(binary string) error {
+	return l.run("init", binary)
+}
+
+// AddUpgrade copies binary into the folder of the named upgrade. With a
+// height above zero, cosmovisor also switches to it at that height without
+// an on-chain upgrade plan; force replaces an existing upgrade.
+func (l Layout) AddUpgrade(upgrade, binary string, height int64, force bool) error {
+	args := []string{"add-upgrade", upgrade, binary}
+	if height > 0 {
+		args = append(args, "--upgrade-height", fmt.Sprint(height))
+	}
+	if force {
+		args = append(args, "--force")
+	}
+	return l.run(args...)
+}
+
+// Upgrades returns the names of the upgrades that have a binary.
+func (l Layout) Upgrades() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(l.Root(), "upgrades"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if _, err := os.Stat(l.UpgradeBin(e.Name())); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// UpgradeInfo is the content of upgrade-info.json.
+type UpgradeInfo struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+}
+
+// PendingUpgrade returns the upgrade cosmovisor is waiting for, if any.
+func (l Layout) PendingUpgrade() (*UpgradeInfo, error) {
+	data, err := os.ReadFile(l.UpgradeInfoPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info UpgradeInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", l.UpgradeInfoPath(), err)
+	}
+	return &info, nil
+}
+
+// Current returns the name of the directory current points at, e.g.
+// "genesis" or "upgrades/v1.1.0".
+func (l Layout) Current() (string, error) {
+	target, err := os.Readlink(filepath.Join(l.Root(), "current"))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(l.Root(), target); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel, nil
+	}
+	return target, nil
+}
+
+func (l Layout) run(args ...string) error {
+	cmd := executor.Cmd(l.Cosmovisor, args...)
+	cmd.Env = l.Env()
+	if err := executor.Run(cmd); err != nil {
+		return fmt.Errorf("cosmovisor %s failed: %v", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package executor runs the commands and file changes scli makes on the
+// machine. Everything that changes the system goes through the active
+// Executor, so it can be printed instead (--dry-run) or recorded in tests.
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Executor runs commands and changes files.
+type Executor interface {
+	// Run runs a command that changes the system.
+	Run(c Command) error
+	// Query runs a command that only reads the system and returns its
+	// standard output.
+	Query(c Command) ([]byte, error)
+
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	MkdirAll(path string, perm os.FileMode) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+	Symlink(target, link string) error
+	Chmod(path string, mode os.FileMode) error
+
+	// Perform runs fn, a step done in Go code such as a download, that is
+	// described by what.
+	Perform(what string, fn func() error) error
+}
+
+// Command is a program to run.
+type Command struct {
+	Name string
+	Args []string
+	// Dir is the working directory; empty is the current one.
+	Dir string
+	// Env is added to the environment of scli.
+	Env []string
+	// Stdin, Stdout and Stderr are connected to the command if set.
+	// Output that isn't connected is captured and included in errors.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Cmd returns the command name with args.
+func Cmd(name string, args ...string) Command {
+	return Command{Name: name, Args: args}
+}
+
+// String returns the command as it would be typed in a shell.
+func (c Command) String() string {
+	var parts []string
+	if c.Dir != "" {
+		parts = append(parts, "cd", quote(c.Dir), "&&")
+	}
+	for _, e := range c.Env {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			parts = append(parts, k+"="+quote(v))
+		}
+	}
+	parts = append(parts, quote(c.Name))
+	for _, a := range c.Args {
+		parts = append(parts, quote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// quote quotes s for a POSIX shell if needed
+func quote(s string) string {
+	if plainWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// CommandError is returned when a command fails. It holds the output that
+// was captured.
+type CommandError struct {
+	Command Command
+	Err     error
+	Stdout  string
+	Stderr  string
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Command, e.Err)
+	if out := strings.TrimSpace(e.Stderr); out != "" {
+		msg += ": " + out
+	} else if out := strings.TrimSpace(e.Stdout); out != "" {
+		msg += ": " + out
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+var active Executor = System{}
+
+// SetActive makes e the executor returned by Active.
+func SetActive(e Executor) {
+	active = e
+}
+
+// Active returns the executor selected for this run, the System executor
+// unless another one was set.
+func Active() Executor {
+	return active
+}
+
+// Run runs c with the active executor.
+func Run(c Command) error {
+	return active.Run(c)
+}
+
+// Query runs c with the active executor and returns its output.
+func Query(c Command) ([]byte, error) {
+	return active.Query(c)
+}
+
+// Perform runs fn with the active executor.
+func Perform(what string, fn func() error) error {
+	return active.Perform(what, fn)
+}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoResponse is returned by a Recorder for a query it has no response
+// for.
+var ErrNoResponse = errors.New("no response recorded")
+
+// Call is one thing an executor was asked to do.
+type Call struct {
+	// Kind is "run", "query", "write", "mkdir", "remove", "rename",
+	// "symlink", "chmod" or "perform".
+	Kind string
+	// Command is set for run and query.
+	Command Command
+	// Path, Target and Mode are set for file changes; Target is the new
+	// path of a rename and the target of a symlink.
+	Path   string
+	Target string
+	Mode   os.FileMode
+	// Data is the content of a write.
+	Data []byte
+	// What describes a perform.
+	What string
+}
+
+// String returns the call as a shell command, e.g. "mkdir -p /root/.story".
+func (c Call) String() string {
+	switch c.Kind {
+	case "run", "query":
+		return c.Command.String()
+	case "write":
+		return fmt.Sprintf("write %s (%d bytes, mode %04o)", quote(c.Path), len(c.Data), c.Mode.Perm())
+	case "mkdir":
+		return "mkdir -p " + quote(c.Path)
+	case "remove":
+		return "rm -rf " + quote(c.Path)
+	case "rename":
+		return "mv " + quote(c.Path) + " " + quote(c.Target)
+	case "symlink":
+		return "ln -s " + quote(c.Target) + " " + quote(c.Path)
+	case "chmod":
+		return fmt.Sprintf("chmod %04o %s", c.Mode.Perm(), quote(c.Path))
+	}
+	return c.What
+}
+
+type response struct {
+	out []byte
+	err error
+}
+
+// Recorder records what it is asked to do instead of doing it. It backs
+// --dry-run, which prints every call, and tests, which check the calls and
+// answer queries without touching the machine.
+type Recorder struct {
+	// Out, if set, gets every call printed as it is made.
+	Out io.Writer
+	// Queries, if set, runs queries; otherwise they are answered from the
+	// responses set with Respond.
+	Queries Executor
+	// RunSteps makes Perform run its function instead of only recording it.
+	RunSteps bool
+
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string]response
+	failures  map[string]error
+}
+
+// NewRecorder returns a recorder for tests. Queries are answered from
+// Respond and fail with ErrNoResponse otherwise.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// NewDryRun returns the executor of --dry-run: calls that change the
+// system are printed to out, queries are run so that scli can still decide
+// what it would do.
+func NewDryRun(out io.Writer) *Recorder {
+	return &Recorder{Out: out, Queries: System{}}
+}
+
+// Respond sets the output of the query command (as returned by
+// Command.String).
+func (r *Recorder) Respond(command string, out []byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.responses == nil {
+		r.responses = map[string]response{}
+	}
+	r.responses[command] = response{out: out, err: err}
+}
+
+// Fail makes the call that prints as call (see Call.String) return err.
+func (r *Recorder) Fail(call string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures == nil {
+		r.failures = map[string]error{}
+	}
+	r.failures[call] = err
+}
+
+// Calls returns the calls recorded so far.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Commands returns the recorded calls as shell commands.
+func (r *Recorder) Commands() []string {
+	var lines []string
+	for _, c := range r.Calls() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// Written returns the data last written to path.
+func (r *Recorder) Written(path string) ([]byte, bool) {
+	calls := r.Calls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i].Kind == "write" && calls[i].Path == path {
+			return calls[i].Data, true
+		}
+	}
+	return nil, false
+}
+
+// record stores c, prints it if Out is set and returns the failure set
+// for it
+func (r *Recorder) record(c Call) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, c)
+
+	if r.Out != nil && c.Kind != "query" {
+		line := c.String()
+		if c.Kind == "perform" {
+			line = "skip: " + line
+		}
+		fmt.Fprintln(r.Out, "[dry-run] "+line)
+		if c.Kind == "write" {
+			for _, l := range strings.Split(strings.TrimRight(string(c.Data), "\n"), "\n") {
+				fmt.Fprintln(r.Out, "[dry-run]   | "+l)
+			}
+		}
+	}
+	return r.failures[c.String()]
+}
+
+// Run records c.
+func (r *Recorder) Run(c Command) error {
+	return r.record(Call{Kind: "run", Command: c})
+}
+
+// Query records c and answers it.
+func (r *Recorder) Query(c Command) ([]byte, error) {
+	if err := r.record(Call{Kind: "query", Command: c}); err != nil {
+		return nil, err
+	}
+	if r.Queries != nil {
+		return r.Queries.Query(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resp, ok := r.responses[c.String()]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoResponse, c)
+	}
+	return resp.out, resp.err
+}
+
+// WriteFile records a write of data to path.
+func (r *Recorder) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return r.record(Call{Kind: "write", Path: path, Data: append([]byte(nil), data...), Mode: perm})
+}
+
+// MkdirAll records the creation of path.
+func (r *Recorder) MkdirAll(path string, perm os.FileMode) error {
+	return r.record(Call{Kind: "mkdir", Path: path, Mode: perm})
+}
+
+// RemoveAll records the removal of path.
+func (r *Recorder) RemoveAll(path string) error {
+	return r.record(Call{Kind: "remove", Path: path})
+}
+
+// Rename records the rename of oldpath to newpath.
+func (r *Recorder) Rename(oldpath, newpath string) error {
+	return r.record(Call{Kind: "rename", Path: oldpath, Target: newpath})
+}
+
+// Symlink records the creation of link pointing to target.
+func (r *Recorder) Symlink(target, link string) error {
+	return r.record(Call{Kind: "symlink", Path: link, Target: target})
+}
+
+// Chmod records the mode change of path.
+func (r *Recorder) Chmod(path string, mode os.FileMode) error {
+	return r.record(Call{Kind: "chmod", Path: path, Mode: mode})
+}
+
+// Perform records what, and runs fn if RunSteps is set.
+func (r *Recorder) Perform(what string, fn func() error) error {
+	if err := r.record(Call{Kind: "perform", What: what}); err != nil {
+		return err
+	}
+	if r.RunSteps {
+		return fn()
+	}
+	return nil
+}
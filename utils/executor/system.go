@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+)
+
+// System runs commands and changes files for real.
+type System struct{}
+
+// Run runs c.
+func (System) Run(c Command) error {
+	_, err := run(c)
+	return err
+}
+
+// Query runs c and returns its standard output.
+func (System) Query(c Command) ([]byte, error) {
+	return run(c)
+}
+
+// Exec returns the process that runs c. It is for commands that are
+// started rather than run to completion, such as a supervised service, and
+// must only be used inside a Perform step.
+func (c Command) Exec() *exec.Cmd {
+	cmd := exec.Command(c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	return cmd
+}
+
+func run(c Command) ([]byte, error) {
+	cmd := c.Exec()
+
+	var stdout, stderr bytes.Buffer
+	if c.Stdout == nil {
+		cmd.Stdout = &stdout
+	}
+	if c.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), &CommandError{Command: c, Err: err, Stdout: stdout.String(), Stderr: stderr.String()}
+	}
+	return stdout.Bytes(), nil
+}
+
+// WriteFile writes data to path.
+func (System) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+
+// MkdirAll creates path and its parents.
+func (System) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// RemoveAll removes path and everything below it.
+func (System) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// Rename renames oldpath to newpath.
+func (System) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Symlink creates link pointing to target.
+func (System) Symlink(target, link string) error {
+	return os.Symlink(target, link)
+}
+
+// Chmod changes the mode of path.
+func (System) Chmod(path string, mode os.FileMode) error {
+	return os.Chmod(path, mode)
+}
+
+// Perform runs fn.
+func (System) Perform(what string, fn func() error) error {
+	return fn()
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Format is the compression format of a tar archive.
+type Format string
+
+const (
+	FormatTar  Format = "tar"
+	FormatLz4  Format = "lz4"
+	FormatZstd Format = "zstd"
+	FormatGzip Format = "gzip"
+)
+
+var (
+	lz4Magic  = []byte{0x04, 0x22, 0x4d, 0x18}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the position of the "ustar" magic in a tar header
+const tarMagicOffset = 257
+
+// DetectFormat returns the archive format based on the first bytes of the
+// file, falling back to the extension of name when the magic bytes are not
+// recognised. Unknown archives are treated as plain tar.
+func DetectFormat(header []byte, name string) Format {
+	switch {
+	case bytes.HasPrefix(header, lz4Magic):
+		return FormatLz4
+	case bytes.HasPrefix(header, zstdMagic):
+		return FormatZstd
+	case bytes.HasPrefix(header, gzipMagic):
+		return FormatGzip
+	case len(header) >= tarMagicOffset+len(tarMagic) && bytes.Equal(header[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic):
+		return FormatTar
+	}
+	return FormatFromName(name)
+}
+
+// FormatFromName guesses the archive format from a file name or URL
+func FormatFromName(name string) Format {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".lz4"):
+		return FormatLz4
+	case strings.HasSuffix(name, ".zst"), strings.HasSuffix(name, ".zstd"), strings.HasSuffix(name, ".tzst"):
+		return FormatZstd
+	case strings.HasSuffix(name, ".gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatGzip
+	}
+	return FormatTar
+}
+
+// ArchiveName returns the file name of the archive at rawURL, e.g.
+// "story_pruned.tar.zst", or fallback if the URL has none
+func ArchiveName(rawURL, fallback string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fallback
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	return name
+}
+
+// Decompress detects the format of r and returns a reader for the
+// uncompressed tar stream. name is used when the format can't be detected
+// from the content.
+func Decompress(r io.Reader, name string) (io.ReadCloser, Format, error) {
+	br := bufio.NewReaderSize(r, 1024)
+	// Peek returns fewer bytes with an error for short input, that's fine
+	header, _ := br.Peek(tarMagicOffset + len(tarMagic))
+
+	format := DetectFormat(header, name)
+	switch format {
+	case FormatLz4:
+		return io.NopCloser(lz4.NewReader(br)), format, nil
+	case FormatZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, format, err
+		}
+		return zr.IOReadCloser(), format, nil
+	case FormatGzip:
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, format, fmt.Errorf("failed to read gzip header: %w", err)
+		}
+		return gr, format, nil
+	default:
+		return io.NopCloser(br), format, nil
+	}
+}
+
+// ExtractArchive extracts the (possibly compressed) tar stream r into destDir
+func ExtractArchive(r io.Reader, name, destDir string, opts ExtractOptions) error {
+	tarReader, _, err := Decompress(r, name)
+	if err != nil {
+		return err
+	}
+	defer tarReader.Close()
+
+	return ExtractTar(tarReader, destDir, opts)
+}
@@ -0,0 +1,366 @@
+package file
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+// ErrVerification is returned when a downloaded file doesn't match the
+// expected size or checksum.
+var ErrVerification = errors.New("file verification failed")
+
+// Expected describes what a downloaded file should look like. Zero fields
+// are not checked.
+type Expected struct {
+	Size   int64
+	SHA256 string
+}
+
+// IsZero reports whether there is nothing to verify.
+func (e Expected) IsZero() bool {
+	return e.Size <= 0 && e.SHA256 == ""
+}
+
+// DownloadOptions configures DownloadFileResumable.
+type DownloadOptions struct {
+	// Expected is checked once the download has completed.
+	Expected Expected
+	// Client is the HTTP client used for the download (http.DefaultClient if nil).
+	Client *http.Client
+	// Retries is the number of times an interrupted download is resumed.
+	Retries int
+	// RetryDelay is the pause between retries.
+	RetryDelay time.Duration
+	// HideProgress disables the progress bar.
+	HideProgress bool
+	// Progress is where the progress bar is drawn (stdout if nil).
+	Progress io.Writer
+}
+
+// downloadState is stored next to a partial download so it can be resumed.
+type downloadState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	TotalSize    int64  `json:"total_size,omitempty"`
+}
+
+// partPath returns the path of the partial download for dest
+func partPath(dest string) string {
+	return dest + ".part"
+}
+
+// statePath returns the path of the sidecar state file for dest
+func statePath(dest string) string {
+	return dest + ".part.json"
+}
+
+// DownloadFileResumable downloads url to dest. Data is written to dest.part
+// and a sidecar state file so that an interrupted download continues with an
+// HTTP Range request instead of starting over. The file is only moved to dest
+// once it is complete and matches opts.Expected.
+func DownloadFileResumable(url, dest string, opts DownloadOptions) error {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 5 * time.Second
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			pterm.Warning.Printf("Download interrupted (%v), resuming in %s (attempt %d/%d)...\n", lastErr, opts.RetryDelay, attempt, opts.Retries)
+			time.Sleep(opts.RetryDelay)
+		}
+
+		lastErr = downloadAttempt(url, dest, opts)
+		if lastErr == nil {
+			break
+		}
+		if errors.Is(lastErr, ErrVerification) {
+			return lastErr
+		}
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+
+	if err := VerifyFile(partPath(dest), opts.Expected); err != nil {
+		// A corrupt partial file must not be resumed again
+		os.Remove(partPath(dest))
+		os.Remove(statePath(dest))
+		return err
+	}
+
+	if err := os.Rename(partPath(dest), dest); err != nil {
+		return err
+	}
+	os.Remove(statePath(dest))
+	return nil
+}
+
+// downloadAttempt downloads (the rest of) url into the partial file
+func downloadAttempt(url, dest string, opts DownloadOptions) error {
+	state := loadDownloadState(dest)
+	resumable := state.URL == url
+	if !resumable {
+		// A partial file of another URL, or one without state, can't be
+		// continued
+		if err := os.Remove(partPath(dest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		state = downloadState{URL: url}
+	}
+
+	var offset int64
+	if info, err := os.Stat(partPath(dest)); err == nil && resumable {
+		offset = info.Size()
+	}
+	if state.TotalSize > 0 && offset == state.TotalSize {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// Only resume if the remote file hasn't changed in the meantime
+		if state.ETag != "" {
+			req.Header.Set("If-Range", state.ETag)
+		} else if state.LastModified != "" {
+			req.Header.Set("If-Range", state.LastModified)
+		}
+	}
+
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range or the file changed: start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		if state.TotalSize > 0 && offset >= state.TotalSize {
+			return nil
+		}
+		os.Remove(partPath(dest))
+		os.Remove(statePath(dest))
+		return fmt.Errorf("server rejected resume at offset %d", offset)
+	default:
+		return fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	state.ETag = resp.Header.Get("ETag")
+	state.LastModified = resp.Header.Get("Last-Modified")
+	if resp.ContentLength > 0 {
+		state.TotalSize = offset + resp.ContentLength
+	}
+	if err := saveDownloadState(dest, state); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(partPath(dest), flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var body io.Reader = resp.Body
+	var p *mpb.Progress
+	var bar *mpb.Bar
+	if !opts.HideProgress && state.TotalSize > 0 {
+		p = mpb.New(
+			mpb.WithOutput(progressOutput(opts.Progress)),
+			mpb.WithWidth(64),
+			mpb.WithRefreshRate(180*time.Millisecond),
+		)
+		bar = p.AddBar(state.TotalSize,
+			mpb.PrependDecorators(
+				decor.Name("Downloading:", decor.WC{W: len("Downloading: "), C: decor.DidentRight}),
+				decor.CountersKibiByte("% .2f / % .2f"),
+			),
+			mpb.AppendDecorators(
+				decor.Percentage(decor.WC{W: 5}),
+			),
+		)
+		bar.SetCurrent(offset)
+		proxy := bar.ProxyReader(resp.Body)
+		defer proxy.Close()
+		body = proxy
+	}
+
+	written, err := io.Copy(out, body)
+	if p != nil {
+		if err != nil || offset+written < state.TotalSize {
+			bar.Abort(false)
+		}
+		p.Wait()
+	}
+	if err != nil {
+		return err
+	}
+
+	if state.TotalSize > 0 && offset+written < state.TotalSize {
+		return fmt.Errorf("connection closed after %d of %d bytes", offset+written, state.TotalSize)
+	}
+	return nil
+}
+
+func loadDownloadState(dest string) downloadState {
+	var state downloadState
+	data, err := os.ReadFile(statePath(dest))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return downloadState{}
+	}
+	return state
+}
+
+func saveDownloadState(dest string, state downloadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath(dest), data, 0644)
+}
+
+// progressOutput returns w, or stdout if w is nil
+func progressOutput(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// VerifyFile checks path against the expected size and SHA256 checksum.
+// The returned error wraps ErrVerification on a mismatch.
+func VerifyFile(path string, expected Expected) error {
+	if expected.IsZero() {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if expected.Size > 0 && info.Size() != expected.Size {
+		return fmt.Errorf("%w: %s has %d bytes, expected %d", ErrVerification, path, info.Size(), expected.Size)
+	}
+
+	if expected.SHA256 == "" {
+		return nil
+	}
+
+	sum, err := SHA256Sum(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, expected.SHA256) {
+		return fmt.Errorf("%w: %s has sha256 %s, expected %s", ErrVerification, path, sum, expected.SHA256)
+	}
+	return nil
+}
+
+// SHA256Sum returns the hex encoded SHA256 checksum of the file at path.
+func SHA256Sum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FetchExpected asks the server for the size of url and looks for a
+// published checksum next to it (url + ".sha256"). Whatever can't be
+// determined is left empty.
+func FetchExpected(url string) Expected {
+	var expected Expected
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	if resp, err := client.Head(url); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && resp.ContentLength > 0 {
+			expected.Size = resp.ContentLength
+		}
+	}
+
+	resp, err := client.Get(url + ".sha256")
+	if err != nil {
+		return expected
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return expected
+	}
+
+	// Checksum files look like "<hex>  <file name>"
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 4096))
+	if scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && len(fields[0]) == sha256.Size*2 {
+			if _, err := hex.DecodeString(fields[0]); err == nil {
+				expected.SHA256 = strings.ToLower(fields[0])
+			}
+		}
+	}
+	return expected
+}
+
+// CheckDownload verifies a finished download. A mismatch is an error when
+// strict is set and a warning otherwise.
+func CheckDownload(path string, expected Expected, strict bool) error {
+	if expected.IsZero() {
+		if strict {
+			pterm.Warning.Println(fmt.Sprintf("No size or checksum published for %s, skipping verification.", path))
+		}
+		return nil
+	}
+
+	pterm.Info.Println(fmt.Sprintf("Verifying %s...", path))
+	if err := VerifyFile(path, expected); err != nil {
+		if strict {
+			return err
+		}
+		pterm.Warning.Println(err.Error())
+		return nil
+	}
+
+	what := "size " + strconv.FormatInt(expected.Size, 10)
+	if expected.SHA256 != "" {
+		what = "sha256 " + expected.SHA256
+	}
+	pterm.Success.Println(fmt.Sprintf("%s verified (%s).", path, what))
+	return nil
+}
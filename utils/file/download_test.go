@@ -0,0 +1,173 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var payload = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+// server serves payload and records the Range header of every request
+type server struct {
+	*httptest.Server
+	mu     sync.Mutex
+	ranges []string
+}
+
+// newServer returns a server that honours Range requests if ranges is set
+// and always answers with the whole file otherwise
+func newServer(t *testing.T, ranges bool) *server {
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+		if !ranges {
+			r.Header.Del("Range")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(payload))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) requestedRanges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+// leavePart leaves a partial download of url with data behind
+func leavePart(t *testing.T, dest, url string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(partPath(dest), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if url == "" {
+		return
+	}
+	state := downloadState{URL: url, ETag: `"v1"`, TotalSize: int64(len(payload))}
+	if err := saveDownloadState(dest, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadFileResumable(t *testing.T) {
+	half := len(payload) / 2
+	garbage := bytes.Repeat([]byte("x"), half)
+
+	tests := []struct {
+		name string
+		// ranges is whether the server honours Range requests
+		ranges bool
+		// part is the partial file left behind, if any
+		part []byte
+		// partURL is the URL in the sidecar state; "" leaves no sidecar
+		partURL string
+		// wantRange is the Range header the first request should carry
+		wantRange string
+	}{
+		{
+			name:   "fresh download",
+			ranges: true,
+		},
+		{
+			name:      "resumes with a Range request",
+			ranges:    true,
+			part:      payload[:half],
+			partURL:   "{url}",
+			wantRange: "bytes=32768-",
+		},
+		{
+			name:      "starts over when the server ignores the range",
+			ranges:    false,
+			part:      garbage,
+			partURL:   "{url}",
+			wantRange: "bytes=32768-",
+		},
+		{
+			name:    "starts over when the URL changed",
+			ranges:  true,
+			part:    garbage,
+			partURL: "http://example.invalid/old",
+		},
+		{
+			name:   "starts over without a sidecar",
+			ranges: true,
+			part:   garbage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.ranges)
+			url := srv.URL + "/snapshot.tar.lz4"
+			dest := filepath.Join(t.TempDir(), "snapshot.tar.lz4")
+			if tt.part != nil {
+				leavePart(t, dest, strings.ReplaceAll(tt.partURL, "{url}", url), tt.part)
+			}
+
+			opts := DownloadOptions{
+				Expected:     Expected{Size: int64(len(payload)), SHA256: checksum(payload)},
+				HideProgress: true,
+			}
+			if err := DownloadFileResumable(url, dest, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("downloaded %d bytes that don't match the %d served", len(got), len(payload))
+			}
+			if ranges := srv.requestedRanges(); len(ranges) != 1 || ranges[0] != tt.wantRange {
+				t.Errorf("requested ranges %q, want [%q]", ranges, tt.wantRange)
+			}
+			for _, path := range []string{partPath(dest), statePath(dest)} {
+				if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("%s left behind", filepath.Base(path))
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadFileResumableChecksumMismatch(t *testing.T) {
+	srv := newServer(t, true)
+	dest := filepath.Join(t.TempDir(), "snapshot.tar.lz4")
+
+	opts := DownloadOptions{
+		Expected:     Expected{SHA256: checksum([]byte("something else"))},
+		Retries:      2,
+		HideProgress: true,
+	}
+	err := DownloadFileResumable(srv.URL+"/snapshot.tar.lz4", dest, opts)
+	if !errors.Is(err, ErrVerification) {
+		t.Fatalf("got %v, want ErrVerification", err)
+	}
+	for _, path := range []string{dest, partPath(dest), statePath(dest)} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left behind", filepath.Base(path))
+		}
+	}
+	if n := len(srv.requestedRanges()); n != 1 {
+		t.Errorf("%d requests, a mismatch must not be retried", n)
+	}
+}
@@ -0,0 +1,250 @@
+package file
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// ErrUnsafeArchive is returned when an archive entry would be written
+// outside of the destination directory.
+var ErrUnsafeArchive = errors.New("unsafe archive entry")
+
+// ExtractOptions configures tar extraction.
+type ExtractOptions struct {
+	// PreserveOwnership applies the uid and gid stored in the archive. This
+	// only works when running as root.
+	PreserveOwnership bool
+}
+
+// ExtractTar extracts the uncompressed tar stream r into destDir. Entries
+// with absolute paths, entries that would end up outside destDir and links
+// pointing outside destDir are rejected with ErrUnsafeArchive. Parent
+// directories are created as needed and modification times are restored.
+func ExtractTar(r io.Reader, destDir string, opts ExtractOptions) error {
+	root, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+
+	// Directory mtimes are set at the end, as extracting files into a
+	// directory changes its mtime again
+	type dirTime struct {
+		path  string
+		mtime time.Time
+	}
+	var dirTimes []dirTime
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break // End of tar archive
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := archivePath(root, header.Name)
+		if err != nil {
+			return err
+		}
+		if target == root && header.Typeflag != tar.TypeDir {
+			return fmt.Errorf("%w: %s", ErrUnsafeArchive, header.Name)
+		}
+		if err := checkNoSymlinkParents(root, target); err != nil {
+			return err
+		}
+
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+				if err := os.Remove(target); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			if err := os.Chmod(target, mode|0700); err != nil {
+				return err
+			}
+			dirTimes = append(dirTimes, dirTime{target, header.ModTime})
+
+		case tar.TypeReg:
+			if err := prepareTarget(target); err != nil {
+				return err
+			}
+			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(outFile, tarReader); err != nil {
+				outFile.Close()
+				return err
+			}
+			if err := outFile.Close(); err != nil {
+				return err
+			}
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			if err := checkSymlinkTarget(root, target, header.Linkname); err != nil {
+				return fmt.Errorf("%w: %s -> %s", err, header.Name, header.Linkname)
+			}
+			if err := prepareTarget(target); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+
+		case tar.TypeLink:
+			source, err := archivePath(root, header.Linkname)
+			if err != nil {
+				return fmt.Errorf("%w: %s -> %s", ErrUnsafeArchive, header.Name, header.Linkname)
+			}
+			if err := checkNoSymlinkParents(root, source); err != nil {
+				return err
+			}
+			// Only link to regular files that were already extracted
+			info, err := os.Lstat(source)
+			if err != nil || !info.Mode().IsRegular() {
+				return fmt.Errorf("%w: hardlink %s -> %s does not point to an extracted file", ErrUnsafeArchive, header.Name, header.Linkname)
+			}
+			if err := prepareTarget(target); err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+
+		case tar.TypeXGlobalHeader:
+			continue
+
+		default:
+			// Devices, fifos etc. never belong in a snapshot
+			pterm.Warning.Printf("Skipping unsupported file type %v: %s\n", header.Typeflag, header.Name)
+			continue
+		}
+
+		if opts.PreserveOwnership {
+			if err := os.Lchown(target, header.Uid, header.Gid); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(dirTimes) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirTimes[i].path, dirTimes[i].mtime, dirTimes[i].mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivePath maps an archive entry name to a path below root
+func archivePath(root, name string) (string, error) {
+	if name == "" || filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchive, name)
+	}
+	target := filepath.Join(root, name)
+	if !withinDir(root, target) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchive, name)
+	}
+	return target, nil
+}
+
+// withinDir reports whether path is root or below it
+func withinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// checkNoSymlinkParents makes sure no directory between root and target is
+// a symlink, so an earlier entry can't redirect later ones out of root
+func checkNoSymlinkParents(root, target string) error {
+	rel, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is a symlink", ErrUnsafeArchive, current)
+		}
+	}
+	return nil
+}
+
+// checkSymlinkTarget rejects symlinks that are absolute or resolve outside
+// of root. ".." is only accepted at the start of the link, since "a/.."
+// doesn't resolve lexically once "a" is itself a symlink.
+func checkSymlinkTarget(root, target, linkname string) error {
+	if linkname == "" || filepath.IsAbs(linkname) {
+		return ErrUnsafeArchive
+	}
+	descending := false
+	for _, part := range strings.Split(linkname, "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			if descending {
+				return ErrUnsafeArchive
+			}
+		default:
+			descending = true
+		}
+	}
+	if !withinDir(root, filepath.Join(filepath.Dir(target), linkname)) {
+		return ErrUnsafeArchive
+	}
+	return nil
+}
+
+// prepareTarget creates the parent directories of target and removes
+// whatever is currently at target
+func prepareTarget(target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot replace directory %s with a file", target)
+	}
+	return os.Remove(target)
+}
@@ -0,0 +1,265 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+)
+
+// entry is one member of a crafted archive
+type entry struct {
+	name string
+	// typ defaults to a regular file
+	typ  byte
+	body string
+	link string
+}
+
+// makeTar builds a tar archive of entries
+func makeTar(t testing.TB, entries ...entry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Typeflag: e.typ, Linkname: e.link, Mode: 0644, Size: int64(len(e.body))}
+		switch e.typ {
+		case 0:
+			h.Typeflag = tar.TypeReg
+		case tar.TypeDir:
+			h.Mode = 0755
+		}
+		if h.Typeflag != tar.TypeReg {
+			h.Size = 0
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Size > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTar(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	tests := []struct {
+		name    string
+		entries []entry
+		// want maps extracted paths to their content, or "-> target" for
+		// symlinks
+		want       map[string]string
+		wantUnsafe bool
+		wantErr    string
+	}{
+		{
+			name: "files, directories and links",
+			entries: []entry{
+				{name: "data/", typ: tar.TypeDir},
+				{name: "data/a.db", body: "a"},
+				{name: "data/nested/b.db", body: "b"},
+				{name: "data/link", typ: tar.TypeSymlink, link: "nested/b.db"},
+				{name: "data/hard", typ: tar.TypeLink, link: "data/a.db"},
+				{name: "data/nested/up", typ: tar.TypeSymlink, link: "../a.db"},
+			},
+			want: map[string]string{
+				"data/a.db":        "a",
+				"data/nested/b.db": "b",
+				"data/link":        "-> nested/b.db",
+				"data/hard":        "a",
+				"data/nested/up":   "-> ../a.db",
+			},
+		},
+		{
+			name:    "skips devices",
+			entries: []entry{{name: "dev", typ: tar.TypeChar}, {name: "a", body: "a"}},
+			want:    map[string]string{"a": "a"},
+		},
+		{
+			name:       "absolute path",
+			entries:    []entry{{name: "/etc/passwd", body: "x"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "parent directory",
+			entries:    []entry{{name: "../evil", body: "x"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "parent directory inside the path",
+			entries:    []entry{{name: "data/../../evil", body: "x"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "backslash",
+			entries:    []entry{{name: `..\evil`, body: "x"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "file in place of the root",
+			entries:    []entry{{name: ".", body: "x"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "absolute symlink",
+			entries:    []entry{{name: "link", typ: tar.TypeSymlink, link: "/etc"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "symlink out of the root",
+			entries:    []entry{{name: "data/link", typ: tar.TypeSymlink, link: "../../etc"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "symlink climbing after descending",
+			entries:    []entry{{name: "link", typ: tar.TypeSymlink, link: "a/../.."}},
+			wantUnsafe: true,
+		},
+		{
+			name: "write through a symlinked directory",
+			entries: []entry{
+				{name: "sub/", typ: tar.TypeDir},
+				{name: "link", typ: tar.TypeSymlink, link: "sub"},
+				{name: "link/file", body: "x"},
+			},
+			wantUnsafe: true,
+		},
+		{
+			name:       "hardlink out of the root",
+			entries:    []entry{{name: "hard", typ: tar.TypeLink, link: "../outside"}},
+			wantUnsafe: true,
+		},
+		{
+			name:       "hardlink to a file not extracted",
+			entries:    []entry{{name: "hard", typ: tar.TypeLink, link: "missing"}},
+			wantUnsafe: true,
+		},
+		{
+			name: "hardlink to a symlink",
+			entries: []entry{
+				{name: "link", typ: tar.TypeSymlink, link: "a"},
+				{name: "hard", typ: tar.TypeLink, link: "link"},
+			},
+			wantUnsafe: true,
+		},
+		{
+			name: "file in place of a directory",
+			entries: []entry{
+				{name: "data/", typ: tar.TypeDir},
+				{name: "data/a", body: "a"},
+				{name: "data", body: "x"},
+			},
+			wantErr: "cannot replace directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "dest")
+			outside := filepath.Join(parent, "outside")
+			if err := os.WriteFile(outside, []byte("outside"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := ExtractTar(bytes.NewReader(makeTar(t, tt.entries...)), dest, ExtractOptions{})
+			switch {
+			case tt.wantUnsafe:
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Fatalf("got %v, want ErrUnsafeArchive", err)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			for name, want := range tt.want {
+				path := filepath.Join(dest, name)
+				var got string
+				if link, err := os.Readlink(path); err == nil {
+					got = "-> " + link
+				} else if data, err := os.ReadFile(path); err == nil {
+					got = string(data)
+				} else {
+					t.Errorf("%s not extracted: %v", name, err)
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			checkConfined(t, parent, dest)
+		})
+	}
+}
+
+// checkConfined fails if anything but dest and the file "outside" exists in
+// parent, or if a symlink in dest points out of it
+func checkConfined(t *testing.T, parent, dest string) {
+	t.Helper()
+	if data, err := os.ReadFile(filepath.Join(parent, "outside")); err != nil || string(data) != "outside" {
+		t.Errorf("file outside the destination changed: %q, %v", data, err)
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "dest" && e.Name() != "outside" {
+			t.Errorf("%s written outside the destination", e.Name())
+		}
+	}
+	filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		link, err := os.Readlink(path)
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		if filepath.IsAbs(link) || !withinDir(dest, filepath.Join(filepath.Dir(path), link)) {
+			t.Errorf("symlink %s -> %s points out of the destination", path, link)
+		}
+		return nil
+	})
+}
+
+func FuzzExtract(f *testing.F) {
+	pterm.DisableOutput()
+	seeds := [][]entry{
+		{{name: "data/", typ: tar.TypeDir}, {name: "data/a", body: "a"}, {name: "data/l", typ: tar.TypeSymlink, link: "a"}},
+		{{name: "../evil", body: "x"}},
+		{{name: "link", typ: tar.TypeSymlink, link: "."}, {name: "link/a", body: "x"}},
+		{{name: "up", typ: tar.TypeSymlink, link: ".."}, {name: "up/outside", body: "x"}},
+		{{name: "a", body: "a"}, {name: "h", typ: tar.TypeLink, link: "a"}, {name: "h2", typ: tar.TypeLink, link: "../outside"}},
+	}
+	for _, s := range seeds {
+		f.Add(makeTar(f, s...))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parent := t.TempDir()
+		dest := filepath.Join(parent, "dest")
+		if err := os.WriteFile(filepath.Join(parent, "outside"), []byte("outside"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Errors are expected; escaping dest is not
+		ExtractTar(bytes.NewReader(data), dest, ExtractOptions{})
+		checkConfined(t, parent, dest)
+	})
+}
@@ -1,145 +1,60 @@
 package file
 
 import (
-	"archive/tar"
 	"bufio"
-	"bytes"
-	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"time"
 
-	"github.com/pierrec/lz4/v4"
 	"github.com/pterm/pterm"
-	"github.com/vbauerster/mpb/v7"
-	"github.com/vbauerster/mpb/v7/decor"
+
+	"github.com/sSelmann/storycli/utils/executor"
 )
 
-// Helper function to decompress LZ4 and extract tar
-func DecompressAndExtractLz4Tar(lz4Path, destDir string) error {
-	// Open the LZ4 file
-	file, err := os.Open(lz4Path)
+// ExtractArchiveFile extracts a tar archive compressed with lz4, zstd, gzip
+// or not at all
+func ExtractArchiveFile(archivePath, destDir string, opts ExtractOptions) error {
+	// Open the archive
+	file, err := os.Open(archivePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	// Create LZ4 reader
-	lz4Reader := lz4.NewReader(file)
-
-	// Create tar reader
-	tarReader := tar.NewReader(lz4Reader)
-
-	// Iterate through the files in the tar archive
-	for {
-		header, err := tarReader.Next()
+	return ExtractArchive(file, filepath.Base(archivePath), destDir, opts)
+}
 
-		if err == io.EOF {
-			break // End of tar archive
+// DownloadVerified downloads url to dest with aria2c when it is installed,
+// falling back to the resumable HTTP downloader, and checks the result
+// against the size and checksum published by the server. With strict set a
+// mismatch is an error instead of a warning. Progress is written to progress
+// (stdout if nil).
+func DownloadVerified(url, dest string, strict bool, progress io.Writer) error {
+	expected := FetchExpected(url)
+
+	if _, err := exec.LookPath("aria2c"); err == nil {
+		var checksum string
+		if strict {
+			checksum = expected.SHA256
 		}
-		if err != nil {
+		if err := downloadWithAria2(url, dest, checksum, progressOutput(progress)); err != nil {
 			return err
 		}
-
-		// Determine proper file path
-		target := filepath.Join(destDir, header.Name)
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			// Create directory
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			// Create file
-			outFile, err := os.Create(target)
-			if err != nil {
-				return err
-			}
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close()
-				return err
-			}
-			outFile.Close()
-
-			// Set permissions
-			if err := os.Chmod(target, os.FileMode(header.Mode)); err != nil {
-				return err
-			}
-		default:
-			// Unsupported type
-			pterm.Warning.Printf(fmt.Sprintf("Unsupported file type: %v in %s", header.Typeflag, header.Name))
+	} else {
+		if err := DownloadFileResumable(url, dest, DownloadOptions{Retries: 3, Progress: progress}); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return CheckDownload(dest, expected, strict)
 }
 
-// Helper function to download a file with a progress bar
-func DownloadFileWithProgress(url, dest string) error {
-	// Create the file
-	out, err := os.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	// Get the data
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	// Check server response
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
-
-	// Get the size
-	sizeStr := resp.Header.Get("Content-Length")
-	size, err := strconv.Atoi(sizeStr)
-	if err != nil || size <= 0 {
-		return errors.New("invalid Content-Length")
-	}
-
-	// Create progress bar
-	p := mpb.New(
-		mpb.WithWidth(64),
-		mpb.WithRefreshRate(180*time.Millisecond),
-	)
-	bar := p.AddBar(int64(size),
-		mpb.PrependDecorators(
-			decor.Name("Downloading:", decor.WC{W: len("Downloading: "), C: decor.DidentRight}),
-			decor.CountersKibiByte("% .2f / % .2f"),
-		),
-		mpb.AppendDecorators(
-			decor.Percentage(decor.WC{W: 5}),
-		),
-	)
-
-	// Create a proxy reader
-	reader := bar.ProxyReader(resp.Body)
-	defer reader.Close()
-
-	// Write the body to file
-	_, err = io.Copy(out, reader)
-	if err != nil {
-		return err
-	}
-
-	p.Wait()
-
-	return nil
-}
-
-func DownloadFileWithAria2(url, dest string) error {
+// downloadWithAria2 runs aria2c, continuing a previous partial download of
+// dest. A non-empty sha256 makes aria2c verify the file itself.
+func downloadWithAria2(url, dest, sha256 string, out io.Writer) error {
 	// Check if aria2c is installed
 	_, err := exec.LookPath("aria2c")
 	if err != nil {
@@ -147,12 +62,8 @@ func DownloadFileWithAria2(url, dest string) error {
 		pterm.Info.Println("Attempting to install aria2...")
 
 		// Attempt to install aria2
-		installCmd := exec.Command("sudo", "apt-get", "install", "aria2", "-y")
-		var stderr bytes.Buffer
-		installCmd.Stderr = &stderr
-
-		if err := installCmd.Run(); err != nil {
-			return fmt.Errorf("failed to install aria2: %v\nDetails: %s", err, stderr.String())
+		if err := executor.Run(executor.Cmd("sudo", "apt-get", "install", "aria2", "-y")); err != nil {
+			return fmt.Errorf("failed to install aria2: %v", err)
 		}
 	}
 
@@ -164,46 +75,44 @@ func DownloadFileWithAria2(url, dest string) error {
 		"--enable-color=true",       // Disable colors for cleaner output
 		"--console-log-level=error", // Suppress logs except errors
 		"--summary-interval=1",      // Update progress every second
+		"--continue=true",           // Resume partial downloads
+		"--auto-file-renaming=false",
 		"--dir=" + filepath.Dir(dest),
 		"--out=" + filepath.Base(dest),
-		url,
 	}
-
-	cmd := exec.Command("aria2c", cmdArgs...)
-
-	// Capture stdout and filter progress lines
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to capture stdout: %v", err)
+	if sha256 != "" {
+		cmdArgs = append(cmdArgs, "--checksum=sha-256="+sha256)
 	}
-
-	cmd.Stderr = os.Stderr // Show errors if any
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start aria2c: %v", err)
-	}
-
-	// Filter progress lines and clean output
-	scanner := bufio.NewScanner(stdout)
-	lastLine := ""
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "[#") { // Filter only progress lines
-			// Clean and update the line
-			lastLine = strings.TrimSpace(line)
-			fmt.Printf("\r%-80s", lastLine) // Clear previous line and overwrite
+	cmdArgs = append(cmdArgs, url)
+
+	// Filter progress lines from stdout and clean output
+	stdout, progress := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "[#") { // Filter only progress lines
+				fmt.Fprintf(out, "\r%-80s", strings.TrimSpace(line)) // Clear previous line and overwrite
+			}
 		}
-	}
+		// Keep aria2c from blocking on output that is no longer read
+		io.Copy(io.Discard, stdout)
+	}()
 
-	// Print final progress summary after completion
-	fmt.Printf("\r%-80s\n", "") // Clear line and show completion message
-	pterm.Info.Println("Download complete!")
+	cmd := executor.Cmd("aria2c", cmdArgs...)
+	cmd.Stdout = progress
+	cmd.Stderr = os.Stderr // Show errors if any
+	err = executor.Run(cmd)
+	progress.Close()
+	<-done
 
-	// Wait for the command to finish
-	if err := cmd.Wait(); err != nil {
+	// Clear the progress line
+	fmt.Fprintf(out, "\r%-80s\n", "")
+	if err != nil {
 		return fmt.Errorf("aria2c failed: %v", err)
 	}
-
+	pterm.Info.Println("Download complete!")
 	return nil
 }
@@ -0,0 +1,147 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+// streamRetries is how often a dropped connection is resumed while streaming
+const streamRetries = 5
+
+// StreamExtractArchive downloads a tar archive (compressed with lz4, zstd,
+// gzip or not at all) from url and extracts it into destDir while it is being downloaded, so no temporary
+// archive is written to disk. Progress is reported on the compressed bytes.
+// A dropped connection is resumed with an HTTP Range request.
+func StreamExtractArchive(url, destDir string, opts ExtractOptions, progress io.Writer) error {
+	body, err := openResumingReader(http.DefaultClient, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var reader io.Reader = body
+	var p *mpb.Progress
+	var bar *mpb.Bar
+	if body.size > 0 {
+		p = mpb.New(
+			mpb.WithOutput(progressOutput(progress)),
+			mpb.WithWidth(64),
+			mpb.WithRefreshRate(180*time.Millisecond),
+		)
+		bar = p.AddBar(body.size,
+			mpb.PrependDecorators(
+				decor.Name("Downloading & extracting:", decor.WC{W: len("Downloading & extracting: "), C: decor.DidentRight}),
+				decor.CountersKibiByte("% .2f / % .2f"),
+			),
+			mpb.AppendDecorators(
+				decor.AverageSpeed(decor.UnitKiB, "% .1f", decor.WC{W: 12}),
+				decor.Percentage(decor.WC{W: 5}),
+			),
+		)
+		proxy := bar.ProxyReader(body)
+		defer proxy.Close()
+		reader = proxy
+	}
+
+	err = ExtractArchive(reader, ArchiveName(url, ""), destDir, opts)
+	if err == nil {
+		// tar stops at its end marker; read the trailing padding so the
+		// transfer completes
+		_, err = io.Copy(io.Discard, reader)
+	}
+	if p != nil {
+		if err != nil {
+			bar.Abort(false)
+		}
+		p.Wait()
+	}
+	return err
+}
+
+// resumingReader is an HTTP response body that transparently reopens the
+// connection at the current offset when it drops.
+type resumingReader struct {
+	client  *http.Client
+	url     string
+	etag    string
+	size    int64
+	offset  int64
+	retries int
+	body    io.ReadCloser
+}
+
+func openResumingReader(client *http.Client, url string) (*resumingReader, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("bad status: %s", resp.Status)
+	}
+	return &resumingReader{
+		client: client,
+		url:    url,
+		etag:   resp.Header.Get("ETag"),
+		size:   resp.ContentLength,
+		body:   resp.Body,
+	}, nil
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || (err == io.EOF && (r.size <= 0 || r.offset >= r.size)) {
+			return n, err
+		}
+		if n > 0 {
+			// Hand out what we got, the error shows up again on the next read
+			return n, nil
+		}
+		if r.retries >= streamRetries || r.size <= 0 {
+			return 0, err
+		}
+		r.retries++
+		pterm.Warning.Printf("Connection dropped at %d bytes (%v), resuming (attempt %d/%d)...\n", r.offset, err, r.retries, streamRetries)
+		if rerr := r.reopen(); rerr != nil {
+			return 0, fmt.Errorf("failed to resume download: %w", rerr)
+		}
+	}
+}
+
+// reopen requests the rest of the file starting at the current offset
+func (r *resumingReader) reopen() error {
+	r.body.Close()
+	time.Sleep(time.Duration(r.retries) * time.Second)
+
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	if r.etag != "" {
+		req.Header.Set("If-Range", r.etag)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return fmt.Errorf("server did not resume the download: %s", resp.Status)
+	}
+	r.body = resp.Body
+	return nil
+}
+
+func (r *resumingReader) Close() error {
+	return r.body.Close()
+}
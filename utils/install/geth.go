@@ -0,0 +1,31 @@
+package install
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// GethRepo is the GitHub repository geth is released from.
+const GethRepo = "piplabs/story-geth"
+
+// GethSource installs geth from its releases.
+var GethSource = Source{Binary: Geth, Repo: GethRepo, Build: BuildGeth}
+
+// BuildGeth checks out story-geth at version in workDir and builds geth to
+// outPath. It needs git, Go and a C compiler.
+func BuildGeth(version, workDir, outPath string) error {
+	src := filepath.Join(workDir, "story-geth")
+	if err := executor.Active().RemoveAll(src); err != nil {
+		return err
+	}
+
+	if err := run(workDir, "git", "clone", "--quiet", "--depth", "1", "--branch", version, "https://github.com/"+GethRepo, src); err != nil {
+		return fmt.Errorf("failed to clone geth %s: %v", version, err)
+	}
+	if err := run(src, "go", "build", "-o", outPath, "./cmd/geth"); err != nil {
+		return fmt.Errorf("failed to build geth %s: %v", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package install
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/file"
+)
+
+// ErrNoChecksum is returned when a release publishes no checksum for the
+// binary being installed.
+var ErrNoChecksum = errors.New("no published checksum")
+
+// Method is how a binary gets installed.
+type Method string
+
+const (
+	// MethodRelease downloads the binary published with the release,
+	// building from source if the release has none for the platform.
+	MethodRelease Method = "release"
+	// MethodSource always builds the binary from the release tag.
+	MethodSource Method = "source"
+)
+
+// Methods lists the install methods, for flag completion and help.
+var Methods = []Method{MethodRelease, MethodSource}
+
+// ParseMethod checks an --install-method value.
+func ParseMethod(s string) (Method, error) {
+	for _, m := range Methods {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown install method %q (use %s or %s)", s, MethodRelease, MethodSource)
+}
+
+// Source is where a binary comes from: the GitHub repository it is
+// released from, and how to build it from a release tag. Release binaries
+// are named <binary>-<os>-<arch>, e.g. story-linux-arm64.
+type Source struct {
+	Binary string
+	Repo   string
+	Build  func(version, workDir, outPath string) error
+}
+
+// archAliases lists the names releases use for a GOARCH
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64"},
+	"arm64": {"arm64", "aarch64"},
+}
+
+// Installer installs a binary for one platform. OS and Arch default to the
+// platform scli runs on and Index to GitHub; tests can inject their own.
+type Installer struct {
+	Source Source
+	Method Method
+	OS     string
+	Arch   string
+	Index  ReleaseIndex
+	// SkipVerify allows release binaries without a published checksum.
+	SkipVerify bool
+}
+
+// NewInstaller returns an installer of source for the running platform.
+func NewInstaller(source Source, method Method, skipVerify bool) Installer {
+	return Installer{
+		Source:     source,
+		Method:     method,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Index:      GitHubReleases{},
+		SkipVerify: skipVerify,
+	}
+}
+
+// Plan is how a version will be installed.
+type Plan struct {
+	Binary  string
+	Version string
+	Method  Method
+	// Asset and SHA256 are set for MethodRelease. SHA256 is empty if the
+	// release publishes no checksum and verification was skipped.
+	Asset  ReleaseAsset
+	SHA256 string
+	// Platform is the OS/arch the plan was made for.
+	Platform string
+	// NoAsset is set when a release binary was wanted but the release has
+	// none for the platform.
+	NoAsset bool
+}
+
+// String describes the plan for the setup and update output.
+func (p Plan) String() string {
+	switch {
+	case p.Method == MethodSource && p.NoAsset:
+		return fmt.Sprintf("building %s %s from source (the release has no binary for %s)", p.Binary, p.Version, p.Platform)
+	case p.Method == MethodSource:
+		return fmt.Sprintf("building %s %s from source", p.Binary, p.Version)
+	case p.SHA256 == "":
+		return fmt.Sprintf("downloading release binary %s of %s %s (unverified)", p.Asset.Name, p.Binary, p.Version)
+	}
+	return fmt.Sprintf("downloading release binary %s of %s %s (sha256 %s)", p.Asset.Name, p.Binary, p.Version, p.SHA256)
+}
+
+// Resolve decides how to install version. With MethodRelease it looks up the
+// release asset for the installer's platform and its checksum, falling back
+// to a source build when the release has no asset for the platform.
+func (in Installer) Resolve(version string) (Plan, error) {
+	plan := Plan{
+		Binary:   in.Source.Binary,
+		Version:  version,
+		Method:   MethodSource,
+		Platform: in.OS + "/" + in.Arch,
+	}
+	if in.Method == MethodSource {
+		return plan, nil
+	}
+
+	assets, err := in.Index.Assets(in.Source.Repo, version)
+	if err != nil {
+		return plan, err
+	}
+
+	asset, ok := in.findAsset(assets)
+	if !ok {
+		plan.NoAsset = true
+		return plan, nil
+	}
+	plan.Method = MethodRelease
+	plan.Asset = asset
+
+	sum, err := in.checksum(version, assets, asset)
+	switch {
+	case err == nil:
+		plan.SHA256 = sum
+	case errors.Is(err, ErrNoChecksum) && in.SkipVerify:
+		pterm.Warning.Printf("Not verifying %s %s: %v\n", plan.Binary, version, err)
+	default:
+		return plan, fmt.Errorf("cannot verify %s %s: %w", plan.Binary, version, err)
+	}
+	return plan, nil
+}
+
+// Install carries out plan, writing the binary to outPath. Source builds
+// check the code out in workDir.
+func (in Installer) Install(plan Plan, workDir, outPath string) error {
+	if plan.Method == MethodSource {
+		return in.Source.Build(plan.Version, workDir, outPath)
+	}
+
+	opts := file.DownloadOptions{Expected: file.Expected{SHA256: plan.SHA256}, Retries: 3}
+	err := executor.Perform("download "+plan.Asset.DownloadURL+" to "+outPath, func() error {
+		return file.DownloadFileResumable(plan.Asset.DownloadURL, outPath, opts)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download %s %s: %v", plan.Binary, plan.Version, err)
+	}
+	return executor.Active().Chmod(outPath, 0755)
+}
+
+// findAsset returns the binary for the installer's platform, named like
+// geth-linux-arm64
+func (in Installer) findAsset(assets []ReleaseAsset) (ReleaseAsset, bool) {
+	aliases, ok := archAliases[in.Arch]
+	if !ok {
+		aliases = []string{in.Arch}
+	}
+	for _, arch := range aliases {
+		name := fmt.Sprintf("%s-%s-%s", in.Source.Binary, in.OS, arch)
+		for _, a := range assets {
+			if a.Name == name {
+				return a, true
+			}
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+// checksum returns the SHA256 of asset from a checksum file among the
+// release assets, e.g. SHA256SUMS or geth-linux-arm64.sha256. The digest
+// GitHub computes for an asset is not used: it only shows the file wasn't
+// corrupted on its way, not that it is what the release published.
+func (in Installer) checksum(version string, assets []ReleaseAsset, asset ReleaseAsset) (string, error) {
+	for _, a := range assets {
+		if !isChecksumFile(a.Name) {
+			continue
+		}
+		sums, err := in.fetchChecksums(a)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", a.Name, err)
+		}
+		if sum, ok := sums[asset.Name]; ok {
+			return sum, nil
+		}
+		// A per-file checksum may list the hash without a file name
+		if sum, ok := sums[""]; ok && a.Name == asset.Name+".sha256" {
+			return sum, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w for %s in release %s of %s", ErrNoChecksum, asset.Name, version, in.Source.Repo)
+}
+
+func (in Installer) fetchChecksums(asset ReleaseAsset) (map[string]string, error) {
+	body, err := in.Index.Open(asset)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ParseChecksums(io.LimitReader(body, 1<<20)), nil
+}
+
+func isChecksumFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".sha256") || strings.Contains(lower, "sha256sums") || strings.Contains(lower, "checksums")
+}
+
+// ParseChecksums reads lines in the sha256sum format, "<hex>  <file name>",
+// into a map from file name to checksum. A line with only a checksum is
+// stored under "".
+func ParseChecksums(r io.Reader) map[string]string {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !isSHA256(fields[0]) {
+			continue
+		}
+		name := ""
+		if len(fields) > 1 {
+			// sha256sum marks binary mode with a leading "*"
+			name = strings.TrimPrefix(fields[1], "*")
+		}
+		sums[name] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,133 @@
+package install
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+)
+
+// fakeIndex serves the assets of one release; files holds the content of
+// the checksum files
+type fakeIndex struct {
+	assets []ReleaseAsset
+	files  map[string]string
+}
+
+func (f fakeIndex) Assets(repo, version string) ([]ReleaseAsset, error) {
+	if repo != GethRepo || version != "v1.0.2" {
+		return nil, errors.New("release not found")
+	}
+	return f.assets, nil
+}
+
+func (f fakeIndex) Open(asset ReleaseAsset) (io.ReadCloser, error) {
+	content, ok := f.files[asset.Name]
+	if !ok {
+		return nil, errors.New("asset not found")
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+// asset returns a release asset named name
+func asset(name string) ReleaseAsset {
+	return ReleaseAsset{Name: name, DownloadURL: "https://releases.test/" + name}
+}
+
+func TestInstallerResolve(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	const (
+		sumAMD64 = "1111111111111111111111111111111111111111111111111111111111111111"
+		sumARM64 = "2222222222222222222222222222222222222222222222222222222222222222"
+		sumX8664 = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	)
+	sums := fakeIndex{
+		assets: []ReleaseAsset{asset("geth-linux-amd64"), asset("geth-linux-arm64"), asset("SHA256SUMS")},
+		files:  map[string]string{"SHA256SUMS": sumAMD64 + "  geth-linux-amd64\n" + sumARM64 + " *geth-linux-arm64\n"},
+	}
+	aliases := fakeIndex{
+		assets: []ReleaseAsset{asset("geth-linux-x86_64"), asset("geth-linux-aarch64"), asset("checksums.txt"), asset("geth-linux-aarch64.sha256")},
+		files: map[string]string{
+			"checksums.txt":             sumX8664 + "  geth-linux-x86_64\n",
+			"geth-linux-aarch64.sha256": sumARM64 + "\n",
+		},
+	}
+	unsigned := fakeIndex{assets: []ReleaseAsset{asset("geth-linux-amd64")}}
+
+	tests := []struct {
+		name       string
+		arch       string
+		method     Method
+		index      fakeIndex
+		skipVerify bool
+		// wantAsset is empty for a source build
+		wantAsset  string
+		wantSHA256 string
+		wantErr    error
+	}{
+		{name: "amd64", arch: "amd64", index: sums, wantAsset: "geth-linux-amd64", wantSHA256: sumAMD64},
+		{name: "arm64", arch: "arm64", index: sums, wantAsset: "geth-linux-arm64", wantSHA256: sumARM64},
+		{name: "x86_64 alias", arch: "amd64", index: aliases, wantAsset: "geth-linux-x86_64", wantSHA256: strings.ToLower(sumX8664)},
+		{name: "aarch64 alias with a per-file checksum", arch: "arm64", index: aliases, wantAsset: "geth-linux-aarch64", wantSHA256: sumARM64},
+		{name: "no binary for the platform", arch: "riscv64", index: sums},
+		{name: "source method", arch: "amd64", method: MethodSource, index: sums},
+		{name: "checksum missing", arch: "amd64", index: unsigned, wantErr: ErrNoChecksum},
+		{name: "checksum missing with --skip-verify", arch: "amd64", index: unsigned, skipVerify: true, wantAsset: "geth-linux-amd64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = MethodRelease
+			}
+			in := Installer{Source: GethSource, Method: method, OS: "linux", Arch: tt.arch, Index: tt.index, SkipVerify: tt.skipVerify}
+
+			plan, err := in.Resolve("v1.0.2")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Platform != "linux/"+tt.arch {
+				t.Errorf("platform %s, want linux/%s", plan.Platform, tt.arch)
+			}
+			if tt.wantAsset == "" {
+				if plan.Method != MethodSource {
+					t.Errorf("method %s, want a source build", plan.Method)
+				}
+				if plan.NoAsset != (method == MethodRelease) {
+					t.Errorf("NoAsset = %v", plan.NoAsset)
+				}
+				return
+			}
+			if plan.Method != MethodRelease || plan.Asset.Name != tt.wantAsset {
+				t.Errorf("got %s %s, want release asset %s", plan.Method, plan.Asset.Name, tt.wantAsset)
+			}
+			if plan.SHA256 != tt.wantSHA256 {
+				t.Errorf("sha256 %q, want %q", plan.SHA256, tt.wantSHA256)
+			}
+		})
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	got := ParseChecksums(strings.NewReader("# comment\n" + strings.ToUpper(sum) + "  story-linux-amd64\n" + sum + " *story-linux-arm64\nnot-a-sum  x\n" + sum + "\n"))
+	want := map[string]string{"story-linux-amd64": sum, "story-linux-arm64": sum, "": sum}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, s := range want {
+		if got[name] != s {
+			t.Errorf("%q: got %q, want %q", name, got[name], s)
+		}
+	}
+}
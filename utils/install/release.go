@@ -0,0 +1,62 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseIndex looks up the published releases of a repository.
+type ReleaseIndex interface {
+	// Assets lists the assets of the release tagged version.
+	Assets(repo, version string) ([]ReleaseAsset, error)
+	// Open returns the content of an asset, e.g. a checksum file.
+	Open(asset ReleaseAsset) (io.ReadCloser, error)
+}
+
+// GitHubReleases is the ReleaseIndex of GitHub.
+type GitHubReleases struct{}
+
+var releaseClient = &http.Client{Timeout: 15 * time.Second}
+
+// Assets lists the assets of a GitHub release through the REST API.
+func (GitHubReleases) Assets(repo, version string) ([]ReleaseAsset, error) {
+	resp, err := releaseClient.Get(fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repo, version))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch release %s of %s: %s", version, repo, resp.Status)
+	}
+
+	var release struct {
+		Assets []ReleaseAsset `json:"assets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release %s of %s: %v", version, repo, err)
+	}
+	return release.Assets, nil
+}
+
+// Open downloads asset.
+func (GitHubReleases) Open(asset ReleaseAsset) (io.ReadCloser, error) {
+	resp, err := releaseClient.Get(asset.DownloadURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: got non-OK status code %d", asset.Name, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,382 @@
+package install
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+	"github.com/sSelmann/storycli/utils/file"
+)
+
+// Binaries managed by the store
+const (
+	Story = "story"
+	Geth  = "geth"
+)
+
+// unmanagedVersion names a binary that was installed before scli managed the
+// bin dir. It is kept in the store so an update can be rolled back to it.
+const unmanagedVersion = "unmanaged"
+
+// Store keeps every installed version of the story and geth binaries in
+// <home>/versions/<binary>/<version>/<binary>. The binaries in the bin dir
+// are symlinks into the store, switched atomically, and the record in
+// <home>/versions/installed.json remembers the current and previous version
+// of each, and the SHA256 of every stored binary.
+type Store struct {
+	Root   string
+	BinDir string
+}
+
+// Record is the content of installed.json.
+type Record struct {
+	Binaries map[string]*Installed `json:"binaries"`
+	// SHA256 maps binary and version to the checksum taken when the
+	// version was added to the store.
+	SHA256 map[string]map[string]string `json:"sha256"`
+}
+
+// Checksum returns the recorded SHA256 of version of binary.
+func (r *Record) Checksum(binary, version string) string {
+	return r.SHA256[binary][version]
+}
+
+func (r *Record) setChecksum(binary, version, sum string) {
+	if r.SHA256[binary] == nil {
+		r.SHA256[binary] = map[string]string{}
+	}
+	r.SHA256[binary][version] = sum
+}
+
+// Installed is the install state of one binary.
+type Installed struct {
+	Current   string    `json:"current"`
+	Previous  string    `json:"previous,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewStore returns the store of node.
+func NewStore(node config.Profile) Store {
+	return Store{
+		Root:   filepath.Join(node.HomeDir, "versions"),
+		BinDir: node.BinDir,
+	}
+}
+
+// Path returns where version of binary is kept.
+func (s Store) Path(binary, version string) string {
+	return filepath.Join(s.Root, binary, version, binary)
+}
+
+// Has reports whether version of binary is in the store.
+func (s Store) Has(binary, version string) bool {
+	_, err := os.Stat(s.Path(binary, version))
+	return err == nil
+}
+
+// Add puts version of binary into the store and records its checksum.
+// fetch writes the binary to the path it is given; it only becomes visible
+// in the store once fetch succeeded. A version that is already stored is
+// not fetched again, but has to match its recorded checksum.
+func (s Store) Add(binary, version string, fetch func(path string) error) error {
+	if s.Has(binary, version) {
+		// A version without a recorded checksum gets one, which writes the record
+		return executor.Perform(fmt.Sprintf("check %s against its recorded sha256", s.Path(binary, version)), func() error {
+			return s.checkStored(binary, version)
+		})
+	}
+
+	ex := executor.Active()
+	final := s.Path(binary, version)
+	if err := ex.MkdirAll(filepath.Dir(final), 0755); err != nil {
+		return err
+	}
+	tmp := final + ".tmp"
+	ex.RemoveAll(tmp)
+	if err := fetch(tmp); err != nil {
+		ex.RemoveAll(tmp)
+		return err
+	}
+	return executor.Perform(fmt.Sprintf("store %s as %s and record its sha256", tmp, final), func() error {
+		if err := os.Chmod(tmp, 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, final); err != nil {
+			return err
+		}
+		return s.recordChecksum(binary, version)
+	})
+}
+
+// checkStored compares a stored version with its recorded checksum. A
+// version stored before checksums were recorded gets its checksum now.
+func (s Store) checkStored(binary, version string) error {
+	record, err := s.LoadRecord()
+	if err != nil {
+		return err
+	}
+	want := record.Checksum(binary, version)
+	if want == "" {
+		return s.recordChecksum(binary, version)
+	}
+	err = file.VerifyFile(s.Path(binary, version), file.Expected{SHA256: want})
+	if err != nil {
+		return fmt.Errorf("stored %s %s was changed: %v", binary, version, err)
+	}
+	return nil
+}
+
+// recordChecksum stores the SHA256 of version of binary in the record
+func (s Store) recordChecksum(binary, version string) error {
+	sum, err := file.SHA256Sum(s.Path(binary, version))
+	if err != nil {
+		return err
+	}
+	record, err := s.LoadRecord()
+	if err != nil {
+		return err
+	}
+	record.setChecksum(binary, version, sum)
+	return s.SaveRecord(record)
+}
+
+// Activate points the binary in the bin dir at version and records it as
+// current, with the version it replaces as previous. A version that no
+// longer matches its recorded checksum is refused.
+func (s Store) Activate(binary, version string) error {
+	link := filepath.Join(s.BinDir, binary)
+	return executor.Perform(fmt.Sprintf("link %s to %s", link, s.Path(binary, version)), func() error {
+		return s.activate(binary, version)
+	})
+}
+
+func (s Store) activate(binary, version string) error {
+	if !s.Has(binary, version) {
+		return fmt.Errorf("%s %s is not installed", binary, version)
+	}
+	if err := s.checkStored(binary, version); err != nil {
+		return err
+	}
+
+	record, err := s.LoadRecord()
+	if err != nil {
+		return err
+	}
+	installed := record.Binaries[binary]
+	if installed == nil {
+		installed = &Installed{}
+		record.Binaries[binary] = installed
+	}
+	if installed.Current == "" {
+		// Keep a binary installed before scli managed it, so the switch
+		// can be rolled back
+		adopted, err := s.adopt(binary)
+		if err != nil {
+			return err
+		}
+		if adopted != "" {
+			sum, err := file.SHA256Sum(s.Path(binary, adopted))
+			if err != nil {
+				return err
+			}
+			record.setChecksum(binary, adopted, sum)
+		}
+		installed.Current = adopted
+	}
+
+	if err := s.link(binary, version); err != nil {
+		return err
+	}
+
+	if installed.Current != version {
+		installed.Previous = installed.Current
+	}
+	installed.Current = version
+	installed.UpdatedAt = time.Now().UTC()
+	return s.SaveRecord(record)
+}
+
+// link switches the bin dir symlink of binary to version atomically
+func (s Store) link(binary, version string) error {
+	if err := os.MkdirAll(s.BinDir, 0755); err != nil {
+		return err
+	}
+	link := filepath.Join(s.BinDir, binary)
+	tmp := link + ".scli-tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(s.Path(binary, version), tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// adopt moves a regular binary in the bin dir into the store and returns
+// the version it was stored as, or "" if there is none
+func (s Store) adopt(binary string) (string, error) {
+	link := filepath.Join(s.BinDir, binary)
+	info, err := os.Lstat(link)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", nil
+	}
+
+	dest := s.Path(binary, unmanagedVersion)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(link, dest); err != nil {
+		return "", err
+	}
+	// Leave a working binary in place until the new link replaces it
+	if err := s.link(binary, unmanagedVersion); err != nil {
+		return "", err
+	}
+	return unmanagedVersion, nil
+}
+
+// Rollback switches binary back to its previous version.
+func (s Store) Rollback(binary string) (string, error) {
+	record, err := s.LoadRecord()
+	if err != nil {
+		return "", err
+	}
+	installed := record.Binaries[binary]
+	if installed == nil || installed.Previous == "" {
+		return "", fmt.Errorf("no previous %s version recorded", binary)
+	}
+	previous := installed.Previous
+	return previous, s.Activate(binary, previous)
+}
+
+func (s Store) recordPath() string {
+	return filepath.Join(s.Root, "installed.json")
+}
+
+// LoadRecord reads installed.json; a missing file is an empty record.
+func (s Store) LoadRecord() (*Record, error) {
+	record := &Record{Binaries: map[string]*Installed{}, SHA256: map[string]map[string]string{}}
+	data, err := os.ReadFile(s.recordPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return record, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", s.recordPath(), err)
+	}
+	if record.Binaries == nil {
+		record.Binaries = map[string]*Installed{}
+	}
+	if record.SHA256 == nil {
+		record.SHA256 = map[string]map[string]string{}
+	}
+	return record, nil
+}
+
+// SaveRecord writes installed.json atomically.
+func (s Store) SaveRecord(record *Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Root, 0755); err != nil {
+		return err
+	}
+	tmp := s.recordPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.recordPath())
+}
+
+// Check is the result of verifying one stored binary.
+type Check struct {
+	Binary  string
+	Version string
+	Path    string
+	// Active is set for the version the bin dir links to.
+	Active bool
+	// Problem describes what is wrong; it is empty for a binary that
+	// matches its recorded checksum.
+	Problem string
+}
+
+// Verify compares every stored binary with its recorded checksum and checks
+// that the bin dir still links to the current version of each binary.
+func (s Store) Verify() ([]Check, error) {
+	record, err := s.LoadRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []Check
+	for _, binary := range []string{Story, Geth} {
+		installed := record.Binaries[binary]
+		if installed == nil && len(record.SHA256[binary]) == 0 {
+			continue
+		}
+
+		versions := make([]string, 0, len(record.SHA256[binary]))
+		for version := range record.SHA256[binary] {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+
+		for _, version := range versions {
+			check := Check{Binary: binary, Version: version, Path: s.Path(binary, version)}
+			check.Active = installed != nil && installed.Current == version
+			err := file.VerifyFile(check.Path, file.Expected{SHA256: record.Checksum(binary, version)})
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				check.Problem = "missing"
+			case errors.Is(err, file.ErrVerification):
+				check.Problem = "checksum mismatch"
+			case err != nil:
+				check.Problem = err.Error()
+			}
+			checks = append(checks, check)
+		}
+
+		if installed != nil && installed.Current != "" {
+			checks = append(checks, s.checkLink(binary, installed.Current, record))
+		}
+	}
+	return checks, nil
+}
+
+// checkLink checks that the bin dir entry of binary is the store's symlink
+// to version
+func (s Store) checkLink(binary, version string, record *Record) Check {
+	link := filepath.Join(s.BinDir, binary)
+	check := Check{Binary: binary, Version: version, Path: link, Active: true}
+
+	target, err := os.Readlink(link)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		check.Problem = "missing"
+	case err != nil:
+		check.Problem = "replaced outside scli (not a symlink into the store)"
+		if sum, err := file.SHA256Sum(link); err == nil && sum != record.Checksum(binary, version) {
+			check.Problem = "replaced outside scli (unknown checksum " + sum + ")"
+		}
+	case target != s.Path(binary, version):
+		check.Problem = "points to " + target + " instead of " + s.Path(binary, version)
+	}
+	return check
+}
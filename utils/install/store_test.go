@@ -0,0 +1,58 @@
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+func TestStoreAddStoredWithoutChecksum(t *testing.T) {
+	home := t.TempDir()
+	store := NewStore(config.Profile{HomeDir: home, BinDir: filepath.Join(home, "bin")})
+	path := store.Path(Geth, "v1.0.2")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("geth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fetch := func(string) error {
+		t.Fatal("a stored version was fetched again")
+		return nil
+	}
+
+	// A dry run leaves the record alone
+	rec := executor.NewRecorder()
+	active := executor.Active()
+	executor.SetActive(rec)
+	err := store.Add(Geth, "v1.0.2", fetch)
+	executor.SetActive(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rec.Commands(), "\n"); got != "check "+path+" against its recorded sha256" {
+		t.Errorf("recorded calls %q", got)
+	}
+	if _, err := os.Stat(store.recordPath()); !os.IsNotExist(err) {
+		t.Errorf("the record was written on a dry run: %v", err)
+	}
+
+	// A real run records the checksum
+	if err := store.Add(Geth, "v1.0.2", fetch); err != nil {
+		t.Fatal(err)
+	}
+	record, err := store.LoadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("geth"))
+	if got, want := record.Checksum(Geth, "v1.0.2"), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("recorded checksum %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// StoryRepo is the GitHub repository story is released from.
+const StoryRepo = "piplabs/story"
+
+// StorySource installs story from its releases.
+var StorySource = Source{Binary: Story, Repo: StoryRepo, Build: BuildStory}
+
+// BuildStory checks out story at version (a release tag) in workDir and
+// builds the binary to outPath. It needs git and Go.
+func BuildStory(version, workDir, outPath string) error {
+	src := filepath.Join(workDir, "story")
+	if err := executor.Active().RemoveAll(src); err != nil {
+		return err
+	}
+
+	if err := run(workDir, "git", "clone", "--quiet", "--depth", "1", "--branch", version, "https://github.com/"+StoryRepo, src); err != nil {
+		return fmt.Errorf("failed to clone story %s: %v", version, err)
+	}
+	if err := run(src, "go", "build", "-o", outPath, "./client"); err != nil {
+		return fmt.Errorf("failed to build story %s: %v", version, err)
+	}
+	return nil
+}
+
+// BinaryVersion returns the output of `<binary> version`.
+func BinaryVersion(binary string) (string, error) {
+	cmd := executor.Cmd(binary, "version")
+	// Keep both streams, the version may be printed on either
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if _, err := executor.Query(cmd); err != nil {
+		return "", fmt.Errorf("failed to run %s version: %v", binary, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// run runs a command in dir. Go's default install location is added to
+// PATH, as the setup installs Go there without touching the shell profile.
+func run(dir, name string, args ...string) error {
+	cmd := executor.Cmd(name, args...)
+	cmd.Dir = dir
+	cmd.Env = GoPathEnv()
+	return executor.Run(cmd)
+}
+
+// GoPathEnv returns the environment that adds Go's default install location
+// to PATH, or nothing if it is there already.
+func GoPathEnv() []string {
+	const goBin = "/usr/local/go/bin"
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == goBin {
+			return nil
+		}
+	}
+	return []string{"PATH=" + os.Getenv("PATH") + string(filepath.ListSeparator) + goBin}
+}
@@ -0,0 +1,97 @@
+// Package metrics writes metrics in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types
+const (
+	Gauge   = "gauge"
+	Counter = "counter"
+)
+
+// Writer writes samples, adding the HELP and TYPE lines before the first
+// sample of every metric. All samples of a metric must be written one
+// after another.
+type Writer struct {
+	w    *bufio.Writer
+	last string
+	err  error
+}
+
+// NewWriter returns a Writer writing to w. Call Flush when done.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Gauge writes a sample of the gauge name. labels are name and value pairs.
+func (w *Writer) Gauge(name, help string, value float64, labels ...string) {
+	w.sample(name, Gauge, help, value, labels)
+}
+
+// Counter writes a sample of the counter name, whose name should end in
+// _total. labels are name and value pairs.
+func (w *Writer) Counter(name, help string, value float64, labels ...string) {
+	w.sample(name, Counter, help, value, labels)
+}
+
+// Bool writes 1 for true and 0 for false as a sample of the gauge name.
+func (w *Writer) Bool(name, help string, value bool, labels ...string) {
+	v := 0.0
+	if value {
+		v = 1
+	}
+	w.Gauge(name, help, v, labels...)
+}
+
+// Flush writes the buffered samples and returns the first error that
+// occurred while writing.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) sample(name, kind, help string, value float64, labels []string) {
+	if w.err != nil {
+		return
+	}
+	if len(labels)%2 != 0 {
+		panic("metrics: odd number of label names and values for " + name)
+	}
+	if name != w.last {
+		fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind)
+		w.last = name
+	}
+	w.w.WriteString(name)
+	if len(labels) > 0 {
+		w.w.WriteByte('{')
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				w.w.WriteByte(',')
+			}
+			fmt.Fprintf(w.w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		w.w.WriteByte('}')
+	}
+	w.w.WriteByte(' ')
+	w.w.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	_, w.err = w.w.WriteString("\n")
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var out strings.Builder
+	w := NewWriter(&out)
+	w.Gauge("story_height", "Latest block height.", 1234, "node", "node-1")
+	w.Gauge("story_height", "Latest block height.", 1200, "node", `a "b" \c`+"\nd")
+	w.Bool("story_catching_up", "Whether the node is catching up.\nFrom /status.", true)
+	w.Counter("story_missed_blocks_total", `Missed blocks, see \docs.`, 3, "node", "node-1", "network", "aeneid")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP story_height Latest block height.
+# TYPE story_height gauge
+story_height{node="node-1"} 1234
+story_height{node="a \"b\" \\c\nd"} 1200
+# HELP story_catching_up Whether the node is catching up.\nFrom /status.
+# TYPE story_catching_up gauge
+story_catching_up 1
+# HELP story_missed_blocks_total Missed blocks, see \\docs.
+# TYPE story_missed_blocks_total counter
+story_missed_blocks_total{node="node-1",network="aeneid"} 3
+`
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriterOddLabels(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an odd number of labels")
+		}
+	}()
+	NewWriter(&strings.Builder{}).Gauge("story_height", "Latest block height.", 1, "node")
+}
@@ -0,0 +1,148 @@
+// Package monitor evaluates alert rules against a node and turns changes
+// in their outcome into notifications.
+package monitor
+
+import (
+	"os"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+	"github.com/sSelmann/storycli/utils/notify"
+)
+
+// Monitor checks a node and keeps the state the rules and the
+// deduplication need between checks. A Monitor is not safe for concurrent
+// use.
+type Monitor struct {
+	node      config.Profile
+	endpoints nodestatus.Endpoints
+	opts      nodestatus.Options
+	cfg       config.MonitorConfig
+	host      string
+
+	signing nodestatus.SigningTracker
+	// validator is set once the node was seen with voting power
+	validator bool
+	// heights is when the height of each client last changed
+	heights map[string]progress
+	// procs are the service processes of the last check
+	procs map[string]nodestatus.ProcessStats
+	// alerts are the alerts firing, by key
+	alerts map[string]*alert
+}
+
+type progress struct {
+	height int64
+	since  time.Time
+}
+
+type alert struct {
+	since    time.Time
+	notified time.Time
+}
+
+// result is the outcome of a rule for one subject, such as a service
+type result struct {
+	rule    string
+	subject string
+	firing  bool
+	// event results are notified once and never resolve
+	event   bool
+	summary string
+}
+
+func (r result) key() string {
+	if r.subject == "" {
+		return r.rule
+	}
+	return r.rule + ":" + r.subject
+}
+
+// observation is what a check saw of the node
+type observation struct {
+	time   time.Time
+	status nodestatus.Status
+	// disk is nil if the usage of the node home is unknown
+	disk *disk.UsageStat
+	// procs is nil if the service processes are unknown
+	procs []nodestatus.ProcessStats
+}
+
+// New returns a monitor of node with the rules of cfg.
+func New(node config.Profile, endpoints nodestatus.Endpoints, opts nodestatus.Options, cfg config.MonitorConfig) *Monitor {
+	host, _ := os.Hostname()
+	return &Monitor{
+		node:      node,
+		endpoints: endpoints,
+		opts:      opts,
+		cfg:       cfg,
+		host:      host,
+		signing:   nodestatus.SigningTracker{Endpoint: endpoints.CometRPC, Client: opts.Client},
+		heights:   map[string]progress{},
+		alerts:    map[string]*alert{},
+	}
+}
+
+// Check observes the node, evaluates the rules and returns the messages
+// to send: alerts that started firing, are due to be repeated or resolved,
+// and events.
+func (m *Monitor) Check() []notify.Message {
+	o := m.observe()
+	var results []result
+	for _, rule := range m.rules() {
+		results = append(results, rule(o)...)
+	}
+	return m.update(o.time, results)
+}
+
+func (m *Monitor) observe() observation {
+	o := observation{status: nodestatus.Collect(m.node, m.endpoints, m.opts)}
+	o.time = o.status.Time
+	if usage, err := disk.Usage(m.node.HomeDir); err == nil {
+		o.disk = usage
+	}
+	if procs, err := nodestatus.ServiceProcesses(m.node); err == nil {
+		o.procs = procs
+	}
+	return o
+}
+
+// update tracks the alerts of results and returns the messages about
+// their changes. Alerts without a result keep their state.
+func (m *Monitor) update(now time.Time, results []result) []notify.Message {
+	var msgs []notify.Message
+	for _, r := range results {
+		key := r.key()
+		a, active := m.alerts[key]
+		switch {
+		case r.event:
+			msgs = append(msgs, m.message(r, notify.Event, now, now))
+		case r.firing && !active:
+			m.alerts[key] = &alert{since: now, notified: now}
+			msgs = append(msgs, m.message(r, notify.Firing, now, now))
+		case r.firing && m.cfg.Repeat > 0 && now.Sub(a.notified) >= time.Duration(m.cfg.Repeat):
+			a.notified = now
+			msgs = append(msgs, m.message(r, notify.Firing, a.since, now))
+		case !r.firing && active:
+			delete(m.alerts, key)
+			msgs = append(msgs, m.message(r, notify.Resolved, a.since, now))
+		}
+	}
+	return msgs
+}
+
+func (m *Monitor) message(r result, state string, since, now time.Time) notify.Message {
+	return notify.Message{
+		Profile: m.node.Name,
+		Host:    m.host,
+		Alert:   r.key(),
+		Rule:    r.rule,
+		State:   state,
+		Summary: r.summary,
+		Since:   since,
+		Time:    now,
+	}
+}
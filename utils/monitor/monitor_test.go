@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/nodestatus"
+	"github.com/sSelmann/storycli/utils/notify"
+)
+
+var start = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testMonitor returns a monitor with cfg of a profile named p on host h
+// that isn't connected to a node
+func testMonitor(cfg config.MonitorConfig) *Monitor {
+	return &Monitor{
+		node:    config.Profile{Name: "p"},
+		host:    "h",
+		cfg:     cfg,
+		heights: map[string]progress{},
+		alerts:  map[string]*alert{},
+	}
+}
+
+// describe renders messages as "<state> <alert> since <minutes>"
+func describe(msgs []notify.Message) string {
+	var parts []string
+	for _, m := range msgs {
+		parts = append(parts, m.State+" "+m.Alert+" since "+m.Since.Sub(start).String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestUpdate(t *testing.T) {
+	down := result{rule: RuleServiceDown, subject: "story", firing: true, summary: "story is down"}
+	up := result{rule: RuleServiceDown, subject: "story", summary: "story is up"}
+	restart := result{rule: RuleServiceRestarted, subject: "story", firing: true, event: true}
+
+	steps := []struct {
+		after   time.Duration
+		results []result
+		want    string
+	}{
+		{0, []result{up}, ""},
+		{1 * time.Minute, []result{down}, "firing service_down:story since 1m0s"},
+		{2 * time.Minute, []result{down}, ""},
+		// Repeated once Repeat has passed since the last notification
+		{11 * time.Minute, []result{down}, "firing service_down:story since 1m0s"},
+		{15 * time.Minute, []result{down}, ""},
+		// No result, e.g. the state couldn't be judged: nothing changes
+		{25 * time.Minute, nil, ""},
+		{26 * time.Minute, []result{up, restart}, "resolved service_down:story since 1m0s, event service_restarted:story since 26m0s"},
+		{27 * time.Minute, []result{up, restart}, "event service_restarted:story since 27m0s"},
+		{28 * time.Minute, []result{down}, "firing service_down:story since 28m0s"},
+	}
+	m := testMonitor(config.MonitorConfig{Repeat: config.Duration(10 * time.Minute)})
+	for _, s := range steps {
+		msgs := m.update(start.Add(s.after), s.results)
+		if got := describe(msgs); got != s.want {
+			t.Errorf("after %s: got %q, want %q", s.after, got, s.want)
+		}
+		for _, msg := range msgs {
+			if msg.Profile != "p" || msg.Host != "h" || !msg.Time.Equal(start.Add(s.after)) {
+				t.Errorf("after %s: message %+v", s.after, msg)
+			}
+		}
+	}
+}
+
+func TestUpdateWithoutRepeat(t *testing.T) {
+	down := result{rule: RuleRPCDown, subject: "cometbft", firing: true}
+	m := testMonitor(config.MonitorConfig{})
+	if got := describe(m.update(start, []result{down})); got != "firing rpc_down:cometbft since 0s" {
+		t.Fatalf("got %q", got)
+	}
+	if got := describe(m.update(start.Add(24*time.Hour), []result{down})); got != "" {
+		t.Errorf("repeated without Repeat: %q", got)
+	}
+}
+
+func TestStalled(t *testing.T) {
+	m := testMonitor(config.MonitorConfig{StalledAfter: config.Duration(5 * time.Minute)})
+	observe := func(after time.Duration, up bool, height int64) observation {
+		var o observation
+		o.time = start.Add(after)
+		o.status.Consensus = nodestatus.Consensus{Up: up, LatestHeight: height}
+		return o
+	}
+
+	steps := []struct {
+		o    observation
+		want string
+	}{
+		{observe(0, true, 100), ""},
+		{observe(4*time.Minute, true, 100), ""},
+		{observe(5*time.Minute, true, 100), "firing stalled:consensus since 5m0s"},
+		// An RPC that is down says nothing about the height
+		{observe(6*time.Minute, false, 0), ""},
+		{observe(7*time.Minute, true, 101), "resolved stalled:consensus since 5m0s"},
+		{observe(11*time.Minute, true, 101), ""},
+		{observe(12*time.Minute, true, 101), "firing stalled:consensus since 12m0s"},
+	}
+	for i, s := range steps {
+		if got := describe(m.update(s.o.time, m.stalled(s.o))); got != s.want {
+			t.Errorf("step %d: got %q, want %q", i, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/nodestatus"
+)
+
+// Rules
+const (
+	RuleServiceDown      = "service_down"
+	RuleServiceRestarted = "service_restarted"
+	RuleRPCDown          = "rpc_down"
+	RuleStalled          = "stalled"
+	RuleBehind           = "behind"
+	RuleJailed           = "jailed"
+	RuleMissedBlocks     = "missed_blocks"
+	RuleLowPeers         = "low_peers"
+	RuleDiskFull         = "disk_full"
+)
+
+// rule evaluates one kind of alert. Subjects it can't judge from an
+// observation, e.g. the peers while the RPC is down, are left out, so that
+// their alerts neither fire nor resolve.
+type rule func(o observation) []result
+
+func (m *Monitor) rules() []rule {
+	return []rule{
+		m.serviceDown,
+		m.serviceRestarted,
+		m.rpcDown,
+		m.stalled,
+		m.behind,
+		m.jailed,
+		m.missedBlocks,
+		m.lowPeers,
+		m.diskFull,
+	}
+}
+
+func (m *Monitor) serviceDown(o observation) []result {
+	var results []result
+	for _, svc := range o.status.Services {
+		if svc.Error != "" {
+			continue
+		}
+		r := result{rule: RuleServiceDown, subject: svc.Name, firing: !svc.Active}
+		if r.firing {
+			r.summary = fmt.Sprintf("service %s is not running", svc.Name)
+		} else {
+			r.summary = fmt.Sprintf("service %s is running", svc.Name)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// serviceRestarted reports services whose main process, or all of whose
+// child processes such as story under Cosmovisor, were replaced since the
+// last check
+func (m *Monitor) serviceRestarted(o observation) []result {
+	if o.procs == nil {
+		return nil
+	}
+	var results []result
+	current := map[string]nodestatus.ProcessStats{}
+	for _, cur := range o.procs {
+		current[cur.Service] = cur
+		prev, ok := m.procs[cur.Service]
+		if !ok || !restarted(prev, cur) {
+			continue
+		}
+		results = append(results, result{
+			rule:    RuleServiceRestarted,
+			subject: cur.Service,
+			event:   true,
+			summary: fmt.Sprintf("service %s restarted", cur.Service),
+		})
+	}
+	m.procs = current
+	return results
+}
+
+func restarted(prev, cur nodestatus.ProcessStats) bool {
+	if prev.PID != cur.PID {
+		return true
+	}
+	if len(prev.PIDs) < 2 {
+		return false
+	}
+	if len(cur.PIDs) < 2 {
+		return true
+	}
+	for _, old := range prev.PIDs[1:] {
+		for _, pid := range cur.PIDs[1:] {
+			if old == pid {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (m *Monitor) rpcDown(o observation) []result {
+	c, e := o.status.Consensus, o.status.Execution
+	consensus := result{rule: RuleRPCDown, subject: "consensus", firing: !c.Up}
+	if c.Up {
+		consensus.summary = fmt.Sprintf("CometBFT RPC %s answers", c.Endpoint)
+	} else {
+		consensus.summary = fmt.Sprintf("CometBFT RPC %s is unreachable: %s", c.Endpoint, c.Error)
+	}
+	execution := result{rule: RuleRPCDown, subject: "execution", firing: !e.Up}
+	if e.Up {
+		execution.summary = fmt.Sprintf("geth RPC %s answers", e.Endpoint)
+	} else {
+		execution.summary = fmt.Sprintf("geth RPC %s is unreachable: %s", e.Endpoint, e.Error)
+	}
+	return []result{consensus, execution}
+}
+
+// stalled reports clients whose height stayed the same for StalledAfter
+func (m *Monitor) stalled(o observation) []result {
+	after := time.Duration(m.cfg.StalledAfter)
+	if after <= 0 {
+		return nil
+	}
+	var results []result
+	check := func(client string, up bool, height int64) {
+		if !up {
+			return
+		}
+		p, ok := m.heights[client]
+		if !ok || p.height != height {
+			p = progress{height: height, since: o.time}
+			m.heights[client] = p
+		}
+		stuck := o.time.Sub(p.since)
+		r := result{rule: RuleStalled, subject: client, firing: stuck >= after}
+		if r.firing {
+			r.summary = fmt.Sprintf("%s height stuck at %d for %s", client, height, stuck.Round(time.Second))
+		} else {
+			r.summary = fmt.Sprintf("%s height moves again, now %d", client, height)
+		}
+		results = append(results, r)
+	}
+	check("consensus", o.status.Consensus.Up, o.status.Consensus.LatestHeight)
+	check("execution", o.status.Execution.Up, int64(o.status.Execution.BlockNumber))
+	return results
+}
+
+func (m *Monitor) behind(o observation) []result {
+	s := o.status
+	if m.cfg.MaxBlockLag <= 0 || s.BlockLag == nil {
+		return nil
+	}
+	r := result{rule: RuleBehind, firing: *s.BlockLag > m.cfg.MaxBlockLag}
+	if r.firing {
+		r.summary = fmt.Sprintf("%d blocks behind %s, more than %d", *s.BlockLag, s.Reference.Endpoint, m.cfg.MaxBlockLag)
+	} else {
+		r.summary = fmt.Sprintf("%d blocks behind %s, caught up", *s.BlockLag, s.Reference.Endpoint)
+	}
+	return []result{r}
+}
+
+// jailed reports a validator without voting power. The node counts as a
+// validator once it was seen with voting power, or with ExpectValidator.
+func (m *Monitor) jailed(o observation) []result {
+	c := o.status.Consensus
+	if !c.Up {
+		return nil
+	}
+	if c.VotingPower > 0 {
+		m.validator = true
+	}
+	if !m.validator && !m.cfg.ExpectValidator {
+		return nil
+	}
+	r := result{rule: RuleJailed, firing: c.VotingPower == 0}
+	if r.firing {
+		r.summary = fmt.Sprintf("validator %s has no voting power: jailed or out of the active set", c.ValidatorAddress)
+	} else {
+		r.summary = fmt.Sprintf("validator %s is in the active set with voting power %d", c.ValidatorAddress, c.VotingPower)
+	}
+	return []result{r}
+}
+
+// missedBlocks checks the blocks committed since the last check while the
+// node is an active validator that is in sync
+func (m *Monitor) missedBlocks(o observation) []result {
+	c := o.status.Consensus
+	if m.cfg.MaxMissedBlocks <= 0 || !c.Up || c.CatchingUp || c.VotingPower == 0 {
+		return nil
+	}
+	if err := m.signing.Update(c.ValidatorAddress, c.LatestHeight); err != nil {
+		pterm.Warning.Printf("Failed to check signed blocks: %v\n", err)
+		return nil
+	}
+	blocks, missed := m.signing.Window()
+	r := result{rule: RuleMissedBlocks, firing: missed >= m.cfg.MaxMissedBlocks}
+	if r.firing {
+		r.summary = fmt.Sprintf("validator missed %d of the last %d blocks", missed, blocks)
+	} else {
+		r.summary = fmt.Sprintf("validator missed %d of the last %d blocks, signing again", missed, blocks)
+	}
+	return []result{r}
+}
+
+func (m *Monitor) lowPeers(o observation) []result {
+	var results []result
+	check := func(client string, up bool, peers, least int) {
+		if least <= 0 || !up {
+			return
+		}
+		r := result{rule: RuleLowPeers, subject: client, firing: peers < least}
+		if r.firing {
+			r.summary = fmt.Sprintf("%d %s peers, fewer than %d", peers, client, least)
+		} else {
+			r.summary = fmt.Sprintf("%d %s peers", peers, client)
+		}
+		results = append(results, r)
+	}
+	c, e := o.status.Consensus, o.status.Execution
+	check("consensus", c.Up, c.Peers, m.cfg.MinPeers)
+	check("execution", e.Up, int(e.Peers), m.cfg.MinGethPeers)
+	return results
+}
+
+func (m *Monitor) diskFull(o observation) []result {
+	if m.cfg.MaxDiskUsedPercent <= 0 || o.disk == nil {
+		return nil
+	}
+	r := result{rule: RuleDiskFull, firing: o.disk.UsedPercent > m.cfg.MaxDiskUsedPercent}
+	r.summary = fmt.Sprintf("disk of %s is %.1f%% full", m.node.HomeDir, o.disk.UsedPercent)
+	if r.firing {
+		r.summary += fmt.Sprintf(", over %.0f%%", m.cfg.MaxDiskUsedPercent)
+	}
+	return []result{r}
+}
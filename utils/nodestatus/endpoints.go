@@ -0,0 +1,116 @@
+// Package nodestatus reads the state of a node from its CometBFT RPC and
+// geth JSON-RPC.
+package nodestatus
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/service"
+	"github.com/sSelmann/storycli/utils/tomledit"
+)
+
+// Endpoints are the RPC URLs of a node and where they were found.
+type Endpoints struct {
+	// CometRPC is the CometBFT RPC, e.g. http://127.0.0.1:26657.
+	CometRPC    string `json:"consensus_rpc"`
+	CometSource string `json:"consensus_rpc_source"`
+	// GethRPC is the geth HTTP JSON-RPC, e.g. http://127.0.0.1:8545.
+	GethRPC    string `json:"execution_rpc"`
+	GethSource string `json:"execution_rpc_source"`
+}
+
+// Discover finds the RPC endpoints of node: the CometBFT RPC from rpc.laddr
+// in config.toml and the geth RPC from the --http.addr and --http.port flags
+// of the geth service. The ports of the profile are used when neither is
+// available, e.g. before the node is set up.
+func Discover(node config.Profile) Endpoints {
+	e := Endpoints{
+		CometRPC:    localURL("", node.RPCPort),
+		CometSource: "profile rpc_port",
+		GethRPC:     localURL("", node.GethRPCPort),
+		GethSource:  "profile geth_rpc_port",
+	}
+
+	configToml := filepath.Join(node.ConfigDir(), config.CometToml.FileName())
+	if doc, err := tomledit.Load(configToml); err == nil {
+		if raw, ok := doc.Get("rpc.laddr"); ok {
+			if u, err := laddrURL(strings.Trim(raw, `"'`)); err == nil {
+				e.CometRPC = u
+				e.CometSource = config.CometToml.FileName() + " rpc.laddr"
+			}
+		}
+	}
+
+	if manager, err := service.ForProfile(node); err == nil {
+		if command, err := manager.Command(node.GethService); err == nil {
+			if u, ok := gethHTTPURL(command); ok {
+				e.GethRPC = u
+				e.GethSource = node.GethService + " service flags"
+			}
+		}
+	}
+	return e
+}
+
+// laddrURL turns a CometBFT listen address such as tcp://0.0.0.0:26657
+// into a URL reaching it from this machine
+func laddrURL(laddr string) (string, error) {
+	u, err := url.Parse(laddr)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "tcp" && u.Scheme != "http" {
+		return "", fmt.Errorf("unsupported listen address %s", laddr)
+	}
+	host, portText, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "", err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return "", err
+	}
+	return localURL(host, port), nil
+}
+
+// gethHTTPURL returns the HTTP RPC URL of a geth command line, or false if
+// the command doesn't enable it. Flags may be given as --flag value or
+// --flag=value.
+func gethHTTPURL(command []string) (string, bool) {
+	flags := map[string]string{}
+	for i := 0; i < len(command); i++ {
+		arg := command[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue && i+1 < len(command) && !strings.HasPrefix(command[i+1], "-") {
+			value = command[i+1]
+		}
+		flags[name] = value
+	}
+	if _, ok := flags["http"]; !ok {
+		return "", false
+	}
+	port := 8545
+	if p, err := strconv.Atoi(flags["http.port"]); err == nil {
+		port = p
+	}
+	return localURL(flags["http.addr"], port), true
+}
+
+// localURL returns the URL of a server listening on host:port, using the
+// loopback address for servers listening on all interfaces
+func localURL(host string, port int) string {
+	switch host {
+	case "", "0.0.0.0", "::", "[::]":
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -0,0 +1,95 @@
+package nodestatus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLaddrURL(t *testing.T) {
+	tests := []struct {
+		laddr   string
+		want    string
+		wantErr bool
+	}{
+		{laddr: "tcp://127.0.0.1:26657", want: "http://127.0.0.1:26657"},
+		{laddr: "tcp://0.0.0.0:36657", want: "http://127.0.0.1:36657"},
+		{laddr: "tcp://10.0.0.5:26657", want: "http://10.0.0.5:26657"},
+		{laddr: "http://localhost:26657", want: "http://localhost:26657"},
+		{laddr: "tcp://[::]:26657", want: "http://127.0.0.1:26657"},
+		{laddr: "tcp://[::1]:26657", want: "http://[::1]:26657"},
+		{laddr: "unix:///var/run/cometbft.sock", wantErr: true},
+		{laddr: "tcp://127.0.0.1", wantErr: true},
+		{laddr: "tcp://127.0.0.1:rpc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := laddrURL(tt.laddr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("laddrURL(%q) = %q, want an error", tt.laddr, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("laddrURL(%q) = %q, %v, want %q", tt.laddr, got, err, tt.want)
+		}
+	}
+}
+
+func TestGethHTTPURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+		// wantOK is false if the command doesn't serve HTTP
+		wantOK bool
+	}{
+		{
+			name:    "default port",
+			command: "geth --odyssey --syncmode full --http",
+			want:    "http://127.0.0.1:8545",
+			wantOK:  true,
+		},
+		{
+			name:    "port with =",
+			command: "geth --odyssey --http --http.port=18545 --ws",
+			want:    "http://127.0.0.1:18545",
+			wantOK:  true,
+		},
+		{
+			name:    "port as the next argument",
+			command: "geth --http --http.port 18545 --ws.port 18546",
+			want:    "http://127.0.0.1:18545",
+			wantOK:  true,
+		},
+		{
+			name:    "all interfaces",
+			command: "geth --http --http.addr 0.0.0.0 --http.port 8547",
+			want:    "http://127.0.0.1:8547",
+			wantOK:  true,
+		},
+		{
+			name:    "all IPv6 interfaces",
+			command: "geth --http --http.addr=:: --http.port=8547",
+			want:    "http://127.0.0.1:8547",
+			wantOK:  true,
+		},
+		{
+			name:    "specific address",
+			command: "geth -http -http.addr 10.0.0.5",
+			want:    "http://10.0.0.5:8545",
+			wantOK:  true,
+		},
+		{
+			name:    "http disabled",
+			command: "geth --odyssey --http.port 18545 --ws --ws.port 8546",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := gethHTTPURL(strings.Fields(tt.command))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("got %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package nodestatus
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/service"
+)
+
+// ProcessStats is the resource use of a service: its main process and all
+// of its descendants, such as story started by cosmovisor.
+type ProcessStats struct {
+	Service string `json:"service"`
+	PID     int    `json:"pid"`
+	// Processes is the number of processes in the tree.
+	Processes int `json:"processes"`
+	// PIDs are the processes in the tree, the main process first.
+	PIDs []int `json:"-"`
+	// CPUSeconds is the user and system CPU time used so far.
+	CPUSeconds float64 `json:"cpu_seconds"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+}
+
+// ServiceProcesses returns the resource use of the story and geth services
+// of node. Services that don't run are left out.
+func ServiceProcesses(node config.Profile) ([]ProcessStats, error) {
+	manager, err := service.ForProfile(node)
+	if err != nil {
+		return nil, err
+	}
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	children := map[int32][]*process.Process{}
+	byPID := map[int32]*process.Process{}
+	for _, p := range procs {
+		byPID[p.Pid] = p
+		if ppid, err := p.Ppid(); err == nil {
+			children[ppid] = append(children[ppid], p)
+		}
+	}
+
+	var stats []ProcessStats
+	for _, name := range node.Services() {
+		pid, err := manager.MainPID(name)
+		if err != nil || pid <= 0 {
+			continue
+		}
+		root, ok := byPID[int32(pid)]
+		if !ok {
+			continue
+		}
+		st := ProcessStats{Service: name, PID: pid}
+		queue := []*process.Process{root}
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = append(queue[1:], children[p.Pid]...)
+			st.Processes++
+			st.PIDs = append(st.PIDs, int(p.Pid))
+			if times, err := p.Times(); err == nil {
+				st.CPUSeconds += times.User + times.System
+			}
+			if mem, err := p.MemoryInfo(); err == nil {
+				st.RSSBytes += mem.RSS
+			}
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// DirSize returns the total size of the files below dir. Files that vanish
+// while walking, as geth and CometBFT compact their databases, are skipped.
+func DirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += uint64(info.Size())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s: %v", dir, err)
+	}
+	return size, nil
+}
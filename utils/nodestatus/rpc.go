@@ -0,0 +1,86 @@
+package nodestatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultClient is used for the RPC calls unless Options sets another one.
+var DefaultClient = &http.Client{Timeout: 5 * time.Second}
+
+// rpcResponse is the JSON-RPC envelope used by both CometBFT and geth
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+func (r rpcResponse) decode(result interface{}) error {
+	if r.Error != nil {
+		msg := r.Error.Message
+		if r.Error.Data != "" {
+			msg += ": " + r.Error.Data
+		}
+		return fmt.Errorf("rpc error %d: %s", r.Error.Code, msg)
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// cometGet calls a CometBFT RPC route such as /status
+func cometGet(client *http.Client, endpoint, route string, result interface{}) error {
+	resp, err := client.Get(strings.TrimRight(endpoint, "/") + route)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got non-OK status code %d from %s%s", resp.StatusCode, endpoint, route)
+	}
+
+	var r rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", route, err)
+	}
+	return r.decode(result)
+}
+
+// ethCall calls a geth JSON-RPC method
+func ethCall(client *http.Client, endpoint, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got non-OK status code %d from %s", resp.StatusCode, method)
+	}
+
+	var r rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", method, err)
+	}
+	if err := r.decode(result); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
+
+// hexUint parses a 0x-prefixed quantity as returned by geth
+func hexUint(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+}
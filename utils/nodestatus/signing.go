@@ -0,0 +1,113 @@
+package nodestatus
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SigningWindow is how many of the most recent blocks a SigningTracker
+// keeps to count misses in.
+const SigningWindow = 100
+
+// blockIDFlagAbsent marks a validator that sent no vote for a block. Nil
+// votes count as signed, as they do for slashing.
+const blockIDFlagAbsent = 1
+
+// SigningTracker follows whether a validator signs the blocks of a CometBFT
+// RPC. Each call to Update checks the blocks committed since the last call.
+// A SigningTracker is not safe for concurrent use.
+type SigningTracker struct {
+	Endpoint string
+	// Client makes the RPC calls; DefaultClient if nil.
+	Client *http.Client
+
+	// Signed and Missed count the checked blocks since the tracker was
+	// created.
+	Signed uint64
+	Missed uint64
+
+	last   int64
+	recent []bool // missed flags of the last SigningWindow blocks, oldest first
+}
+
+// Update checks the blocks from the last checked one up to latest for a
+// signature of the validator with the hex address. Only the last
+// SigningWindow blocks are checked after a gap. The commit of the latest
+// block isn't final yet, so it is left for the next call.
+func (t *SigningTracker) Update(address string, latest int64) error {
+	client := t.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	from := t.last + 1
+	if from < latest-SigningWindow {
+		from = latest - SigningWindow
+	}
+	for height := from; height < latest; height++ {
+		missed, err := missedBlock(client, t.Endpoint, address, height)
+		if err != nil {
+			return err
+		}
+		t.last = height
+		if missed {
+			t.Missed++
+		} else {
+			t.Signed++
+		}
+		t.recent = append(t.recent, missed)
+		if len(t.recent) > SigningWindow {
+			t.recent = t.recent[1:]
+		}
+	}
+	return nil
+}
+
+// Window returns how many blocks the tracker has checked, up to
+// SigningWindow, and how many of those the validator missed.
+func (t *SigningTracker) Window() (blocks, missed int) {
+	for _, m := range t.recent {
+		if m {
+			missed++
+		}
+	}
+	return len(t.recent), missed
+}
+
+// ConsecutiveMissed returns how many of the latest checked blocks in a row
+// the validator missed.
+func (t *SigningTracker) ConsecutiveMissed() int {
+	n := 0
+	for i := len(t.recent) - 1; i >= 0 && t.recent[i]; i-- {
+		n++
+	}
+	return n
+}
+
+// LastHeight returns the last checked height, 0 before the first check.
+func (t *SigningTracker) LastHeight() int64 {
+	return t.last
+}
+
+// missedBlock tells whether the commit of height lacks a vote of address
+func missedBlock(client *http.Client, endpoint, address string, height int64) (bool, error) {
+	var commit struct {
+		SignedHeader struct {
+			Commit struct {
+				Signatures []struct {
+					BlockIDFlag      int    `json:"block_id_flag"`
+					ValidatorAddress string `json:"validator_address"`
+				} `json:"signatures"`
+			} `json:"commit"`
+		} `json:"signed_header"`
+	}
+	if err := cometGet(client, endpoint, fmt.Sprintf("/commit?height=%d", height), &commit); err != nil {
+		return false, err
+	}
+	for _, sig := range commit.SignedHeader.Commit.Signatures {
+		if strings.EqualFold(sig.ValidatorAddress, address) {
+			return sig.BlockIDFlag == blockIDFlagAbsent, nil
+		}
+	}
+	return true, nil
+}
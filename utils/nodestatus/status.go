@@ -0,0 +1,319 @@
+package nodestatus
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/service"
+)
+
+// HeadDriftTolerance is how far apart in time the latest consensus block
+// and the latest execution block may be for the heads to agree. Every
+// consensus block carries an execution payload built at the same time, so
+// the heads drift apart when geth falls behind or follows another chain.
+const HeadDriftTolerance = 30 * time.Second
+
+// Status is the state of a node at one point in time. Sections whose RPC
+// could not be reached have Up unset and Error set.
+type Status struct {
+	Profile   string        `json:"profile"`
+	Network   string        `json:"network"`
+	Time      time.Time     `json:"time"`
+	Services  []ServiceInfo `json:"services"`
+	Consensus Consensus     `json:"consensus"`
+	Execution Execution     `json:"execution"`
+	Reference *Reference    `json:"reference,omitempty"`
+
+	// BlockLag is how many blocks the node is behind the reference RPC.
+	BlockLag *int64 `json:"block_lag,omitempty"`
+	// HeadDrift is the latest consensus block time minus the latest
+	// execution block time, in seconds.
+	HeadDrift  *float64 `json:"head_drift_seconds,omitempty"`
+	HeadsAgree *bool    `json:"heads_agree,omitempty"`
+}
+
+// ServiceInfo tells whether a service of the node runs.
+type ServiceInfo struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Consensus is the state reported by the CometBFT RPC.
+type Consensus struct {
+	Endpoint string `json:"endpoint"`
+	Source   string `json:"endpoint_source"`
+	Up       bool   `json:"up"`
+	Error    string `json:"error,omitempty"`
+
+	Moniker string `json:"moniker,omitempty"`
+	NodeID  string `json:"node_id,omitempty"`
+	ChainID string `json:"chain_id,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	LatestHeight    int64     `json:"latest_height"`
+	LatestBlockTime time.Time `json:"latest_block_time"`
+	CatchingUp      bool      `json:"catching_up"`
+
+	Peers         int `json:"peers"`
+	InboundPeers  int `json:"inbound_peers"`
+	OutboundPeers int `json:"outbound_peers"`
+	// PeerIDs are the node ids of the peers, to follow peer churn.
+	PeerIDs []string `json:"-"`
+
+	ValidatorAddress string `json:"validator_address,omitempty"`
+	VotingPower      int64  `json:"voting_power"`
+}
+
+// Execution is the state reported by the geth JSON-RPC.
+type Execution struct {
+	Endpoint string `json:"endpoint"`
+	Source   string `json:"endpoint_source"`
+	Up       bool   `json:"up"`
+	Error    string `json:"error,omitempty"`
+
+	ChainID     uint64    `json:"chain_id,omitempty"`
+	BlockNumber uint64    `json:"block_number"`
+	BlockTime   time.Time `json:"block_time"`
+	Syncing     bool      `json:"syncing"`
+	// CurrentBlock and HighestBlock are set while syncing.
+	CurrentBlock uint64 `json:"current_block,omitempty"`
+	HighestBlock uint64 `json:"highest_block,omitempty"`
+	Peers        uint64 `json:"peers"`
+}
+
+// Reference is the latest height of a CometBFT RPC the node is compared
+// with.
+type Reference struct {
+	Endpoint     string `json:"endpoint"`
+	Up           bool   `json:"up"`
+	Error        string `json:"error,omitempty"`
+	LatestHeight int64  `json:"latest_height"`
+}
+
+// Options configure Collect.
+type Options struct {
+	// ReferenceRPC is a CometBFT RPC to compute the block lag against.
+	// Empty skips the comparison.
+	ReferenceRPC string
+	// Client makes the RPC calls; DefaultClient if nil.
+	Client *http.Client
+}
+
+// Collect queries the services, the consensus and execution RPCs and the
+// reference RPC of node concurrently. Failures are recorded in the status
+// rather than returned, so that a node that is down still has a status.
+func Collect(node config.Profile, endpoints Endpoints, opts Options) Status {
+	client := opts.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	s := Status{
+		Profile:   node.Name,
+		Network:   node.Network,
+		Time:      time.Now().UTC(),
+		Consensus: Consensus{Endpoint: endpoints.CometRPC, Source: endpoints.CometSource},
+		Execution: Execution{Endpoint: endpoints.GethRPC, Source: endpoints.GethSource},
+	}
+	if opts.ReferenceRPC != "" {
+		s.Reference = &Reference{Endpoint: opts.ReferenceRPC}
+	}
+
+	var wg sync.WaitGroup
+	run := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	run(func() { s.Services = services(node) })
+	run(func() { s.Consensus.collect(client) })
+	run(func() { s.Execution.collect(client) })
+	if s.Reference != nil {
+		run(func() { s.Reference.collect(client) })
+	}
+	wg.Wait()
+
+	if s.Reference != nil && s.Reference.Up && s.Consensus.Up {
+		lag := s.Reference.LatestHeight - s.Consensus.LatestHeight
+		s.BlockLag = &lag
+	}
+	if s.Consensus.Up && s.Execution.Up && !s.Consensus.LatestBlockTime.IsZero() && !s.Execution.BlockTime.IsZero() {
+		drift := s.Consensus.LatestBlockTime.Sub(s.Execution.BlockTime)
+		seconds := drift.Seconds()
+		agree := drift.Abs() <= HeadDriftTolerance
+		s.HeadDrift = &seconds
+		s.HeadsAgree = &agree
+	}
+	return s
+}
+
+// CollectConsensus queries only the CometBFT RPC of a node.
+func CollectConsensus(endpoints Endpoints, opts Options) Consensus {
+	client := opts.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	c := Consensus{Endpoint: endpoints.CometRPC, Source: endpoints.CometSource}
+	c.collect(client)
+	return c
+}
+
+func services(node config.Profile) []ServiceInfo {
+	infos := make([]ServiceInfo, 0, 2)
+	manager, err := service.ForProfile(node)
+	for _, name := range node.Services() {
+		info := ServiceInfo{Name: name}
+		if err != nil {
+			info.Error = err.Error()
+		} else if active, err := manager.Active(name); err != nil {
+			info.Error = err.Error()
+		} else {
+			info.Active = active
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// cometStatus is the result of /status
+type cometStatus struct {
+	NodeInfo struct {
+		ID      string `json:"id"`
+		Network string `json:"network"`
+		Version string `json:"version"`
+		Moniker string `json:"moniker"`
+	} `json:"node_info"`
+	SyncInfo struct {
+		LatestBlockHeight string    `json:"latest_block_height"`
+		LatestBlockTime   time.Time `json:"latest_block_time"`
+		CatchingUp        bool      `json:"catching_up"`
+	} `json:"sync_info"`
+	ValidatorInfo struct {
+		Address     string `json:"address"`
+		VotingPower string `json:"voting_power"`
+	} `json:"validator_info"`
+}
+
+// LatestHeight returns the latest block height of a CometBFT RPC.
+func LatestHeight(endpoint string) (int64, error) {
+	_, height, err := latestHeight(DefaultClient, endpoint)
+	return height, err
+}
+
+// latestHeight calls /status
+func latestHeight(client *http.Client, endpoint string) (cometStatus, int64, error) {
+	var st cometStatus
+	if err := cometGet(client, endpoint, "/status", &st); err != nil {
+		return st, 0, err
+	}
+	height, err := strconv.ParseInt(st.SyncInfo.LatestBlockHeight, 10, 64)
+	return st, height, err
+}
+
+func (c *Consensus) collect(client *http.Client) {
+	st, height, err := latestHeight(client, c.Endpoint)
+	if err != nil {
+		c.Error = err.Error()
+		return
+	}
+	c.Up = true
+	c.Moniker = st.NodeInfo.Moniker
+	c.NodeID = st.NodeInfo.ID
+	c.ChainID = st.NodeInfo.Network
+	c.Version = st.NodeInfo.Version
+	c.LatestHeight = height
+	c.LatestBlockTime = st.SyncInfo.LatestBlockTime
+	c.CatchingUp = st.SyncInfo.CatchingUp
+	c.ValidatorAddress = st.ValidatorInfo.Address
+	c.VotingPower, _ = strconv.ParseInt(st.ValidatorInfo.VotingPower, 10, 64)
+
+	var netInfo struct {
+		NPeers string `json:"n_peers"`
+		Peers  []struct {
+			IsOutbound bool `json:"is_outbound"`
+			NodeInfo   struct {
+				ID string `json:"id"`
+			} `json:"node_info"`
+		} `json:"peers"`
+	}
+	if err := cometGet(client, c.Endpoint, "/net_info", &netInfo); err != nil {
+		c.Error = err.Error()
+		return
+	}
+	c.Peers, _ = strconv.Atoi(netInfo.NPeers)
+	for _, p := range netInfo.Peers {
+		c.PeerIDs = append(c.PeerIDs, p.NodeInfo.ID)
+		if p.IsOutbound {
+			c.OutboundPeers++
+		} else {
+			c.InboundPeers++
+		}
+	}
+}
+
+func (e *Execution) collect(client *http.Client) {
+	var number string
+	if err := ethCall(client, e.Endpoint, "eth_blockNumber", &number); err != nil {
+		e.Error = err.Error()
+		return
+	}
+	e.Up = true
+	var err error
+	if e.BlockNumber, err = hexUint(number); err != nil {
+		e.Error = "eth_blockNumber: " + err.Error()
+		return
+	}
+
+	// eth_syncing returns false, or the sync progress while syncing
+	var syncing json.RawMessage
+	if err := ethCall(client, e.Endpoint, "eth_syncing", &syncing); err != nil {
+		e.Error = err.Error()
+		return
+	}
+	var progress struct {
+		CurrentBlock string `json:"currentBlock"`
+		HighestBlock string `json:"highestBlock"`
+	}
+	if json.Unmarshal(syncing, &progress) == nil {
+		e.Syncing = true
+		e.CurrentBlock, _ = hexUint(progress.CurrentBlock)
+		e.HighestBlock, _ = hexUint(progress.HighestBlock)
+	}
+
+	var peers, chainID string
+	if err := ethCall(client, e.Endpoint, "net_peerCount", &peers); err != nil {
+		e.Error = err.Error()
+		return
+	}
+	e.Peers, _ = hexUint(peers)
+	if err := ethCall(client, e.Endpoint, "eth_chainId", &chainID); err == nil {
+		e.ChainID, _ = hexUint(chainID)
+	}
+
+	var head struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := ethCall(client, e.Endpoint, "eth_getBlockByNumber", &head, "latest", false); err != nil {
+		e.Error = err.Error()
+		return
+	}
+	if ts, err := hexUint(head.Timestamp); err == nil {
+		e.BlockTime = time.Unix(int64(ts), 0).UTC()
+	}
+}
+
+func (r *Reference) collect(client *http.Client) {
+	_, height, err := latestHeight(client, r.Endpoint)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	r.Up = true
+	r.LatestHeight = height
+}
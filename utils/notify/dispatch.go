@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// notifyTimeout bounds one delivery
+const notifyTimeout = 30 * time.Second
+
+// maxPending is how many undelivered messages are kept per notifier; the
+// oldest are dropped beyond that
+const maxPending = 100
+
+// maxPerSend is how many messages one Send delivers per notifier, so a
+// long queue to a slow notifier doesn't stall the caller; the rest wait
+// for the next Send
+const maxPerSend = 10
+
+// Dispatcher sends messages to every notifier. Messages a notifier fails to
+// deliver are retried, in order, with the next Send.
+type Dispatcher struct {
+	Notifiers []Notifier
+	pending   map[Notifier][]Message
+}
+
+// Send delivers msgs and the messages still pending from earlier sends, at
+// most maxPerSend per notifier. Failures are logged, not returned.
+func (d *Dispatcher) Send(ctx context.Context, msgs []Message) {
+	if d.pending == nil {
+		d.pending = map[Notifier][]Message{}
+	}
+	for _, n := range d.Notifiers {
+		queue := append(d.pending[n], msgs...)
+		if dropped := len(queue) - maxPending; dropped > 0 {
+			pterm.Warning.Printf("Dropping %d undelivered messages for %s\n", dropped, n.Name())
+			queue = queue[dropped:]
+		}
+		for sent := 0; len(queue) > 0 && sent < maxPerSend; sent++ {
+			m := queue[0]
+			err := executor.Perform(fmt.Sprintf("send %q via %s", m.Title(), n.Name()), func() error {
+				ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+				defer cancel()
+				return n.Notify(ctx, m)
+			})
+			if err != nil {
+				pterm.Warning.Printf("Failed to notify %s, retrying with the next check: %v\n", n.Name(), err)
+				break
+			}
+			queue = queue[1:]
+		}
+		d.pending[n] = queue
+	}
+}
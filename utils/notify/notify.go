@@ -0,0 +1,130 @@
+// Package notify sends alerts of `scli monitor` through webhooks, Telegram
+// and email.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sSelmann/storycli/utils/config"
+)
+
+// Notifier types
+const (
+	Webhook  = "webhook"
+	Telegram = "telegram"
+	SMTP     = "smtp"
+)
+
+// Types lists the notifier types.
+var Types = []string{Webhook, Telegram, SMTP}
+
+// Alert states
+const (
+	// Firing is sent when a rule starts to fail, and again as a reminder
+	// while it keeps failing.
+	Firing = "firing"
+	// Resolved is sent when a firing rule passes again.
+	Resolved = "resolved"
+	// Event is sent once for things that happen rather than last, such as
+	// a service restart.
+	Event = "event"
+)
+
+// HTTPClient is used by the webhook and Telegram notifiers.
+var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Message is one notification about an alert.
+type Message struct {
+	Profile string `json:"profile"`
+	Host    string `json:"host"`
+	// Alert identifies the alert, the rule and what it applies to, e.g.
+	// "service_down:story".
+	Alert   string `json:"alert"`
+	Rule    string `json:"rule"`
+	State   string `json:"state"`
+	Summary string `json:"summary"`
+	// Since is when the alert started firing.
+	Since time.Time `json:"since"`
+	Time  time.Time `json:"time"`
+}
+
+// Title is a one line description of m, e.g. for an email subject.
+func (m Message) Title() string {
+	return fmt.Sprintf("[%s] %s on %s: %s", strings.ToUpper(m.State), m.Profile, m.Host, m.Summary)
+}
+
+// Text is the full description of m.
+func (m Message) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.Title())
+	fmt.Fprintf(&b, "Profile: %s\nHost: %s\nAlert: %s\n", m.Profile, m.Host, m.Alert)
+	switch m.State {
+	case Firing:
+		fmt.Fprintf(&b, "Firing since: %s\n", m.Since.Format(time.RFC3339))
+	case Resolved:
+		fmt.Fprintf(&b, "Fired for: %s\n", m.Time.Sub(m.Since).Round(time.Second))
+	}
+	fmt.Fprintf(&b, "Time: %s\n", m.Time.Format(time.RFC3339))
+	return b.String()
+}
+
+// Notifier delivers messages to one destination.
+type Notifier interface {
+	// Name describes the notifier, e.g. "telegram" or the configured name.
+	Name() string
+	Notify(ctx context.Context, m Message) error
+}
+
+// New builds the notifier described by cfg.
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Type
+	}
+	switch cfg.Type {
+	case Webhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook notifier %s: url is not set", name)
+		}
+		return &WebhookNotifier{name: name, URL: cfg.URL, Headers: cfg.Headers}, nil
+	case Telegram:
+		if cfg.BotToken == "" || cfg.ChatID == "" {
+			return nil, fmt.Errorf("telegram notifier %s: bot_token and chat_id must be set", name)
+		}
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = DefaultTelegramAPI
+		}
+		return &TelegramNotifier{name: name, APIURL: apiURL, BotToken: cfg.BotToken, ChatID: cfg.ChatID}, nil
+	case SMTP:
+		if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("smtp notifier %s: host, from and to must be set", name)
+		}
+		port := cfg.Port
+		if port == 0 {
+			port = 587
+		}
+		return &SMTPNotifier{name: name, Host: cfg.Host, Port: port, Username: cfg.Username, Password: cfg.Password, From: cfg.From, To: cfg.To}, nil
+	case "":
+		return nil, fmt.Errorf("notifier %s: type is not set (use %s)", name, strings.Join(Types, ", "))
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q (use %s)", cfg.Type, strings.Join(Types, ", "))
+	}
+}
+
+// FromConfig builds the notifiers of the [monitor] section.
+func FromConfig(cfg config.MonitorConfig) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(cfg.Notifiers))
+	for _, c := range cfg.Notifiers {
+		n, err := New(c)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
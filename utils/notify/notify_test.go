@@ -0,0 +1,427 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+var testMessage = Message{
+	Profile: "mainnet",
+	Host:    "node-1",
+	Alert:   "service_down:story",
+	Rule:    "service_down",
+	State:   Firing,
+	Summary: "story is not active",
+	Since:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	Time:    time.Date(2026, 1, 2, 3, 9, 5, 0, time.UTC),
+}
+
+// request is what a test server received
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newHTTPServer records every request and answers with status and reply
+func newHTTPServer(t *testing.T, status int, reply string) (*httptest.Server, *[]request) {
+	var received []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, request{r.Method, r.URL.Path, r.Header.Clone(), body})
+		w.WriteHeader(status)
+		fmt.Fprint(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	srv, received := newHTTPServer(t, http.StatusNoContent, "")
+	n := &WebhookNotifier{name: "hook", URL: srv.URL + "/alerts", Headers: map[string]string{"Authorization": "Bearer secret"}}
+	if err := n.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*received) != 1 {
+		t.Fatalf("%d requests, want 1", len(*received))
+	}
+	r := (*received)[0]
+	if r.method != http.MethodPost || r.path != "/alerts" {
+		t.Errorf("got %s %s, want POST /alerts", r.method, r.path)
+	}
+	if got := r.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type %q", got)
+	}
+	if got := r.header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization %q", got)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		t.Fatalf("body %s: %v", r.body, err)
+	}
+	want := map[string]string{
+		"alert": "service_down:story",
+		"state": Firing,
+		"since": "2026-01-02T03:04:05Z",
+		"title": testMessage.Title(),
+		"text":  testMessage.Text(),
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s is %q, want %q", k, body[k], v)
+		}
+	}
+}
+
+func TestWebhookNotifierStatus(t *testing.T) {
+	srv, _ := newHTTPServer(t, http.StatusBadGateway, "upstream down\n")
+	n := &WebhookNotifier{name: "hook", URL: srv.URL}
+	err := n.Notify(context.Background(), testMessage)
+	if err == nil || !strings.Contains(err.Error(), "got status 502 Bad Gateway") || !strings.Contains(err.Error(), "upstream down") {
+		t.Errorf("got %v, want the status and the reply", err)
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	const token = "123:abc"
+	srv, received := newHTTPServer(t, http.StatusOK, `{"ok": true}`)
+	n := &TelegramNotifier{name: "telegram", APIURL: srv.URL + "/", BotToken: token, ChatID: "-100"}
+	if err := n.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	r := (*received)[0]
+	if r.path != "/bot"+token+"/sendMessage" {
+		t.Errorf("path %q", r.path)
+	}
+	var body struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		t.Fatalf("body %s: %v", r.body, err)
+	}
+	if body.ChatID != "-100" || body.Text != testMessage.Text() {
+		t.Errorf("got %+v", body)
+	}
+}
+
+func TestTelegramNotifierHidesToken(t *testing.T) {
+	const token = "123:abc"
+	srv, _ := newHTTPServer(t, http.StatusUnauthorized, `{"ok": false, "description": "Unauthorized"}`)
+	n := &TelegramNotifier{name: "telegram", APIURL: srv.URL, BotToken: token, ChatID: "-100"}
+	err := n.Notify(context.Background(), testMessage)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("got %v, want a 401 error", err)
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Errorf("the error shows the bot token: %v", err)
+	}
+
+	// Errors about an unreachable server carry the whole URL
+	srv.Close()
+	err = n.Notify(context.Background(), testMessage)
+	if err == nil || strings.Contains(err.Error(), token) || !strings.Contains(err.Error(), "<bot_token>") {
+		t.Errorf("got %v, want the token replaced", err)
+	}
+}
+
+// mail is what the fake SMTP server received
+type mail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a plain text SMTP server that accepts every message, and
+// AUTH PLAIN if auth is set
+func smtpServer(t *testing.T, auth bool) (int, func() []mail) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var mu sync.Mutex
+	var mails []mail
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			m := serveSMTP(conn, auth)
+			mu.Lock()
+			mails = append(mails, m)
+			mu.Unlock()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port, func() []mail {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]mail(nil), mails...)
+	}
+}
+
+// serveSMTP answers one session
+func serveSMTP(conn net.Conn, auth bool) mail {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { fmt.Fprintf(conn, "%s\r\n", s) }
+	var m mail
+	reply("220 test ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return m
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			if auth {
+				reply("250-test")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 test")
+			}
+		case "AUTH":
+			m.auth = line
+			reply("235 accepted")
+		case "MAIL":
+			m.from = line
+			reply("250 ok")
+		case "RCPT":
+			m.to = append(m.to, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return m
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			m.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return m
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantAuth string
+	}{
+		{name: "without authentication"},
+		{
+			name:     "with authentication",
+			username: "alerts",
+			// base64 of "\x00alerts\x00secret"
+			wantAuth: "AUTH PLAIN AGFsZXJ0cwBzZWNyZXQ=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, mails := smtpServer(t, tt.username != "")
+			n := &SMTPNotifier{
+				name:     "mail",
+				Host:     "127.0.0.1",
+				Port:     port,
+				Username: tt.username,
+				Password: "secret",
+				From:     "scli@example.com",
+				To:       []string{"ops@example.com", "oncall@example.com"},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := n.Notify(ctx, testMessage); err != nil {
+				t.Fatal(err)
+			}
+
+			got := mails()
+			if len(got) != 1 {
+				t.Fatalf("%d sessions, want 1", len(got))
+			}
+			m := got[0]
+			if m.auth != tt.wantAuth {
+				t.Errorf("auth %q, want %q", m.auth, tt.wantAuth)
+			}
+			if m.from != "MAIL FROM:<scli@example.com>" {
+				t.Errorf("from %q", m.from)
+			}
+			if want := "RCPT TO:<ops@example.com> RCPT TO:<oncall@example.com>"; strings.Join(m.to, " ") != want {
+				t.Errorf("recipients %q, want %q", m.to, want)
+			}
+			for _, want := range []string{
+				"To: ops@example.com, oncall@example.com\r\n",
+				"Subject: " + testMessage.Title() + "\r\n",
+				"\r\n\r\n[FIRING] mainnet on node-1: story is not active\r\n",
+				"Firing since: 2026-01-02T03:04:05Z\r\n",
+			} {
+				if !strings.Contains(m.data, want) {
+					t.Errorf("the email lacks %q:\n%s", want, m.data)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierRefusedRecipient(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 test\r\n")
+		for _, answer := range []string{"250 test", "250 ok", "550 no such user"} {
+			if _, err := r.ReadString('\n'); err != nil {
+				return
+			}
+			fmt.Fprintf(conn, "%s\r\n", answer)
+		}
+		r.ReadString('\n')
+	}()
+
+	n := &SMTPNotifier{name: "mail", Host: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port, From: "scli@example.com", To: []string{"nobody@example.com"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = n.Notify(ctx, testMessage)
+	if err == nil || !strings.Contains(err.Error(), "recipient nobody@example.com refused") {
+		t.Errorf("got %v, want the refused recipient", err)
+	}
+}
+
+// fakeNotifier records the messages it delivers and fails while failing is
+// set
+type fakeNotifier struct {
+	failing   bool
+	delivered []string
+}
+
+func (n *fakeNotifier) Name() string {
+	return "fake"
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, m Message) error {
+	if n.failing {
+		return errors.New("unreachable")
+	}
+	n.delivered = append(n.delivered, m.Summary)
+	return nil
+}
+
+// messages returns messages with the summaries from first to last
+func messages(first, last int) []Message {
+	var msgs []Message
+	for i := first; i <= last; i++ {
+		m := testMessage
+		m.Summary = strconv.Itoa(i)
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestDispatcherRetriesInOrder(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	up := &fakeNotifier{}
+	down := &fakeNotifier{failing: true}
+	d := &Dispatcher{Notifiers: []Notifier{up, down}}
+
+	d.Send(context.Background(), messages(1, 2))
+	d.Send(context.Background(), messages(3, 3))
+	if got := strings.Join(up.delivered, " "); got != "1 2 3" {
+		t.Errorf("a working notifier delivered %q, want all messages", got)
+	}
+	if len(down.delivered) != 0 {
+		t.Errorf("a failing notifier delivered %q", down.delivered)
+	}
+
+	down.failing = false
+	d.Send(context.Background(), messages(4, 4))
+	if got := strings.Join(down.delivered, " "); got != "1 2 3 4" {
+		t.Errorf("delivered %q after recovering, want the pending messages first", got)
+	}
+	d.Send(context.Background(), nil)
+	if got := strings.Join(down.delivered, " "); got != "1 2 3 4" {
+		t.Errorf("delivered %q, delivered messages must not be sent again", got)
+	}
+}
+
+func TestDispatcherDropsOldest(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	n := &fakeNotifier{failing: true}
+	d := &Dispatcher{Notifiers: []Notifier{n}}
+	d.Send(context.Background(), messages(1, 60))
+	d.Send(context.Background(), messages(61, 130))
+
+	n.failing = false
+	for i := 0; i < maxPending/maxPerSend; i++ {
+		d.Send(context.Background(), nil)
+	}
+	if len(n.delivered) != maxPending {
+		t.Fatalf("delivered %d messages, want %d", len(n.delivered), maxPending)
+	}
+	if first, last := n.delivered[0], n.delivered[maxPending-1]; first != "31" || last != "130" {
+		t.Errorf("delivered %s to %s, want the newest: 31 to 130", first, last)
+	}
+}
+
+func TestDispatcherCapsSend(t *testing.T) {
+	pterm.DisableOutput()
+	t.Cleanup(pterm.EnableOutput)
+
+	n := &fakeNotifier{failing: true}
+	d := &Dispatcher{Notifiers: []Notifier{n}}
+	d.Send(context.Background(), messages(1, 25))
+
+	n.failing = false
+	for _, want := range []int{10, 20, 25, 25} {
+		d.Send(context.Background(), nil)
+		if len(n.delivered) != want {
+			t.Fatalf("delivered %d messages, want %d", len(n.delivered), want)
+		}
+	}
+	if first, last := n.delivered[0], n.delivered[24]; first != "1" || last != "25" {
+		t.Errorf("delivered %s to %s, want 1 to 25", first, last)
+	}
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpsPort is the port on which SMTP servers expect TLS from the start
+const smtpsPort = 465
+
+// SMTPNotifier emails every message. Port 465 uses implicit TLS; on other
+// ports the connection is upgraded with STARTTLS if the server offers it.
+type SMTPNotifier struct {
+	name     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name returns the configured name of the notifier.
+func (n *SMTPNotifier) Name() string {
+	return n.name
+}
+
+// Notify emails m to every recipient, with its title as the subject.
+func (n *SMTPNotifier) Notify(ctx context.Context, m Message) error {
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+	tlsConfig := &tls.Config{ServerName: n.Host}
+	var conn net.Conn
+	var err error
+	if n.Port == smtpsPort {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if n.Port != smtpsPort {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("STARTTLS failed: %v", err)
+			}
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %v", err)
+		}
+	}
+
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s refused: %v", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.email(m)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// email renders m as a plain text email
+func (n *SMTPNotifier) email(m Message) []byte {
+	var b strings.Builder
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", n.From)
+	header("To", strings.Join(n.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Title()))
+	header("Date", m.Time.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Text(), "\n", "\r\n"))
+	return []byte(b.String())
+}
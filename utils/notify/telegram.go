@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API server.
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// TelegramNotifier sends every message to a chat through a Telegram bot.
+type TelegramNotifier struct {
+	name     string
+	APIURL   string
+	BotToken string
+	ChatID   string
+}
+
+// Name returns the configured name of the notifier.
+func (n *TelegramNotifier) Name() string {
+	return n.name
+}
+
+// Notify sends the text of m with sendMessage.
+func (n *TelegramNotifier) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  n.ChatID,
+		"text":                     m.Text(),
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimRight(n.APIURL, "/") + "/bot" + n.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Keep the bot token, which is part of the URL, out of the logs
+	if err := send(req); err != nil {
+		return errors.New(strings.ReplaceAll(err.Error(), n.BotToken, "<bot_token>"))
+	}
+	return nil
+}
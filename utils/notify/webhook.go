@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WebhookNotifier posts every message as JSON to a URL. The body is the
+// Message with its title and text added.
+type WebhookNotifier struct {
+	name    string
+	URL     string
+	Headers map[string]string
+}
+
+// Name returns the configured name of the notifier.
+func (n *WebhookNotifier) Name() string {
+	return n.name
+}
+
+// Notify posts m to the URL. Any 2xx status counts as delivered.
+func (n *WebhookNotifier) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(struct {
+		Message
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}{m, m.Title(), m.Text()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+	return send(req)
+}
+
+// send does req and fails unless the response has a 2xx status
+func send(req *http.Request) error {
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("got status %s from %s: %s", resp.Status, req.URL.Host, bytes.TrimSpace(detail))
+	}
+	return nil
+}
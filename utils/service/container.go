@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// Container runs each service in a Docker or Podman container named after
+// it. The containers use the host network and run the binaries installed
+// on the host, with Mounts bind-mounted at the same paths; Image only needs
+// to be able to run them.
+type Container struct {
+	// Runtime is the container CLI, "docker" or "podman".
+	Runtime string
+	Image   string
+	Mounts  []string
+}
+
+// Kind returns KindDocker or KindPodman.
+func (c Container) Kind() Kind {
+	return Kind(c.Runtime)
+}
+
+// Install creates a container for each unit, replacing an existing one.
+// Containers restart unless stopped, so the runtime starts them at boot.
+func (c Container) Install(units ...Unit) error {
+	for _, u := range units {
+		if exists, _ := c.Exists(u.Name); exists {
+			if err := c.run("rm", "-f", u.Name); err != nil {
+				return err
+			}
+		}
+		if err := c.run(c.createArgs(u)...); err != nil {
+			return fmt.Errorf("failed to create container %s: %v", u.Name, err)
+		}
+	}
+	return nil
+}
+
+// createArgs returns the arguments creating the container of u
+func (c Container) createArgs(u Unit) []string {
+	args := []string{
+		"create", "--name", u.Name,
+		"--restart", "unless-stopped",
+		"--network", "host",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+	}
+	for _, m := range c.Mounts {
+		args = append(args, "-v", m+":"+m)
+	}
+	if u.WorkDir != "" {
+		args = append(args, "-w", u.WorkDir)
+	}
+	for _, e := range u.Env {
+		args = append(args, "-e", e)
+	}
+	args = append(args, c.Image)
+	return append(args, u.Command...)
+}
+
+// Enable does nothing: the restart policy set by Install already starts
+// the containers with the runtime.
+func (c Container) Enable(names ...string) error {
+	return nil
+}
+
+// Start starts the containers.
+func (c Container) Start(names ...string) error {
+	return c.run(append([]string{"start"}, names...)...)
+}
+
+// Stop stops the containers, giving them 30 seconds to shut down.
+func (c Container) Stop(names ...string) error {
+	return c.run(append([]string{"stop", "-t", "30"}, names...)...)
+}
+
+// Restart restarts the containers.
+func (c Container) Restart(names ...string) error {
+	return c.run(append([]string{"restart", "-t", "30"}, names...)...)
+}
+
+// Exists reports whether the container exists.
+func (c Container) Exists(name string) (bool, error) {
+	_, err := executor.Query(executor.Cmd(c.Runtime, "container", "inspect", "--format", "{{.Name}}", name))
+	return err == nil, nil
+}
+
+// Active reports whether the container is running.
+func (c Container) Active(name string) (bool, error) {
+	out, err := executor.Query(executor.Cmd(c.Runtime, "container", "inspect", "--format", "{{.State.Running}}", name))
+	if err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// Status writes the state of the container and its last three log lines
+// to out.
+func (c Container) Status(name string, out io.Writer) error {
+	cmd := executor.Cmd(c.Runtime, "container", "inspect", "--format", "{{.Name}}: {{.State.Status}} since {{.State.StartedAt}} ({{.Config.Image}})", name)
+	cmd.Stdout = out
+	if _, err := executor.Query(cmd); err != nil {
+		return err
+	}
+	return c.Logs(name, 3, false, out)
+}
+
+// Logs writes the output of the container to out.
+func (c Container) Logs(name string, lines int, follow bool, out io.Writer) error {
+	args := []string{"logs", "--tail", strconv.Itoa(lines)}
+	if follow {
+		args = append(args, "-f")
+	}
+	cmd := executor.Cmd(c.Runtime, append(args, name)...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	_, err := executor.Query(cmd)
+	return err
+}
+
+// Command returns the command of the container.
+func (c Container) Command(name string) ([]string, error) {
+	out, err := executor.Query(executor.Cmd(c.Runtime, "container", "inspect", "--format", "{{json .Config.Cmd}}", name))
+	if err != nil {
+		return nil, err
+	}
+	var command []string
+	if err := json.Unmarshal(out, &command); err != nil {
+		return nil, fmt.Errorf("failed to parse the command of container %s: %v", name, err)
+	}
+	return command, nil
+}
+
+// MainPID returns the host pid of the init process of the container.
+func (c Container) MainPID(name string) (int, error) {
+	out, err := executor.Query(executor.Cmd(c.Runtime, "container", "inspect", "--format", "{{.State.Pid}}", name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+func (c Container) run(args ...string) error {
+	return executor.Run(executor.Cmd(c.Runtime, args...))
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/sSelmann/storycli/utils/config"
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// stopTimeout is how long a supervised process gets to exit after SIGTERM
+// before it is killed.
+const stopTimeout = 60 * time.Second
+
+// ProcessDir returns the directory holding the unit, pidfile and log of
+// each service supervised by scli, <home>/services.
+func ProcessDir(node config.Profile) string {
+	return filepath.Join(node.HomeDir, "services")
+}
+
+// Process runs each service as a child of `scli supervise`, which restarts
+// it when it fails. Dir holds <name>.json with the unit, <name>.pid with the
+// pid of the supervisor while it runs and <name>.log with the output.
+// Nothing starts the services at boot.
+type Process struct {
+	Dir string
+}
+
+// Kind returns KindProcess.
+func (p Process) Kind() Kind {
+	return KindProcess
+}
+
+func (p Process) unitPath(name string) string {
+	return filepath.Join(p.Dir, name+".json")
+}
+
+func (p Process) pidPath(name string) string {
+	return filepath.Join(p.Dir, name+".pid")
+}
+
+// LogPath returns the log file of the named service.
+func (p Process) LogPath(name string) string {
+	return filepath.Join(p.Dir, name+".log")
+}
+
+// Install writes the units to Dir.
+func (p Process) Install(units ...Unit) error {
+	if err := executor.Active().MkdirAll(p.Dir, 0755); err != nil {
+		return err
+	}
+	for _, u := range units {
+		data, err := json.MarshalIndent(u, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := executor.Active().WriteFile(p.unitPath(u.Name), append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", p.unitPath(u.Name), err)
+		}
+	}
+	return nil
+}
+
+// Enable only warns: supervised processes are not started at boot.
+func (p Process) Enable(names ...string) error {
+	pterm.Warning.Printf("Services run by the process service manager are not started at boot; add `@reboot scli restart` (with --profile if needed) to your crontab to start %s.\n", strings.Join(names, " and "))
+	return nil
+}
+
+// Start starts a supervisor for each service that is not running.
+func (p Process) Start(names ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if active, _ := p.Active(name); active {
+			continue
+		}
+		if _, err := os.Stat(p.unitPath(name)); err != nil {
+			return fmt.Errorf("service %s is not installed: %v", name, err)
+		}
+		err := executor.Perform(fmt.Sprintf("start %s in the background, logging to %s", p.superviseCmd(exe, name), p.LogPath(name)), func() error {
+			return p.spawn(exe, name)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// superviseCmd returns the `scli supervise` command of the named service
+func (p Process) superviseCmd(exe, name string) executor.Command {
+	return executor.Cmd(exe, "supervise", "--dir", p.Dir, name)
+}
+
+// spawn starts `scli supervise` for the named service in a session of its
+// own, so it keeps running after scli exits, and waits for its pidfile
+func (p Process) spawn(exe, name string) error {
+	logFile, err := os.OpenFile(p.LogPath(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	c := p.superviseCmd(exe, name)
+	c.Stdout = logFile
+	c.Stderr = logFile
+	cmd := c.Exec()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		if active, _ := p.Active(name); active {
+			return nil
+		}
+		select {
+		case err := <-exited:
+			return fmt.Errorf("supervisor exited (%v), see %s", err, p.LogPath(name))
+		case <-deadline:
+			return fmt.Errorf("supervisor did not write %s", p.pidPath(name))
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Stop sends SIGTERM to the supervisors, which stop their service, and
+// waits for them to exit.
+func (p Process) Stop(names ...string) error {
+	for _, name := range names {
+		pid, alive := p.pid(name)
+		if !alive {
+			continue
+		}
+		if err := executor.Run(executor.Cmd("kill", "-TERM", strconv.Itoa(pid))); err != nil {
+			return fmt.Errorf("failed to stop %s: %v", name, err)
+		}
+		err := executor.Perform(fmt.Sprintf("wait for %s (pid %d) to exit", name, pid), func() error {
+			deadline := time.Now().Add(stopTimeout + 10*time.Second)
+			for processAlive(pid) {
+				if time.Now().After(deadline) {
+					return fmt.Errorf("%s (pid %d) did not exit", name, pid)
+				}
+				time.Sleep(200 * time.Millisecond)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restart stops and starts the services.
+func (p Process) Restart(names ...string) error {
+	if err := p.Stop(names...); err != nil {
+		return err
+	}
+	return p.Start(names...)
+}
+
+// Exists reports whether the unit of the service is in Dir.
+func (p Process) Exists(name string) (bool, error) {
+	_, err := os.Stat(p.unitPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Active reports whether the supervisor of the service is running.
+func (p Process) Active(name string) (bool, error) {
+	_, alive := p.pid(name)
+	return alive, nil
+}
+
+// Status writes whether the service runs, its command and its last three
+// log lines to out.
+func (p Process) Status(name string, out io.Writer) error {
+	unit, err := p.loadUnit(name)
+	if err != nil {
+		return err
+	}
+	if pid, alive := p.pid(name); alive {
+		fmt.Fprintf(out, "%s: active, supervised by scli (pid %d)\n", name, pid)
+	} else {
+		fmt.Fprintf(out, "%s: inactive\n", name)
+	}
+	fmt.Fprintf(out, "command: %s\nlog: %s\n", strings.Join(unit.Command, " "), p.LogPath(name))
+	return p.Logs(name, 3, false, out)
+}
+
+// Logs writes the log file of the service to out.
+func (p Process) Logs(name string, lines int, follow bool, out io.Writer) error {
+	args := []string{"-n", strconv.Itoa(lines)}
+	if follow {
+		args = append(args, "-F")
+	}
+	cmd := executor.Cmd("tail", append(args, p.LogPath(name))...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	_, err := executor.Query(cmd)
+	return err
+}
+
+// Command returns the command of the unit.
+func (p Process) Command(name string) ([]string, error) {
+	unit, err := p.loadUnit(name)
+	return unit.Command, err
+}
+
+// MainPID returns the pid of the supervisor, whose child runs the service.
+func (p Process) MainPID(name string) (int, error) {
+	pid, alive := p.pid(name)
+	if !alive {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+func (p Process) loadUnit(name string) (Unit, error) {
+	var unit Unit
+	data, err := os.ReadFile(p.unitPath(name))
+	if err != nil {
+		return unit, err
+	}
+	if err := json.Unmarshal(data, &unit); err != nil {
+		return unit, fmt.Errorf("failed to parse %s: %v", p.unitPath(name), err)
+	}
+	if len(unit.Command) == 0 {
+		return unit, fmt.Errorf("%s has no command", p.unitPath(name))
+	}
+	return unit, nil
+}
+
+// pid returns the pid in the pidfile of the service and whether that
+// process is running
+func (p Process) pid(name string) (int, bool) {
+	data, err := os.ReadFile(p.pidPath(name))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, processAlive(pid)
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Supervise runs the named service until it exits cleanly or the
+// supervisor gets SIGTERM or SIGINT, restarting it RestartSec seconds
+// after each failure. It is run by `scli supervise`, detached from the
+// terminal and with its output going to the log file.
+func (p Process) Supervise(name string) error {
+	unit, err := p.loadUnit(name)
+	if err != nil {
+		return err
+	}
+	if pid, alive := p.pid(name); alive {
+		return fmt.Errorf("%s is already supervised (pid %d)", name, pid)
+	}
+	if err := os.WriteFile(p.pidPath(name), []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(p.pidPath(name))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	signal.Ignore(syscall.SIGHUP)
+
+	delay := time.Duration(unit.RestartSec) * time.Second
+	if delay <= 0 {
+		delay = 5 * time.Second
+	}
+	logf := func(format string, args ...interface{}) {
+		fmt.Printf("%s scli: %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+	}
+
+	for {
+		c := executor.Command{
+			Name:   unit.Command[0],
+			Args:   unit.Command[1:],
+			Dir:    unit.WorkDir,
+			Env:    unit.Env,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		}
+		logf("starting %s", strings.Join(unit.Command, " "))
+		var stopped bool
+		err := executor.Perform("run "+c.String(), func() error {
+			var err error
+			stopped, err = runSupervised(name, c, signals, logf)
+			return err
+		})
+		if stopped {
+			return nil
+		}
+		if err == nil {
+			logf("%s exited cleanly", name)
+			return nil
+		}
+		logf("%s failed: %v; restarting in %v", name, err, delay)
+
+		select {
+		case sig := <-signals:
+			logf("got %v, not restarting %s", sig, name)
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runSupervised runs c until it exits, or until the supervisor gets a
+// signal, in which case c is stopped and stopped is set
+func runSupervised(name string, c executor.Command, signals <-chan os.Signal, logf func(format string, args ...interface{})) (stopped bool, err error) {
+	cmd := c.Exec()
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	select {
+	case sig := <-signals:
+		logf("got %v, stopping %s", sig, name)
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-exited:
+		case <-time.After(stopTimeout):
+			logf("%s did not stop within %v, killing it", name, stopTimeout)
+			_ = cmd.Process.Kill()
+			<-exited
+		}
+		return true, nil
+	case err := <-exited:
+		return false, err
+	}
+}
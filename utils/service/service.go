@@ -0,0 +1,113 @@
+// Package service installs, starts and stops the story and geth services
+// with the service manager a profile uses: systemd, systemd user units,
+// Docker or Podman containers, or processes supervised by scli itself.
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/config"
+)
+
+// Kind is a service manager backend, set per profile with service_manager.
+type Kind string
+
+const (
+	// KindSystemd runs system units in /etc/systemd/system, managed with
+	// sudo systemctl.
+	KindSystemd Kind = "systemd"
+	// KindSystemdUser runs user units in ~/.config/systemd/user, managed
+	// with systemctl --user and without root.
+	KindSystemdUser Kind = "systemd-user"
+	// KindDocker runs each service in a Docker container.
+	KindDocker Kind = "docker"
+	// KindPodman runs each service in a Podman container.
+	KindPodman Kind = "podman"
+	// KindProcess runs each service as a process restarted by
+	// `scli supervise`, tracked with a pidfile.
+	KindProcess Kind = "process"
+)
+
+// Kinds lists the service manager backends.
+var Kinds = []Kind{KindSystemd, KindSystemdUser, KindDocker, KindPodman, KindProcess}
+
+// ParseKind checks a service_manager value.
+func ParseKind(s string) (Kind, error) {
+	for _, k := range Kinds {
+		if string(k) == s {
+			return k, nil
+		}
+	}
+	names := make([]string, len(Kinds))
+	for i, k := range Kinds {
+		names[i] = string(k)
+	}
+	return "", fmt.Errorf("unknown service manager %q (use one of %s)", s, strings.Join(names, ", "))
+}
+
+// Unit describes a service independently of the manager that runs it.
+type Unit struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Command is the binary to run followed by its arguments.
+	Command []string `json:"command"`
+	// WorkDir is the working directory of the service.
+	WorkDir string `json:"work_dir,omitempty"`
+	// Env holds KEY=VALUE pairs set for the service.
+	Env []string `json:"env,omitempty"`
+	// RestartSec is how long to wait before restarting the service after
+	// it fails.
+	RestartSec int `json:"restart_sec"`
+}
+
+// Manager runs services. Commands that change the system go through the
+// active executor, so --dry-run prints them.
+type Manager interface {
+	Kind() Kind
+	// Install creates or replaces the definitions of units, without
+	// starting them.
+	Install(units ...Unit) error
+	// Enable makes the services start when the machine boots.
+	Enable(names ...string) error
+	Start(names ...string) error
+	Stop(names ...string) error
+	Restart(names ...string) error
+	// Exists reports whether the service is installed.
+	Exists(name string) (bool, error)
+	// Active reports whether the service is running.
+	Active(name string) (bool, error)
+	// Status writes a short description of the service and its last log
+	// lines to out.
+	Status(name string, out io.Writer) error
+	// Command returns the command line the installed service runs.
+	Command(name string) ([]string, error)
+	// MainPID returns the pid of the process running the service, 0 if it
+	// doesn't run. The service may run in children of that process.
+	MainPID(name string) (int, error)
+	// Logs writes the last lines of the service log to out, and keeps
+	// writing new lines if follow is set.
+	Logs(name string, lines int, follow bool, out io.Writer) error
+}
+
+// ForProfile returns the service manager selected by the profile.
+func ForProfile(node config.Profile) (Manager, error) {
+	kind, err := ParseKind(node.ServiceManager)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %v", node.Name, err)
+	}
+	switch kind {
+	case KindSystemdUser:
+		return Systemd{User: true}, nil
+	case KindDocker, KindPodman:
+		return Container{
+			Runtime: string(kind),
+			Image:   node.ContainerImage,
+			Mounts:  []string{node.HomeDir, node.BinDir},
+		}, nil
+	case KindProcess:
+		return Process{Dir: ProcessDir(node)}, nil
+	}
+	return Systemd{}, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// Systemd manages systemd units. System units are written to
+// /etc/systemd/system and changed with sudo; user units live in
+// ~/.config/systemd/user and need no root.
+type Systemd struct {
+	User bool
+}
+
+// Kind returns KindSystemd or KindSystemdUser.
+func (s Systemd) Kind() Kind {
+	if s.User {
+		return KindSystemdUser
+	}
+	return KindSystemd
+}
+
+// UnitDir returns the directory the unit files are written to.
+func (s Systemd) UnitDir() (string, error) {
+	if !s.User {
+		return "/etc/systemd/system", nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user"), nil
+}
+
+// Install writes the unit files and reloads systemd.
+func (s Systemd) Install(units ...Unit) error {
+	dir, err := s.UnitDir()
+	if err != nil {
+		return err
+	}
+	if s.User {
+		if err := executor.Active().MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	for _, u := range units {
+		path := filepath.Join(dir, u.Name+".service")
+		if err := executor.Active().WriteFile(path, []byte(s.unitFile(u)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+	return s.systemctl("daemon-reload")
+}
+
+// Enable enables the units. User units are only started at boot if the
+// user lingers, so lingering is enabled as well.
+func (s Systemd) Enable(names ...string) error {
+	if err := s.systemctl(append([]string{"enable"}, names...)...); err != nil {
+		return err
+	}
+	if s.User {
+		return executor.Run(executor.Cmd("loginctl", "enable-linger", currentUser()))
+	}
+	return nil
+}
+
+// Start starts the units.
+func (s Systemd) Start(names ...string) error {
+	return s.systemctl(append([]string{"start"}, names...)...)
+}
+
+// Stop stops the units.
+func (s Systemd) Stop(names ...string) error {
+	return s.systemctl(append([]string{"stop"}, names...)...)
+}
+
+// Restart restarts the units.
+func (s Systemd) Restart(names ...string) error {
+	return s.systemctl(append([]string{"restart"}, names...)...)
+}
+
+// Exists reports whether the unit file is known to systemd.
+func (s Systemd) Exists(name string) (bool, error) {
+	out, err := executor.Query(s.query("list-unit-files", name+".service"))
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Contains(out, []byte(name+".service")), nil
+}
+
+// Active reports whether the unit is running.
+func (s Systemd) Active(name string) (bool, error) {
+	_, err := executor.Query(s.query("is-active", "--quiet", name))
+	return err == nil, nil
+}
+
+// Status writes systemctl status with the last three log lines to out.
+func (s Systemd) Status(name string, out io.Writer) error {
+	cmd := s.query("status", name, "--no-pager", "-n", "3")
+	cmd.Stdout = out
+	cmd.Stderr = out
+	_, err := executor.Query(cmd)
+	return err
+}
+
+// Logs writes the journal of the unit to out.
+func (s Systemd) Logs(name string, lines int, follow bool, out io.Writer) error {
+	args := []string{"-u", name, "-o", "cat", "-n", strconv.Itoa(lines)}
+	if s.User {
+		args = append([]string{"--user"}, args...)
+	}
+	if follow {
+		args = append(args, "-f")
+	}
+	cmd := executor.Cmd("journalctl", args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	_, err := executor.Query(cmd)
+	return err
+}
+
+// Command returns the argv of ExecStart, which systemctl show prints as
+// "{ path=... ; argv[]=<args> ; ... }".
+func (s Systemd) Command(name string) ([]string, error) {
+	out, err := executor.Query(s.query("show", "-p", "ExecStart", "--value", name))
+	if err != nil {
+		return nil, err
+	}
+	_, argv, ok := strings.Cut(string(out), "argv[]=")
+	if !ok {
+		return nil, fmt.Errorf("%s has no ExecStart", name)
+	}
+	argv, _, _ = strings.Cut(argv, " ;")
+	return strings.Fields(argv), nil
+}
+
+// MainPID returns the main pid systemd tracks for the unit.
+func (s Systemd) MainPID(name string) (int, error) {
+	out, err := executor.Query(s.query("show", "-p", "MainPID", "--value", name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+// systemctl runs a systemctl command that changes the system
+func (s Systemd) systemctl(args ...string) error {
+	if s.User {
+		return executor.Run(executor.Cmd("systemctl", append([]string{"--user"}, args...)...))
+	}
+	return executor.Run(executor.Cmd("sudo", append([]string{"systemctl"}, args...)...))
+}
+
+// query returns a systemctl command that only reads the system
+func (s Systemd) query(args ...string) executor.Command {
+	if s.User {
+		args = append([]string{"--user"}, args...)
+	}
+	return executor.Cmd("systemctl", args...)
+}
+
+// unitFile renders u as a systemd unit
+func (s Systemd) unitFile(u Unit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\nAfter=network-online.target\n\n[Service]\n", u.Description)
+	if !s.User {
+		fmt.Fprintf(&b, "User=%s\n", currentUser())
+	}
+	if u.WorkDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", u.WorkDir)
+	}
+	args := make([]string, len(u.Command))
+	for i, a := range u.Command {
+		args[i] = systemdQuote(a)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+	for _, e := range u.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(e))
+	}
+	wantedBy := "multi-user.target"
+	if s.User {
+		wantedBy = "default.target"
+	}
+	fmt.Fprintf(&b, "\nRestart=on-failure\nRestartSec=%d\nLimitNOFILE=65535\n\n[Install]\nWantedBy=%s\n", u.RestartSec, wantedBy)
+	return b.String()
+}
+
+// currentUser returns the name of the user running scli, who the services
+// run as
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// systemdQuote quotes s for a unit file, escaping the $ and % that systemd
+// would expand
+func systemdQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
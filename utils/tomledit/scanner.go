@@ -0,0 +1,293 @@
+package tomledit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tableHeader is a [table] or [[array]] header found while scanning.
+type tableHeader struct {
+	name  []string
+	line  int
+	array bool
+}
+
+// scanner walks the document lines keeping a line/column cursor.
+type scanner struct {
+	lines []string
+	line  int
+	col   int
+}
+
+// scan locates every key/value pair and table header in the document.
+// Keys inside arrays of tables are not addressable and are skipped.
+func (d *Document) scan() ([]entry, []tableHeader, error) {
+	s := &scanner{lines: d.lines}
+
+	var (
+		entries     []entry
+		tables      []tableHeader
+		current     []string
+		arrayTables [][]string
+		inArray     bool
+	)
+
+	for s.line < len(s.lines) {
+		s.skipSpace()
+		if s.eol() {
+			s.nextLine()
+			continue
+		}
+
+		switch s.peek() {
+		case '#':
+			s.nextLine()
+		case '[':
+			array := strings.HasPrefix(s.rest(), "[[")
+			if array {
+				s.col += 2
+			} else {
+				s.col++
+			}
+			name, err := s.readKey(']')
+			if err != nil {
+				return nil, nil, err
+			}
+			closing := "]"
+			if array {
+				closing = "]]"
+			}
+			if !strings.HasPrefix(s.rest(), closing) {
+				return nil, nil, s.errorf("unterminated table header")
+			}
+
+			tables = append(tables, tableHeader{name: name, line: s.line, array: array})
+			current = name
+			if array {
+				arrayTables = append(arrayTables, name)
+			}
+			inArray = withinArrayTable(name, arrayTables)
+			s.nextLine()
+		default:
+			key, err := s.readKey('=')
+			if err != nil {
+				return nil, nil, err
+			}
+			s.col++ // skip '='
+			s.skipSpace()
+
+			start := span{startLine: s.line, startCol: s.col}
+			if err := s.skipValue(); err != nil {
+				return nil, nil, err
+			}
+			start.endLine, start.endCol = s.line, s.col
+
+			if !inArray {
+				full := append(append([]string(nil), current...), key...)
+				entries = append(entries, entry{key: full, header: len(current), value: start})
+			}
+			s.nextLine()
+		}
+	}
+
+	return entries, tables, nil
+}
+
+// withinArrayTable reports whether name is an array table or one of its sub-tables.
+func withinArrayTable(name []string, arrayTables [][]string) bool {
+	for _, a := range arrayTables {
+		if len(name) >= len(a) && equalPath(name[:len(a)], a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", s.line+1, fmt.Sprintf(format, args...))
+}
+
+func (s *scanner) eol() bool {
+	return s.line >= len(s.lines) || s.col >= len(s.lines[s.line])
+}
+
+func (s *scanner) peek() byte {
+	return s.lines[s.line][s.col]
+}
+
+func (s *scanner) rest() string {
+	if s.eol() {
+		return ""
+	}
+	return s.lines[s.line][s.col:]
+}
+
+func (s *scanner) nextLine() {
+	s.line++
+	s.col = 0
+}
+
+func (s *scanner) skipSpace() {
+	for !s.eol() && (s.peek() == ' ' || s.peek() == '\t') {
+		s.col++
+	}
+}
+
+// readKey reads a (possibly dotted) key up to, but not including, term.
+func (s *scanner) readKey(term byte) ([]string, error) {
+	var parts []string
+	for {
+		s.skipSpace()
+		if s.eol() {
+			return nil, s.errorf("unexpected end of line in key")
+		}
+
+		switch c := s.peek(); {
+		case c == '"':
+			start := s.col
+			if err := s.skipString('"', true); err != nil {
+				return nil, err
+			}
+			part, err := strconv.Unquote(s.lines[s.line][start:s.col])
+			if err != nil {
+				return nil, s.errorf("invalid quoted key: %v", err)
+			}
+			parts = append(parts, part)
+		case c == '\'':
+			start := s.col
+			if err := s.skipString('\'', false); err != nil {
+				return nil, err
+			}
+			parts = append(parts, s.lines[s.line][start+1:s.col-1])
+		case isBareKeyChar(c):
+			start := s.col
+			for !s.eol() && isBareKeyChar(s.peek()) {
+				s.col++
+			}
+			parts = append(parts, s.lines[s.line][start:s.col])
+		default:
+			return nil, s.errorf("unexpected character %q in key", c)
+		}
+
+		s.skipSpace()
+		if s.eol() {
+			return nil, s.errorf("unexpected end of line in key")
+		}
+		switch s.peek() {
+		case '.':
+			s.col++
+		case term:
+			return parts, nil
+		default:
+			return nil, s.errorf("unexpected character %q after key", s.peek())
+		}
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// skipValue moves the cursor past the value starting at the cursor.
+func (s *scanner) skipValue() error {
+	if s.eol() {
+		return s.errorf("missing value")
+	}
+
+	switch s.peek() {
+	case '"':
+		return s.skipString('"', true)
+	case '\'':
+		return s.skipString('\'', false)
+	case '[', '{':
+		return s.skipContainer()
+	default:
+		// Scalars end at a comment or the end of the line
+		line := s.lines[s.line]
+		end := len(line)
+		if i := strings.IndexByte(line[s.col:], '#'); i >= 0 {
+			end = s.col + i
+		}
+		for end > s.col && (line[end-1] == ' ' || line[end-1] == '\t') {
+			end--
+		}
+		if end == s.col {
+			return s.errorf("missing value")
+		}
+		s.col = end
+		return nil
+	}
+}
+
+// skipString skips a basic or literal string, including multi-line forms.
+func (s *scanner) skipString(quote byte, escapes bool) error {
+	triple := strings.Repeat(string(quote), 3)
+	if strings.HasPrefix(s.rest(), triple) {
+		s.col += 3
+		for s.line < len(s.lines) {
+			for !s.eol() {
+				c := s.peek()
+				if escapes && c == '\\' {
+					s.col += 2
+					continue
+				}
+				if strings.HasPrefix(s.rest(), triple) {
+					s.col += 3
+					// Up to two quotes may directly precede the closing delimiter
+					for i := 0; i < 2 && !s.eol() && s.peek() == quote; i++ {
+						s.col++
+					}
+					return nil
+				}
+				s.col++
+			}
+			s.nextLine()
+		}
+		return s.errorf("unterminated multi-line string")
+	}
+
+	s.col++
+	for !s.eol() {
+		c := s.peek()
+		if escapes && c == '\\' {
+			s.col += 2
+			continue
+		}
+		s.col++
+		if c == quote {
+			return nil
+		}
+	}
+	return s.errorf("unterminated string")
+}
+
+// skipContainer skips an array or inline table, which may span lines.
+func (s *scanner) skipContainer() error {
+	depth := 0
+	for s.line < len(s.lines) {
+		for !s.eol() {
+			switch c := s.peek(); c {
+			case '"', '\'':
+				if err := s.skipString(c, c == '"'); err != nil {
+					return err
+				}
+				continue
+			case '#':
+				s.col = len(s.lines[s.line])
+				continue
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					s.col++
+					return nil
+				}
+			}
+			s.col++
+		}
+		s.nextLine()
+	}
+	return fmt.Errorf("unterminated array or inline table")
+}
@@ -0,0 +1,411 @@
+// Package tomledit edits TOML documents in place. Only the value of the
+// targeted key is rewritten; comments, ordering, whitespace and keys that are
+// not touched are kept byte for byte.
+package tomledit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/sSelmann/storycli/utils/executor"
+)
+
+// Document is a TOML document kept as its original lines.
+type Document struct {
+	lines        []string
+	newline      string
+	trailingLine bool
+}
+
+// span marks the location of a value inside the document.
+type span struct {
+	startLine, startCol int
+	endLine, endCol     int
+}
+
+// entry is a key/value pair found while scanning the document.
+type entry struct {
+	key []string // full key path including the table header
+	// header is how many parts of key come from the table header; the
+	// rest were written as a (dotted) key on the line
+	header int
+	value  span
+}
+
+// Parse parses data into a Document. The data must be valid TOML.
+func Parse(data []byte) (*Document, error) {
+	var check map[string]interface{}
+	if err := toml.Unmarshal(data, &check); err != nil {
+		return nil, fmt.Errorf("invalid TOML: %w", err)
+	}
+
+	text := string(data)
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+	}
+	trailing := strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\r")
+
+	var lines []string
+	if text != "" || trailing {
+		lines = strings.Split(text, newline)
+	}
+	return &Document{lines: lines, newline: newline, trailingLine: trailing}, nil
+}
+
+// Load reads and parses the TOML file at path.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Bytes returns the document contents.
+func (d *Document) Bytes() []byte {
+	out := strings.Join(d.lines, d.newline)
+	if d.trailingLine {
+		out += d.newline
+	}
+	return []byte(out)
+}
+
+// Save writes the document to path atomically, keeping the file mode of an
+// existing file.
+func (d *Document) Save(path string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	ex := executor.Active()
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := ex.WriteFile(tmp, d.Bytes(), mode); err != nil {
+		return err
+	}
+	if err := ex.Chmod(tmp, mode); err != nil {
+		ex.RemoveAll(tmp)
+		return err
+	}
+	if err := ex.Rename(tmp, path); err != nil {
+		ex.RemoveAll(tmp)
+		return err
+	}
+	return nil
+}
+
+// Has reports whether the dotted key exists in the document.
+func (d *Document) Has(key string) bool {
+	_, ok := d.Get(key)
+	return ok
+}
+
+// Get returns the raw TOML text of the value stored under the dotted key.
+func (d *Document) Get(key string) (string, bool) {
+	entries, _, err := d.scan()
+	if err != nil {
+		return "", false
+	}
+	path := SplitKey(key)
+	for _, e := range entries {
+		if equalPath(e.key, path) {
+			return d.text(e.value), true
+		}
+	}
+	return "", false
+}
+
+// Set encodes value as TOML and stores it under the dotted key. Existing
+// values are replaced in place; missing keys are appended to their table,
+// which is created at the end of the document when needed.
+func (d *Document) Set(key string, value interface{}) error {
+	raw, err := Encode(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s: %w", key, err)
+	}
+	return d.SetRaw(key, raw)
+}
+
+// SetRaw stores raw, which must already be valid TOML value syntax, under the
+// dotted key.
+func (d *Document) SetRaw(key, raw string) error {
+	path := SplitKey(key)
+	if len(path) == 0 {
+		return errors.New("empty key")
+	}
+
+	entries, tables, err := d.scan()
+	if err != nil {
+		return err
+	}
+
+	backup := append([]string(nil), d.lines...)
+	replaced := false
+	for _, e := range entries {
+		if equalPath(e.key, path) {
+			d.replace(e.value, raw)
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		d.insert(path, raw, entries, tables)
+	}
+
+	// Make sure the edit left a valid document behind
+	var check map[string]interface{}
+	if err := toml.Unmarshal(d.Bytes(), &check); err != nil {
+		d.lines = backup
+		return fmt.Errorf("setting %s would produce invalid TOML: %w", key, err)
+	}
+	return nil
+}
+
+// text returns the document text covered by s.
+func (d *Document) text(s span) string {
+	if s.startLine == s.endLine {
+		return d.lines[s.startLine][s.startCol:s.endCol]
+	}
+	parts := []string{d.lines[s.startLine][s.startCol:]}
+	parts = append(parts, d.lines[s.startLine+1:s.endLine]...)
+	parts = append(parts, d.lines[s.endLine][:s.endCol])
+	return strings.Join(parts, d.newline)
+}
+
+// replace swaps the text covered by s for raw.
+func (d *Document) replace(s span, raw string) {
+	line := d.lines[s.startLine][:s.startCol] + raw + d.lines[s.endLine][s.endCol:]
+	rest := append([]string{line}, d.lines[s.endLine+1:]...)
+	d.lines = append(d.lines[:s.startLine], rest...)
+}
+
+// insert adds a new key line to the table that owns path.
+func (d *Document) insert(path []string, raw string, entries []entry, tables []tableHeader) {
+	table := path[:len(path)-1]
+	// line writes the key relative to the header it ends up under, as
+	// the table may be reached through dotted keys below another header
+	line := func(header int) string {
+		return formatPath(path[header:]) + " = " + raw
+	}
+
+	// Insert after the last key that belongs to the table
+	lastLine, header := -1, len(table)
+	for _, e := range entries {
+		if equalPath(e.key[:len(e.key)-1], table) {
+			lastLine, header = e.value.endLine, e.header
+		}
+	}
+
+	if lastLine < 0 {
+		for _, t := range tables {
+			if equalPath(t.name, table) {
+				lastLine = t.line
+			}
+		}
+	}
+
+	if lastLine < 0 && len(table) == 0 {
+		// Root table without keys: insert before the first table header
+		insertAt := len(d.lines)
+		if len(tables) > 0 {
+			insertAt = tables[0].line
+		}
+		d.insertLines(insertAt, line(0), "")
+		return
+	}
+
+	if lastLine < 0 {
+		// Create the table at the end of the document
+		tableLine := "[" + formatPath(table) + "]"
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.insertLines(len(d.lines), "", tableLine, line(len(table)))
+		} else {
+			d.insertLines(len(d.lines), tableLine, line(len(table)))
+		}
+		d.trailingLine = true
+		return
+	}
+
+	d.insertLines(lastLine+1, line(header))
+}
+
+func (d *Document) insertLines(at int, lines ...string) {
+	rest := append(append([]string(nil), lines...), d.lines[at:]...)
+	d.lines = append(d.lines[:at], rest...)
+}
+
+// Keys returns all dotted keys in the document, sorted.
+func (d *Document) Keys() []string {
+	entries, _, err := d.scan()
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, formatPath(e.key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Encode converts a Go value into TOML value syntax.
+func Encode(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quoteString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return encodeFloat(float64(v)), nil
+	case float64:
+		return encodeFloat(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return quoteString(v.String()), nil
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return Encode(items)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			p, err := Encode(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, p)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// quoteString returns s as a TOML basic string.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func encodeFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// SplitKey splits a dotted key into its parts, honouring quoted parts:
+// `a."b.c"` is a and b.c.
+func SplitKey(key string) []string {
+	var parts []string
+	var cur bytes.Buffer
+	var quote byte
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if s := strings.TrimSpace(cur.String()); s != "" || len(parts) > 0 {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+func formatKey(k string) string {
+	for _, c := range k {
+		if !(c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return quoteString(k)
+		}
+	}
+	if k == "" {
+		return `""`
+	}
+	return k
+}
+
+func formatPath(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = formatKey(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+func equalPath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
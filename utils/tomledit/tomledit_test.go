@@ -0,0 +1,247 @@
+package tomledit
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "keeps comments and spacing",
+			in:    "# top\n[p2p]  # peers\nseeds = \"a\"   # keep me\n\nmax = 10\n",
+			key:   "p2p.seeds",
+			value: "b,c",
+			want:  "# top\n[p2p]  # peers\nseeds = \"b,c\"   # keep me\n\nmax = 10\n",
+		},
+		{
+			name:  "keeps CRLF line endings",
+			in:    "[a]\r\nx = 1\r\ny = 2\r\n",
+			key:   "a.z",
+			value: 3,
+			want:  "[a]\r\nx = 1\r\ny = 2\r\nz = 3\r\n",
+		},
+		{
+			name:  "replaces a dotted key",
+			in:    "[t]\nx.y = 2\n",
+			key:   "t.x.y",
+			value: 5,
+			want:  "[t]\nx.y = 5\n",
+		},
+		{
+			name:  "adds to a table reached through a dotted key",
+			in:    "[t]\nx.y = 2\n",
+			key:   "t.x.z",
+			value: 3,
+			want:  "[t]\nx.y = 2\nx.z = 3\n",
+		},
+		{
+			name:  "replaces a quoted key",
+			in:    "[\"a.b\"]\n'c d' = 1\n",
+			key:   `"a.b"."c d"`,
+			value: 2,
+			want:  "[\"a.b\"]\n'c d' = 2\n",
+		},
+		{
+			name:  "quotes a new key that needs it",
+			in:    "[a]\nx = 1\n",
+			key:   `a."b c"`,
+			value: true,
+			want:  "[a]\nx = 1\n\"b c\" = true\n",
+		},
+		{
+			name:  "creates a missing table",
+			in:    "[a]\nx = 1\n",
+			key:   "b.c.d",
+			value: "v",
+			want:  "[a]\nx = 1\n\n[b.c]\nd = \"v\"\n",
+		},
+		{
+			name:  "adds a root key before the first table",
+			in:    "[a]\nx = 1\n",
+			key:   "top",
+			value: 1,
+			want:  "top = 1\n\n[a]\nx = 1\n",
+		},
+		{
+			name:  "replaces a multi-line array",
+			in:    "[a]\nlist = [\n  \"x\", # one\n  \"y\",\n]\nafter = 1\n",
+			key:   "a.list",
+			value: []string{"z"},
+			want:  "[a]\nlist = [\"z\"]\nafter = 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.Set(tt.key, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := "# c\r\n[a] # t\r\n\"q.k\" = 'v' # x\r\nb.c = [1,\r\n  2]\r\n\r\n[[arr]]\r\nn = 1\r\n"
+	doc, err := Parse([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(doc.Bytes()); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+	if got, ok := doc.Get(`a."q.k"`); !ok || got != "'v'" {
+		t.Errorf(`Get(a."q.k") = %q, %v`, got, ok)
+	}
+	if got, ok := doc.Get("a.b.c"); !ok || got != "[1,\r\n  2]" {
+		t.Errorf("Get(a.b.c) = %q, %v", got, ok)
+	}
+	if doc.Has("arr.n") {
+		t.Error("keys inside arrays of tables must not be addressable")
+	}
+}
+
+func TestSetRawRollsBackInvalidTOML(t *testing.T) {
+	in := "[a]\nx = 1\n"
+	doc, err := Parse([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.SetRaw("a.x", "[unterminated"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := doc.SetRaw("a.y", "not toml"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := string(doc.Bytes()); got != in {
+		t.Errorf("document changed after failed edits: %q", got)
+	}
+}
+
+func TestParseRejectsInvalidTOML(t *testing.T) {
+	if _, err := Parse([]byte("[a\nx = 1\n")); err == nil || !strings.Contains(err.Error(), "invalid TOML") {
+		t.Errorf("got %v, want an invalid TOML error", err)
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := map[string][]string{
+		"a.b":         {"a", "b"},
+		`a."b.c".d`:   {"a", "b.c", "d"},
+		`'x y'.z`:     {"x y", "z"},
+		" a . b ":     {"a", "b"},
+		`"".a`:        {"", "a"},
+		"single":      {"single"},
+		`p2p."seeds"`: {"p2p", "seeds"},
+	}
+	for key, want := range tests {
+		if got := SplitKey(key); !equalPath(got, want) {
+			t.Errorf("SplitKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// Config templates as written by `story init`
+var templates = []struct {
+	file string
+	// keep are keys whose values must survive an edit
+	keep map[string]string
+}{
+	{
+		file: "testdata/config.toml",
+		keep: map[string]string{
+			"proxy_app":                            "\"tcp://127.0.0.1:26658\"",
+			"rpc.laddr":                            "\"tcp://127.0.0.1:26657\"",
+			"rpc.max_open_connections":             "900",
+			"rpc.cors_allowed_methods":             "[\"HEAD\", \"GET\", \"POST\", ]",
+			"p2p.seeds":                            "\"434af9dae402ab9f1c8a8fc15eae2d68b5be3387@story-testnet-seed.itrocket.net:29900\"",
+			"instrumentation.max_open_connections": "3",
+		},
+	},
+	{
+		file: "testdata/story.toml",
+		keep: map[string]string{
+			"network":         "\"odyssey\"",
+			"engine-endpoint": "\"http://localhost:8551\"",
+			"engine-jwt-file": "\"/root/.story/geth/odyssey/geth/jwtsecret\"",
+			"api.address":     "\"127.0.0.1:1317\"",
+			"log.level":       "\"info\"",
+		},
+	},
+}
+
+// withoutKey drops the lines setting key at the top of a table from data
+func withoutKey(data, key string) []string {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if !strings.HasPrefix(line, key+" = ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestTemplatesRoundTrip(t *testing.T) {
+	for _, tt := range templates {
+		t.Run(tt.file, func(t *testing.T) {
+			in, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Parse(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Bytes(); !bytes.Equal(got, in) {
+				t.Errorf("the document changed without edits:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestTemplatesSetMoniker(t *testing.T) {
+	for _, tt := range templates {
+		t.Run(tt.file, func(t *testing.T) {
+			doc, err := Load(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := string(doc.Bytes())
+			if err := doc.Set("moniker", "validator-2"); err != nil {
+				t.Fatal(err)
+			}
+			got := string(doc.Bytes())
+
+			if raw, ok := doc.Get("moniker"); !ok || raw != `"validator-2"` {
+				t.Errorf("moniker is %q, %v", raw, ok)
+			}
+			for key, want := range tt.keep {
+				if raw, ok := doc.Get(key); !ok || raw != want {
+					t.Errorf("%s is %q, %v after the edit, want %q", key, raw, ok, want)
+				}
+			}
+			// Every other line, comments included, is kept in place
+			before, after := withoutKey(in, "moniker"), withoutKey(got, "moniker")
+			if strings.Join(before, "\n") != strings.Join(after, "\n") {
+				t.Errorf("lines besides the moniker changed:\n%s", got)
+			}
+			if _, err := Parse([]byte(got)); err != nil {
+				t.Errorf("the edited document is invalid: %v", err)
+			}
+		})
+	}
+}